@@ -0,0 +1,19 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"mygolangproject/cluster/clustertest"
+	"mygolangproject/store"
+	"mygolangproject/store/storetest"
+)
+
+func TestFailover(t *testing.T) {
+	clustertest.RunFailover(t)
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.StudentStore {
+		return clustertest.Start(t, 1).Live()[0].Node
+	})
+}
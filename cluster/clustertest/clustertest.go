@@ -0,0 +1,266 @@
+// Package clustertest runs replicated clusters in-process on loopback
+// addresses and holds the failover scenario every change to the cluster
+// package must pass. It is wired up from a test file:
+//
+//	func TestFailover(t *testing.T) {
+//		clustertest.RunFailover(t)
+//	}
+package clustertest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc"
+	"mygolangproject/cluster"
+	"mygolangproject/store"
+)
+
+const waitTimeout = 10 * time.Second
+
+// Node is one member of a test cluster together with its gRPC server.
+type Node struct {
+	*cluster.Node
+	ID          string
+	GRPCAddress string
+	server      *grpc.Server
+}
+
+type Cluster struct {
+	t     *testing.T
+	mux   sync.Mutex
+	nodes []*Node // nil once killed
+	next  int
+}
+
+func raftConfig() *raft.Config {
+	rc := raft.DefaultConfig()
+	rc.HeartbeatTimeout = 150 * time.Millisecond
+	rc.ElectionTimeout = 150 * time.Millisecond
+	rc.LeaderLeaseTimeout = 100 * time.Millisecond
+	rc.CommitTimeout = 5 * time.Millisecond
+	return rc
+}
+
+// Start bootstraps a cluster of n nodes and waits until all of them have
+// joined. The nodes are shut down when the test ends.
+func Start(t *testing.T, n int) *Cluster {
+	c := &Cluster{t: t}
+	t.Cleanup(c.Close)
+	c.startNode(true)
+	c.WaitLeader()
+	for i := 1; i < n; i++ {
+		c.Add()
+	}
+	return c
+}
+
+func (c *Cluster) startNode(bootstrap bool) *Node {
+	c.t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		c.t.Fatalf("listen: %v", err)
+	}
+	c.mux.Lock()
+	id := fmt.Sprintf("node%d", c.next)
+	c.next++
+	c.mux.Unlock()
+	n, err := cluster.Open(cluster.Config{
+		ID:          id,
+		RaftAddress: "127.0.0.1:0",
+		GRPCAddress: lis.Addr().String(),
+		Bootstrap:   bootstrap,
+		Raft:        raftConfig(),
+		LogOutput:   ioutil.Discard,
+	})
+	if err != nil {
+		lis.Close()
+		c.t.Fatalf("open %v: %v", id, err)
+	}
+	s := grpc.NewServer()
+	cluster.Register(s, n)
+	go s.Serve(lis)
+	node := &Node{Node: n, ID: id, GRPCAddress: lis.Addr().String(), server: s}
+	c.mux.Lock()
+	c.nodes = append(c.nodes, node)
+	c.mux.Unlock()
+	return node
+}
+
+// Add starts a new node and joins it through the first live one.
+func (c *Cluster) Add() *Node {
+	c.t.Helper()
+	via := c.Live()[0]
+	node := c.startNode(false)
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+	if err := node.JoinVia(ctx, via.GRPCAddress); err != nil {
+		c.t.Fatalf("join %v: %v", node.ID, err)
+	}
+	return node
+}
+
+// Live returns the nodes that have not been killed.
+func (c *Cluster) Live() []*Node {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	var live []*Node
+	for _, n := range c.nodes {
+		if n != nil {
+			live = append(live, n)
+		}
+	}
+	return live
+}
+
+// WaitLeader waits until one of the live nodes leads the cluster.
+func (c *Cluster) WaitLeader() *Node {
+	c.t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		for _, n := range c.Live() {
+			if n.IsLeader() {
+				return n
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	c.t.Fatal("no leader elected")
+	return nil
+}
+
+// Kill shuts a node down without removing it from the cluster, as a crash
+// would.
+func (c *Cluster) Kill(node *Node) {
+	c.mux.Lock()
+	for i, n := range c.nodes {
+		if n == node {
+			c.nodes[i] = nil
+		}
+	}
+	c.mux.Unlock()
+	node.server.Stop()
+	node.Shutdown()
+}
+
+func (c *Cluster) Close() {
+	for _, n := range c.Live() {
+		c.Kill(n)
+	}
+}
+
+// RunFailover registers and updates students through every node of a three
+// node cluster, kills the leader halfway, replaces it with a new member and
+// checks that every acknowledged write can be read back, in the same state,
+// from each node.
+func RunFailover(t *testing.T) {
+	c := Start(t, 3)
+	const writers, perWriter = 6, 40
+
+	var mux sync.Mutex
+	acked := make(map[string]store.Student)
+	failed := 0
+	killed := make(chan struct{})
+	var killOnce sync.Once
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if w == 0 && i == perWriter/2 {
+					killOnce.Do(func() {
+						leader := c.WaitLeader()
+						t.Logf("killing leader %v", leader.ID)
+						c.Kill(leader)
+						close(killed)
+					})
+				}
+				live := c.Live()
+				n := live[(w+i)%len(live)]
+				stu := store.Student{Id: fmt.Sprintf("w%d-%d", w, i), Name: "tom", Age: 18, Profession: "软件工程", CreateTime: int64(i)}
+				err := n.Create(stu)
+				if err == nil {
+					stu.Version = 1
+					stu.Age = 19
+					if err = n.Update(stu); err == nil {
+						stu.Version = 2
+					}
+				}
+				mux.Lock()
+				if err != nil {
+					failed++
+					t.Logf("write %v through %v: %v", stu.Id, n.ID, err)
+				} else {
+					acked[stu.Id] = stu
+				}
+				mux.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	<-killed
+	if failed > writers*perWriter/10 {
+		t.Fatalf("%d of %d writes failed", failed, writers*perWriter)
+	}
+
+	c.WaitLeader()
+	for _, n := range c.Live() {
+		members, err := n.Members()
+		if err != nil {
+			t.Fatalf("members on %v: %v", n.ID, err)
+		}
+		for _, m := range members.Members {
+			if c.isKilled(m.Id) {
+				if err := n.Leave(context.Background(), m.Id); err != nil {
+					t.Fatalf("remove %v: %v", m.Id, err)
+				}
+			}
+		}
+		break
+	}
+	c.Add()
+
+	var first []store.Student
+	for i, n := range c.Live() {
+		res, err := n.List(store.ListQuery{Filter: store.Filter{Deleted: store.IncludeDeleted}, Ascending: true})
+		if err != nil {
+			t.Fatalf("list on %v: %v", n.ID, err)
+		}
+		got := make(map[string]store.Student)
+		for _, s := range res.Students {
+			got[s.Id] = s
+		}
+		for id, want := range acked {
+			if got[id] != want {
+				t.Errorf("%v has %+v, want acknowledged %+v", n.ID, got[id], want)
+			}
+		}
+		sort.Slice(res.Students, func(i, j int) bool { return res.Students[i].Id < res.Students[j].Id })
+		if i == 0 {
+			first = res.Students
+		} else if fmt.Sprint(res.Students) != fmt.Sprint(first) {
+			t.Errorf("%v disagrees with %v", n.ID, c.Live()[0].ID)
+		}
+	}
+	members, err := c.Live()[0].Members()
+	if err != nil || len(members.Members) != 3 {
+		t.Fatalf("members after replacing the leader = %v (%v), want 3", members, err)
+	}
+}
+
+func (c *Cluster) isKilled(id string) bool {
+	for _, n := range c.Live() {
+		if n.ID == id {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,344 @@
+package cluster
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/hashicorp/raft"
+	"mygolangproject/store"
+)
+
+const (
+	opCreate    = "create"
+	opCreateAll = "createAll"
+	opUpdate    = "update"
+	opDelete    = "delete"
+	opHistory   = "history"
+	opJoin      = "join"
+	opLeave     = "leave"
+
+	opCreateProfession = "createProfession"
+	opUpdateProfession = "updateProfession"
+	opDeleteProfession = "deleteProfession"
+
+	opPutIdempotent    = "putIdempotent"
+	opExpireIdempotent = "expireIdempotent"
+
+	opPutAlias    = "putAlias"
+	opDeleteAlias = "deleteAlias"
+
+	opAdvanceSequence = "advanceSequence"
+
+	opCreateCourse = "createCourse"
+	opUpdateCourse = "updateCourse"
+	opDeleteCourse = "deleteCourse"
+	opEnroll       = "enroll"
+	opDrop         = "drop"
+)
+
+// command is one entry of the replicated log.
+type command struct {
+	Op      string              `json:"op"`
+	Student store.Student       `json:"student,omitempty"`
+	Version int64               `json:"version,omitempty"`
+	Entry   *store.HistoryEntry `json:"entry,omitempty"`
+	Member  *member             `json:"member,omitempty"`
+
+	// Students are the students of a createAll.
+	Students []store.Student `json:"students,omitempty"`
+
+	Profession *store.Profession `json:"profession,omitempty"`
+
+	Idempotent *store.IdempotentResult `json:"idempotent,omitempty"`
+	Before     int64                   `json:"before,omitempty"`
+
+	Alias *store.Alias `json:"alias,omitempty"`
+
+	// Sequence is advanced from Version to Version+1.
+	Sequence string `json:"sequence,omitempty"`
+
+	Course     *store.Course     `json:"course,omitempty"`
+	Enrollment *store.Enrollment `json:"enrollment,omitempty"`
+}
+
+type member struct {
+	Id          string `json:"id"`
+	RaftAddress string `json:"raftAddress"`
+	GRPCAddress string `json:"grpcAddress"`
+}
+
+// fsm is the replicated state machine: the students, their history, the
+// profession catalogue, the results kept for idempotency keys, the aliases of
+// merged students, the counters of student numbers, the courses and who
+// takes them and the gRPC addresses of the members, all applied in log order
+// on every node. Every node starts out with the default professions.
+type fsm struct {
+	mux         sync.RWMutex // guards the fields below, which Restore replaces
+	students    *store.MemoryStore
+	history     *store.MemoryHistory
+	professions *store.MemoryProfessions
+	idempotency *store.MemoryIdempotency
+	aliases     *store.MemoryAliases
+	sequences   *store.MemorySequences
+	courses     *store.MemoryCourses
+	enrollments *store.MemoryEnrollments
+	members     map[string]member
+
+	appliedMux sync.Mutex
+	appliedCh  chan struct{} // closed and replaced whenever applied grows
+	applied    uint64
+}
+
+func newFSM() *fsm {
+	return &fsm{
+		students:    store.NewMemoryStore(),
+		history:     store.NewMemoryHistory(),
+		professions: store.NewMemoryProfessions(store.DefaultProfessions),
+		idempotency: store.NewMemoryIdempotency(),
+		aliases:     store.NewMemoryAliases(),
+		sequences:   store.NewMemorySequences(nil),
+		courses:     store.NewMemoryCourses(nil),
+		enrollments: store.NewMemoryEnrollments(),
+		members:     make(map[string]member),
+		appliedCh:   make(chan struct{}),
+	}
+}
+
+// Apply returns the error of the command, if any, as the response of the
+// log entry.
+func (f *fsm) Apply(l *raft.Log) interface{} {
+	defer f.setApplied(l.Index)
+	var cmd command
+	if err := json.Unmarshal(l.Data, &cmd); err != nil {
+		return err
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	switch cmd.Op {
+	case opCreate:
+		return f.students.Create(cmd.Student)
+	case opCreateAll:
+		return f.students.CreateAll(cmd.Students)
+	case opUpdate:
+		return f.students.Update(cmd.Student)
+	case opDelete:
+		return f.students.Delete(cmd.Student.Id, cmd.Version)
+	case opHistory:
+		// A retried append after an ambiguous failure must not show up twice.
+		if entries, _ := f.history.List(cmd.Entry.StudentId); len(entries) > 0 && reflect.DeepEqual(entries[len(entries)-1], *cmd.Entry) {
+			return nil
+		}
+		return f.history.Append(*cmd.Entry)
+	case opCreateProfession:
+		return f.professions.Create(*cmd.Profession)
+	case opUpdateProfession:
+		return f.professions.Update(*cmd.Profession)
+	case opDeleteProfession:
+		return f.professions.Delete(cmd.Profession.Code)
+	case opPutIdempotent:
+		return f.idempotency.Put(*cmd.Idempotent)
+	case opExpireIdempotent:
+		_, err := f.idempotency.Expire(cmd.Before)
+		return err
+	case opPutAlias:
+		return f.aliases.Put(*cmd.Alias)
+	case opDeleteAlias:
+		return f.aliases.Delete(cmd.Alias.RetiredId)
+	case opAdvanceSequence:
+		return f.sequences.Advance(cmd.Sequence, cmd.Version)
+	case opCreateCourse:
+		return f.courses.Create(*cmd.Course)
+	case opUpdateCourse:
+		return f.courses.Update(*cmd.Course)
+	case opDeleteCourse:
+		return f.courses.Delete(cmd.Course.Code)
+	case opEnroll:
+		return f.enrollments.Enroll(*cmd.Enrollment)
+	case opDrop:
+		return f.enrollments.Drop(cmd.Enrollment.CourseCode, cmd.Enrollment.StudentId)
+	case opJoin:
+		f.members[cmd.Member.Id] = *cmd.Member
+	case opLeave:
+		delete(f.members, cmd.Member.Id)
+	}
+	return nil
+}
+
+func (f *fsm) setApplied(index uint64) {
+	f.appliedMux.Lock()
+	defer f.appliedMux.Unlock()
+	if index > f.applied {
+		f.applied = index
+		close(f.appliedCh)
+		f.appliedCh = make(chan struct{})
+	}
+}
+
+// appliedIndex returns the last applied index and a channel that is closed
+// once it grows.
+func (f *fsm) appliedIndex() (uint64, <-chan struct{}) {
+	f.appliedMux.Lock()
+	defer f.appliedMux.Unlock()
+	return f.applied, f.appliedCh
+}
+
+func (f *fsm) state() (*store.MemoryStore, *store.MemoryHistory) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.students, f.history
+}
+
+func (f *fsm) catalogue() *store.MemoryProfessions {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.professions
+}
+
+func (f *fsm) idempotentResults() *store.MemoryIdempotency {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.idempotency
+}
+
+func (f *fsm) studentAliases() *store.MemoryAliases {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.aliases
+}
+
+func (f *fsm) counters() *store.MemorySequences {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.sequences
+}
+
+func (f *fsm) courseList() (*store.MemoryCourses, *store.MemoryEnrollments) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.courses, f.enrollments
+}
+
+func (f *fsm) memberList() []member {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	list := make([]member, 0, len(f.members))
+	for _, m := range f.members {
+		list = append(list, m)
+	}
+	return list
+}
+
+func (f *fsm) memberByRaftAddress(addr raft.ServerAddress) (member, bool) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	for _, m := range f.members {
+		if raft.ServerAddress(m.RaftAddress) == addr {
+			return m, true
+		}
+	}
+	return member{}, false
+}
+
+// fsmSnapshot is the serialized form of the whole state machine. Snapshots
+// taken before the catalogue existed have no professions at all, as opposed
+// to an empty list.
+type fsmSnapshot struct {
+	Applied     uint64                   `json:"applied"`
+	Students    []store.Student          `json:"students"`
+	History     []store.HistoryEntry     `json:"history"`
+	Professions *[]store.Profession      `json:"professions"`
+	Idempotency []store.IdempotentResult `json:"idempotency,omitempty"`
+	Aliases     []store.Alias            `json:"aliases,omitempty"`
+	Sequences   map[string]int64         `json:"sequences,omitempty"`
+	Courses     []store.Course           `json:"courses,omitempty"`
+	Enrollments []store.Enrollment       `json:"enrollments,omitempty"`
+	Members     []member                 `json:"members"`
+}
+
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	all, err := f.students.List(store.ListQuery{Filter: store.Filter{Deleted: store.IncludeDeleted}})
+	if err != nil {
+		return nil, err
+	}
+	professions, err := f.professions.List()
+	if err != nil {
+		return nil, err
+	}
+	courses, err := f.courses.List()
+	if err != nil {
+		return nil, err
+	}
+	applied, _ := f.appliedIndex()
+	snap := &fsmSnapshot{
+		Applied:     applied,
+		Students:    all.Students,
+		History:     f.history.All(),
+		Professions: &professions,
+		Idempotency: f.idempotency.All(),
+		Aliases:     f.aliases.All(),
+		Sequences:   f.sequences.All(),
+		Courses:     courses,
+		Enrollments: f.enrollments.All(),
+	}
+	for _, m := range f.members {
+		snap.Members = append(snap.Members, m)
+	}
+	return snap, nil
+}
+
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	var snap fsmSnapshot
+	if err := json.NewDecoder(rc).Decode(&snap); err != nil {
+		return err
+	}
+	students, history := store.NewMemoryStore(), store.NewMemoryHistory()
+	for _, s := range snap.Students {
+		students.Put(s)
+	}
+	for _, e := range snap.History {
+		history.Append(e)
+	}
+	initial := store.DefaultProfessions
+	if snap.Professions != nil {
+		initial = *snap.Professions
+	}
+	professions := store.NewMemoryProfessions(initial)
+	idempotency := store.NewMemoryIdempotency()
+	for _, r := range snap.Idempotency {
+		idempotency.Put(r)
+	}
+	aliases := store.NewMemoryAliases()
+	for _, a := range snap.Aliases {
+		aliases.Put(a)
+	}
+	sequences := store.NewMemorySequences(snap.Sequences)
+	courses, enrollments := store.NewMemoryCourses(snap.Courses), store.NewMemoryEnrollments()
+	for _, e := range snap.Enrollments {
+		enrollments.Enroll(e)
+	}
+	members := make(map[string]member)
+	for _, m := range snap.Members {
+		members[m.Id] = m
+	}
+	f.mux.Lock()
+	f.students, f.history, f.professions, f.idempotency, f.aliases, f.sequences = students, history, professions, idempotency, aliases, sequences
+	f.courses, f.enrollments, f.members = courses, enrollments, members
+	f.mux.Unlock()
+	f.setApplied(snap.Applied)
+	return nil
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
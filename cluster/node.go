@@ -0,0 +1,424 @@
+// Package cluster replicates the student store across several nodes with
+// Raft. Every node keeps the full data set in memory and serves it through
+// Node, a store.StudentStore: writes are forwarded to the leader and
+// committed through the replicated log, reads first confirm with the leader
+// that the node has caught up, which makes them linearizable on any node.
+//
+// Nodes talk to each other over the Cluster gRPC service, which has to be
+// registered on the node's gRPC server with Register. Watch events are only
+// published by the node a write was made through.
+//
+// Only the data is replicated. The student service checks some rules under
+// locks held in the process serving a request: the capacities of professions
+// and courses, duplicate detection and requests sharing an idempotency key.
+// Those are only serialized among the requests one node serves, so two nodes
+// can each hand out the last place of a profession or register the same
+// student twice unflagged. Clients that rely on them should send their writes
+// to a single node. The background jobs of the service, purging deleted
+// students and expiring idempotency keys, run on the leader only.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+)
+
+const (
+	// applyTimeout bounds one attempt to commit a command, retryTimeout the
+	// time spent waiting for a leader across attempts.
+	applyTimeout  = 5 * time.Second
+	retryTimeout  = 10 * time.Second
+	retryInterval = 50 * time.Millisecond
+	maxPool       = 3
+	keepSnapshots = 2
+)
+
+var errNoLeader = status.Error(codes.Unavailable, "cluster has no leader")
+
+type Config struct {
+	ID          string // 节点唯一标识
+	RaftAddress string // Raft 节点间通信地址 host:port
+	GRPCAddress string // 本节点对外提供 gRPC 服务的地址，供其他节点转发请求
+	// Dir keeps the Raft log and snapshots; empty keeps them in memory, which
+	// only suits tests.
+	Dir string
+	// Bootstrap starts a new cluster with this node as its only member.
+	// Other nodes are added with Join.
+	Bootstrap bool
+	// Raft overrides the default Raft settings, e.g. to shorten timeouts.
+	Raft *raft.Config
+	// LogOutput receives the Raft library log; nil means stderr.
+	LogOutput io.Writer
+}
+
+type Node struct {
+	conf  Config
+	raft  *raft.Raft
+	fsm   *fsm
+	trans *raft.NetworkTransport
+	logs  io.Closer
+
+	mux         sync.Mutex
+	conns       map[string]*grpc.ClientConn
+	readyTerm   uint64 // term in which this node, as leader, passed a barrier
+	stop        chan struct{}
+	stopped     sync.WaitGroup
+	shutdownErr error
+
+	sequenceMux sync.Mutex // serializes the advances of counters from this node
+}
+
+// Open starts a node. A node that does not bootstrap waits until a member
+// of an existing cluster adds it through Join.
+func Open(conf Config) (*Node, error) {
+	rc := raft.DefaultConfig()
+	if conf.Raft != nil {
+		copied := *conf.Raft
+		rc = &copied
+	}
+	rc.LocalID = raft.ServerID(conf.ID)
+	if conf.LogOutput != nil {
+		rc.LogOutput = conf.LogOutput
+	}
+	logOutput := rc.LogOutput
+	if logOutput == nil {
+		logOutput = os.Stderr
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", conf.RaftAddress)
+	if err != nil {
+		return nil, err
+	}
+	// With port 0 the transport picks a free port and advertises that one.
+	var advertise net.Addr
+	if addr.Port != 0 {
+		advertise = addr
+	}
+	trans, err := raft.NewTCPTransport(conf.RaftAddress, advertise, maxPool, applyTimeout, logOutput)
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{conf: conf, fsm: newFSM(), trans: trans, conns: make(map[string]*grpc.ClientConn), stop: make(chan struct{})}
+
+	var logs raft.LogStore
+	var stable raft.StableStore
+	var snaps raft.SnapshotStore
+	if conf.Dir == "" {
+		mem := raft.NewInmemStore()
+		logs, stable, snaps = mem, mem, raft.NewInmemSnapshotStore()
+	} else {
+		if err := os.MkdirAll(conf.Dir, 0755); err != nil {
+			trans.Close()
+			return nil, err
+		}
+		bolt, err := raftboltdb.NewBoltStore(filepath.Join(conf.Dir, "raft.db"))
+		if err != nil {
+			trans.Close()
+			return nil, err
+		}
+		if snaps, err = raft.NewFileSnapshotStore(conf.Dir, keepSnapshots, logOutput); err != nil {
+			bolt.Close()
+			trans.Close()
+			return nil, err
+		}
+		logs, stable, n.logs = bolt, bolt, bolt
+	}
+
+	if conf.Bootstrap {
+		existing, err := raft.HasExistingState(logs, stable, snaps)
+		if err == nil && !existing {
+			err = raft.BootstrapCluster(rc, logs, stable, snaps, trans, raft.Configuration{
+				Servers: []raft.Server{{ID: rc.LocalID, Address: trans.LocalAddr()}},
+			})
+		}
+		if err != nil {
+			n.closeStores()
+			return nil, err
+		}
+	}
+	if n.raft, err = raft.NewRaft(rc, n.fsm, logs, stable, snaps, trans); err != nil {
+		n.closeStores()
+		return nil, err
+	}
+	n.stopped.Add(1)
+	go n.watchLeadership()
+	return n, nil
+}
+
+func (n *Node) closeStores() {
+	n.trans.Close()
+	if n.logs != nil {
+		n.logs.Close()
+	}
+}
+
+// watchLeadership records the gRPC address of a node that becomes leader in
+// the member list, so that the other nodes can forward to it.
+func (n *Node) watchLeadership() {
+	defer n.stopped.Done()
+	for {
+		select {
+		case leader := <-n.raft.LeaderCh():
+			if !leader {
+				continue
+			}
+			self := member{Id: n.conf.ID, RaftAddress: string(n.trans.LocalAddr()), GRPCAddress: n.conf.GRPCAddress}
+			if m, ok := n.fsm.memberByRaftAddress(n.trans.LocalAddr()); !ok || m != self {
+				if err := n.apply(context.Background(), command{Op: opJoin, Member: &self}); err != nil {
+					n.logf("recording own address failed: %v", err)
+				}
+			}
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+func (n *Node) logf(format string, args ...interface{}) {
+	out := n.conf.LogOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "[cluster %v] "+format+"\n", append([]interface{}{n.conf.ID}, args...)...)
+}
+
+// Shutdown stops the node. Its peers see it as failed until it is removed
+// with Leave.
+func (n *Node) Shutdown() error {
+	n.mux.Lock()
+	select {
+	case <-n.stop:
+		n.mux.Unlock()
+		return n.shutdownErr
+	default:
+	}
+	close(n.stop)
+	n.mux.Unlock()
+	err := n.raft.Shutdown().Error()
+	n.stopped.Wait()
+	n.trans.Close()
+	if n.logs != nil {
+		n.logs.Close()
+	}
+	n.mux.Lock()
+	for _, c := range n.conns {
+		c.Close()
+	}
+	n.shutdownErr = err
+	n.mux.Unlock()
+	return err
+}
+
+// IsLeader reports whether this node currently leads the cluster.
+func (n *Node) IsLeader() bool {
+	return n.raft.State() == raft.Leader
+}
+
+// leaderClient returns a client for the current leader's Cluster service.
+func (n *Node) leaderClient() (pb.ClusterClient, error) {
+	addr := n.raft.Leader()
+	if addr == "" {
+		return nil, errNoLeader
+	}
+	m, ok := n.fsm.memberByRaftAddress(addr)
+	if !ok || m.GRPCAddress == "" {
+		return nil, errNoLeader
+	}
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	conn, ok := n.conns[m.GRPCAddress]
+	if !ok {
+		var err error
+		if conn, err = grpc.Dial(m.GRPCAddress, grpc.WithInsecure()); err != nil {
+			return nil, err
+		}
+		n.conns[m.GRPCAddress] = conn
+	}
+	return pb.NewClusterClient(conn), nil
+}
+
+// retryable reports whether err may go away once a leader is elected or
+// known. Raft reports a lost leadership when the outcome of a command is
+// unknown; callers resolve that case themselves.
+func retryable(err error) bool {
+	switch err {
+	case raft.ErrNotLeader, raft.ErrLeadershipLost, raft.ErrLeadershipTransferInProgress, raft.ErrEnqueueTimeout:
+		return true
+	}
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// retry runs attempt until it succeeds, fails for good or retryTimeout has
+// passed, reporting whether an earlier attempt ended with an unknown
+// outcome.
+func (n *Node) retry(ctx context.Context, attempt func(ctx context.Context) error) (ambiguous bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, retryTimeout)
+	defer cancel()
+	for {
+		err = attempt(ctx)
+		if err == nil || !retryable(err) {
+			return ambiguous, err
+		}
+		if err != raft.ErrNotLeader && err != errNoLeader {
+			ambiguous = true
+		}
+		select {
+		case <-ctx.Done():
+			return ambiguous, status.Errorf(codes.Unavailable, "cluster unavailable: %v", err)
+		case <-n.stop:
+			return ambiguous, status.Error(codes.Unavailable, "node is shut down")
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// apply commits cmd through the leader and waits until this node has
+// applied it, so that the caller reads its own write.
+func (n *Node) apply(ctx context.Context, cmd command) error {
+	b, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	var cmdErr error
+	ambiguous, err := n.retry(ctx, func(ctx context.Context) error {
+		res, err := n.applyOnce(ctx, b)
+		if err != nil {
+			return err
+		}
+		cmdErr = res.err
+		return n.waitApplied(ctx, res.index)
+	})
+	if err != nil {
+		return err
+	}
+	if cmdErr != nil && ambiguous && n.tookEffect(cmd) {
+		return nil
+	}
+	return cmdErr
+}
+
+type applied struct {
+	index uint64
+	err   error // the command's own error
+}
+
+func (n *Node) applyOnce(ctx context.Context, b []byte) (applied, error) {
+	if n.IsLeader() {
+		f := n.raft.Apply(b, applyTimeout)
+		if err := f.Error(); err != nil {
+			return applied{}, err
+		}
+		res := applied{index: f.Index()}
+		res.err, _ = f.Response().(error)
+		return res, nil
+	}
+	c, err := n.leaderClient()
+	if err != nil {
+		return applied{}, err
+	}
+	reply, err := c.Apply(ctx, &pb.ApplyRequest{Command: b})
+	if err != nil {
+		return applied{}, err
+	}
+	return applied{index: reply.Index, err: commandError(reply.Error)}, nil
+}
+
+// waitApplied blocks until this node has applied the log up to index.
+func (n *Node) waitApplied(ctx context.Context, index uint64) error {
+	for {
+		current, grown := n.fsm.appliedIndex()
+		if current >= index {
+			return nil
+		}
+		select {
+		case <-grown:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-n.stop:
+			return status.Error(codes.Unavailable, "node is shut down")
+		}
+	}
+}
+
+// readIndex returns an index that covers every write acknowledged before the
+// call, as confirmed by the leader.
+func (n *Node) readIndex(ctx context.Context) (uint64, error) {
+	if !n.IsLeader() {
+		c, err := n.leaderClient()
+		if err != nil {
+			return 0, err
+		}
+		reply, err := c.ReadIndex(ctx, &pb.ReadIndexRequest{})
+		if err != nil {
+			return 0, err
+		}
+		return reply.Index, nil
+	}
+	// A new leader may not have applied entries committed in earlier terms;
+	// a barrier in its own term makes sure it has.
+	term := n.currentTerm()
+	n.mux.Lock()
+	ready := n.readyTerm == term
+	n.mux.Unlock()
+	if !ready {
+		if err := n.raft.Barrier(applyTimeout).Error(); err != nil {
+			return 0, err
+		}
+		n.mux.Lock()
+		n.readyTerm = term
+		n.mux.Unlock()
+	}
+	if err := n.raft.VerifyLeader().Error(); err != nil {
+		return 0, err
+	}
+	index, _ := n.fsm.appliedIndex()
+	return index, nil
+}
+
+func (n *Node) currentTerm() uint64 {
+	var term uint64
+	fmt.Sscan(n.raft.Stats()["term"], &term)
+	return term
+}
+
+// linearize waits until this node reflects every write acknowledged before
+// the call.
+func (n *Node) linearize() error {
+	_, err := n.retry(context.Background(), func(ctx context.Context) error {
+		index, err := n.readIndex(ctx)
+		if err != nil {
+			return err
+		}
+		return n.waitApplied(ctx, index)
+	})
+	return err
+}
+
+// commandError restores the store's sentinel errors from their text.
+func commandError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	for _, err := range commandErrors {
+		if err.Error() == msg {
+			return err
+		}
+	}
+	return errors.New(msg)
+}
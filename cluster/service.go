@@ -0,0 +1,177 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+)
+
+var errNotLeader = status.Error(codes.Unavailable, "node is not the leader")
+
+// Register serves the Cluster service of n on s.
+func Register(s *grpc.Server, n *Node) {
+	pb.RegisterClusterServer(s, &service{n: n})
+}
+
+type service struct {
+	pb.UnimplementedClusterServer
+	n *Node
+}
+
+// raftError turns Raft errors, which all mean the request should be retried
+// elsewhere or later, into UNAVAILABLE.
+func raftError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// Apply commits a command forwarded by a follower. It is not forwarded any
+// further; a follower that reached a stale leader retries.
+func (s *service) Apply(_ context.Context, req *pb.ApplyRequest) (*pb.ApplyReply, error) {
+	if !s.n.IsLeader() {
+		return &pb.ApplyReply{}, errNotLeader
+	}
+	f := s.n.raft.Apply(req.Command, applyTimeout)
+	if err := f.Error(); err != nil {
+		return &pb.ApplyReply{}, raftError(err)
+	}
+	reply := &pb.ApplyReply{Index: f.Index()}
+	if err, ok := f.Response().(error); ok {
+		reply.Error = err.Error()
+	}
+	return reply, nil
+}
+
+func (s *service) ReadIndex(ctx context.Context, _ *pb.ReadIndexRequest) (*pb.ReadIndexReply, error) {
+	if !s.n.IsLeader() {
+		return &pb.ReadIndexReply{}, errNotLeader
+	}
+	index, err := s.n.readIndex(ctx)
+	if err != nil {
+		return &pb.ReadIndexReply{}, raftError(err)
+	}
+	return &pb.ReadIndexReply{Index: index}, nil
+}
+
+func (s *service) Join(ctx context.Context, req *pb.JoinRequest) (*pb.Members, error) {
+	m := req.GetMember()
+	if m.GetId() == "" || m.GetRaftAddress() == "" || m.GetGrpcAddress() == "" {
+		return &pb.Members{}, status.Error(codes.InvalidArgument, "member needs an id, a raft address and a grpc address")
+	}
+	if err := s.n.Join(ctx, m.Id, m.RaftAddress, m.GrpcAddress); err != nil {
+		return &pb.Members{}, err
+	}
+	return s.n.Members()
+}
+
+func (s *service) Leave(ctx context.Context, req *pb.LeaveRequest) (*pb.Members, error) {
+	if req.GetId() == "" {
+		return &pb.Members{}, status.Error(codes.InvalidArgument, "missing member id")
+	}
+	if err := s.n.Leave(ctx, req.Id); err != nil {
+		return &pb.Members{}, err
+	}
+	return s.n.Members()
+}
+
+func (s *service) GetMembers(context.Context, *pb.MembersRequest) (*pb.Members, error) {
+	return s.n.Members()
+}
+
+// Join adds a voting member to the cluster. It may be called on any node.
+func (n *Node) Join(ctx context.Context, id, raftAddress, grpcAddress string) error {
+	// A leader reached through forwarding does the whole change itself.
+	forwarded := false
+	_, err := n.retry(ctx, func(ctx context.Context) error {
+		if !n.IsLeader() {
+			c, err := n.leaderClient()
+			if err != nil {
+				return err
+			}
+			_, err = c.Join(ctx, &pb.JoinRequest{Member: &pb.Member{Id: id, RaftAddress: raftAddress, GrpcAddress: grpcAddress}})
+			forwarded = err == nil
+			return err
+		}
+		return n.raft.AddVoter(raft.ServerID(id), raft.ServerAddress(raftAddress), 0, applyTimeout).Error()
+	})
+	if err != nil || forwarded {
+		return raftError(err)
+	}
+	return n.apply(ctx, command{Op: opJoin, Member: &member{Id: id, RaftAddress: raftAddress, GRPCAddress: grpcAddress}})
+}
+
+// Leave removes a member, which may be this node or one that has failed, from
+// the cluster. It may be called on any node.
+func (n *Node) Leave(ctx context.Context, id string) error {
+	// A leader reached through forwarding does the whole change itself.
+	forwarded := false
+	_, err := n.retry(ctx, func(ctx context.Context) error {
+		if !n.IsLeader() {
+			c, err := n.leaderClient()
+			if err != nil {
+				return err
+			}
+			_, err = c.Leave(ctx, &pb.LeaveRequest{Id: id})
+			forwarded = err == nil
+			return err
+		}
+		return n.raft.RemoveServer(raft.ServerID(id), 0, applyTimeout).Error()
+	})
+	if err != nil || forwarded {
+		return raftError(err)
+	}
+	return n.apply(ctx, command{Op: opLeave, Member: &member{Id: id}})
+}
+
+// JoinVia asks the member at grpcAddress to add this node to its cluster.
+func (n *Node) JoinVia(ctx context.Context, grpcAddress string) error {
+	conn, err := grpc.DialContext(ctx, grpcAddress, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = n.retry(ctx, func(ctx context.Context) error {
+		_, err := pb.NewClusterClient(conn).Join(ctx, &pb.JoinRequest{Member: &pb.Member{
+			Id:          n.conf.ID,
+			RaftAddress: string(n.trans.LocalAddr()),
+			GrpcAddress: n.conf.GRPCAddress,
+		}})
+		return err
+	})
+	return err
+}
+
+// Members lists the voters of the current Raft configuration as this node
+// knows it.
+func (n *Node) Members() (*pb.Members, error) {
+	f := n.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return &pb.Members{}, raftError(err)
+	}
+	grpcAddresses := make(map[string]string)
+	for _, m := range n.fsm.memberList() {
+		grpcAddresses[m.Id] = m.GRPCAddress
+	}
+	leader := n.raft.Leader()
+	members := &pb.Members{}
+	for _, srv := range f.Configuration().Servers {
+		members.Members = append(members.Members, &pb.Member{
+			Id:          string(srv.ID),
+			RaftAddress: string(srv.Address),
+			GrpcAddress: grpcAddresses[string(srv.ID)],
+			Leader:      srv.Address == leader,
+		})
+	}
+	sort.Slice(members.Members, func(i, j int) bool { return members.Members[i].Id < members.Members[j].Id })
+	return members, nil
+}
@@ -0,0 +1,360 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"mygolangproject/store"
+)
+
+// commandErrors are the errors a command can fail with that callers tell
+// apart; they survive being forwarded from the leader.
+var commandErrors = []error{
+	store.ErrNotFound, store.ErrAlreadyExists, store.ErrVersionMismatch,
+	store.ErrProfessionNotFound, store.ErrProfessionAlreadyExists,
+	store.ErrSequenceConflict,
+	store.ErrCourseNotFound, store.ErrCourseAlreadyExists,
+	store.ErrAlreadyEnrolled, store.ErrNotEnrolled,
+}
+
+func (n *Node) Create(s store.Student) error {
+	return n.apply(context.Background(), command{Op: opCreate, Student: s})
+}
+
+func (n *Node) CreateAll(students []store.Student) error {
+	return n.apply(context.Background(), command{Op: opCreateAll, Students: students})
+}
+
+func (n *Node) Get(id string) (store.Student, error) {
+	if err := n.linearize(); err != nil {
+		return store.Student{}, err
+	}
+	students, _ := n.fsm.state()
+	return students.Get(id)
+}
+
+func (n *Node) Update(s store.Student) error {
+	return n.apply(context.Background(), command{Op: opUpdate, Student: s})
+}
+
+func (n *Node) Delete(id string, version int64) error {
+	return n.apply(context.Background(), command{Op: opDelete, Student: store.Student{Id: id}, Version: version})
+}
+
+func (n *Node) List(q store.ListQuery) (store.ListResult, error) {
+	if err := n.linearize(); err != nil {
+		return store.ListResult{}, err
+	}
+	students, _ := n.fsm.state()
+	return students.List(q)
+}
+
+// tookEffect reports whether cmd, which failed after an attempt with an
+// unknown outcome, was in fact applied by that attempt: a retried create
+// finds its own student, or all of them, a retried update finds its own
+// version and a retried delete finds nothing.
+func (n *Node) tookEffect(cmd command) bool {
+	if cmd.Profession != nil {
+		return n.professionTookEffect(cmd)
+	}
+	if cmd.Course != nil || cmd.Enrollment != nil {
+		return n.courseTookEffect(cmd)
+	}
+	students, _ := n.fsm.state()
+	if cmd.Op == opCreateAll {
+		for _, want := range cmd.Students {
+			got, err := students.Get(want.Id)
+			want.Version = 1
+			if err != nil || !reflect.DeepEqual(got, want) {
+				return false
+			}
+		}
+		return true
+	}
+	got, err := students.Get(cmd.Student.Id)
+	want := cmd.Student
+	switch cmd.Op {
+	case opCreate:
+		want.Version = 1
+	case opUpdate:
+		want.Version++
+	case opDelete:
+		return err == store.ErrNotFound
+	default:
+		return false
+	}
+	return err == nil && reflect.DeepEqual(got, want)
+}
+
+// History returns the replicated audit trail. Appends go through the log
+// like student writes.
+func (n *Node) History() store.HistoryStore {
+	return history{n}
+}
+
+type history struct {
+	n *Node
+}
+
+func (h history) Append(e store.HistoryEntry) error {
+	if e.StudentId == "" {
+		return errors.New("history entry without student id")
+	}
+	return h.n.apply(context.Background(), command{Op: opHistory, Entry: &e})
+}
+
+func (h history) List(studentId string) ([]store.HistoryEntry, error) {
+	if err := h.n.linearize(); err != nil {
+		return nil, err
+	}
+	_, entries := h.n.fsm.state()
+	return entries.List(studentId)
+}
+
+func (h history) Snapshot(asOf int64) ([]store.Student, error) {
+	if err := h.n.linearize(); err != nil {
+		return nil, err
+	}
+	_, entries := h.n.fsm.state()
+	return entries.Snapshot(asOf)
+}
+
+// Professions returns the replicated profession catalogue.
+func (n *Node) Professions() store.ProfessionStore {
+	return professions{n}
+}
+
+type professions struct {
+	n *Node
+}
+
+func (p professions) Create(prof store.Profession) error {
+	return p.n.apply(context.Background(), command{Op: opCreateProfession, Profession: &prof})
+}
+
+func (p professions) Get(code string) (store.Profession, error) {
+	if err := p.n.linearize(); err != nil {
+		return store.Profession{}, err
+	}
+	return p.n.fsm.catalogue().Get(code)
+}
+
+func (p professions) List() ([]store.Profession, error) {
+	if err := p.n.linearize(); err != nil {
+		return nil, err
+	}
+	return p.n.fsm.catalogue().List()
+}
+
+func (p professions) Update(prof store.Profession) error {
+	return p.n.apply(context.Background(), command{Op: opUpdateProfession, Profession: &prof})
+}
+
+func (p professions) Delete(code string) error {
+	return p.n.apply(context.Background(), command{Op: opDeleteProfession, Profession: &store.Profession{Code: code}})
+}
+
+// professionTookEffect is tookEffect for the catalogue: a retried create or
+// update finds its own profession and a retried delete finds nothing.
+func (n *Node) professionTookEffect(cmd command) bool {
+	got, err := n.fsm.catalogue().Get(cmd.Profession.Code)
+	if cmd.Op == opDeleteProfession {
+		return err == store.ErrProfessionNotFound
+	}
+	want := *cmd.Profession
+	if want.Names == nil {
+		want.Names = map[string]string{}
+	}
+	return err == nil && reflect.DeepEqual(got, want)
+}
+
+// Idempotency returns the replicated results of idempotent requests, so that
+// a retry sent to another node is answered the same way.
+func (n *Node) Idempotency() store.IdempotencyStore {
+	return idempotency{n}
+}
+
+type idempotency struct {
+	n *Node
+}
+
+func (i idempotency) Put(r store.IdempotentResult) error {
+	return i.n.apply(context.Background(), command{Op: opPutIdempotent, Idempotent: &r})
+}
+
+func (i idempotency) Get(key string) (store.IdempotentResult, error) {
+	if err := i.n.linearize(); err != nil {
+		return store.IdempotentResult{}, err
+	}
+	return i.n.fsm.idempotentResults().Get(key)
+}
+
+// Expire reports the number of results this node had recorded before the
+// given time; the log does not carry counts back.
+func (i idempotency) Expire(before int64) (int, error) {
+	if err := i.n.linearize(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, r := range i.n.fsm.idempotentResults().All() {
+		if r.Time < before {
+			n++
+		}
+	}
+	return n, i.n.apply(context.Background(), command{Op: opExpireIdempotent, Before: before})
+}
+
+// Aliases returns the replicated aliases of merged students.
+func (n *Node) Aliases() store.AliasStore {
+	return aliases{n}
+}
+
+type aliases struct {
+	n *Node
+}
+
+func (a aliases) Put(alias store.Alias) error {
+	return a.n.apply(context.Background(), command{Op: opPutAlias, Alias: &alias})
+}
+
+func (a aliases) Delete(retiredId string) error {
+	return a.n.apply(context.Background(), command{Op: opDeleteAlias, Alias: &store.Alias{RetiredId: retiredId}})
+}
+
+func (a aliases) Get(retiredId string) (store.Alias, error) {
+	if err := a.n.linearize(); err != nil {
+		return store.Alias{}, err
+	}
+	return a.n.fsm.studentAliases().Get(retiredId)
+}
+
+// maxSequenceAttempts bounds how often Next retries when other members
+// advance the same counter at the same time.
+const maxSequenceAttempts = 10
+
+// Sequences returns the replicated counters of student numbers.
+func (n *Node) Sequences() store.SequenceStore {
+	return sequences{n}
+}
+
+type sequences struct {
+	n *Node
+}
+
+// Next advances the counter from the value this node last saw, retrying if
+// another member got there first. A number whose advance had an unknown
+// outcome is skipped.
+func (q sequences) Next(name string) (int64, error) {
+	q.n.sequenceMux.Lock()
+	defer q.n.sequenceMux.Unlock()
+	for attempt := 0; ; attempt++ {
+		if err := q.n.linearize(); err != nil {
+			return 0, err
+		}
+		current := q.n.fsm.counters().Current(name)
+		err := q.n.apply(context.Background(), command{Op: opAdvanceSequence, Sequence: name, Version: current})
+		if err == nil {
+			return current + 1, nil
+		}
+		if err != store.ErrSequenceConflict || attempt+1 == maxSequenceAttempts {
+			return 0, err
+		}
+	}
+}
+
+// Courses returns the replicated courses.
+func (n *Node) Courses() store.CourseStore {
+	return courses{n}
+}
+
+type courses struct {
+	n *Node
+}
+
+func (c courses) Create(course store.Course) error {
+	return c.n.apply(context.Background(), command{Op: opCreateCourse, Course: &course})
+}
+
+func (c courses) Get(code string) (store.Course, error) {
+	if err := c.n.linearize(); err != nil {
+		return store.Course{}, err
+	}
+	all, _ := c.n.fsm.courseList()
+	return all.Get(code)
+}
+
+func (c courses) List() ([]store.Course, error) {
+	if err := c.n.linearize(); err != nil {
+		return nil, err
+	}
+	all, _ := c.n.fsm.courseList()
+	return all.List()
+}
+
+func (c courses) Update(course store.Course) error {
+	return c.n.apply(context.Background(), command{Op: opUpdateCourse, Course: &course})
+}
+
+func (c courses) Delete(code string) error {
+	return c.n.apply(context.Background(), command{Op: opDeleteCourse, Course: &store.Course{Code: code}})
+}
+
+// Enrollments returns the replicated enrollments in courses.
+func (n *Node) Enrollments() store.EnrollmentStore {
+	return enrollments{n}
+}
+
+type enrollments struct {
+	n *Node
+}
+
+func (e enrollments) Enroll(enrollment store.Enrollment) error {
+	return e.n.apply(context.Background(), command{Op: opEnroll, Enrollment: &enrollment})
+}
+
+func (e enrollments) Drop(courseCode, studentId string) error {
+	return e.n.apply(context.Background(), command{Op: opDrop, Enrollment: &store.Enrollment{CourseCode: courseCode, StudentId: studentId}})
+}
+
+func (e enrollments) ByCourse(courseCode string) ([]store.Enrollment, error) {
+	if err := e.n.linearize(); err != nil {
+		return nil, err
+	}
+	_, all := e.n.fsm.courseList()
+	return all.ByCourse(courseCode)
+}
+
+func (e enrollments) ByStudent(studentId string) ([]store.Enrollment, error) {
+	if err := e.n.linearize(); err != nil {
+		return nil, err
+	}
+	_, all := e.n.fsm.courseList()
+	return all.ByStudent(studentId)
+}
+
+// courseTookEffect is tookEffect for courses and enrollments: a retried
+// create, update or enroll finds its own course or enrollment and a retried
+// delete or drop finds nothing.
+func (n *Node) courseTookEffect(cmd command) bool {
+	all, enrolled := n.fsm.courseList()
+	switch cmd.Op {
+	case opCreateCourse, opUpdateCourse:
+		got, err := all.Get(cmd.Course.Code)
+		want := *cmd.Course
+		if want.Professions == nil {
+			want.Professions = []string{}
+		}
+		return err == nil && reflect.DeepEqual(got, want)
+	case opDeleteCourse:
+		_, err := all.Get(cmd.Course.Code)
+		return err == store.ErrCourseNotFound
+	}
+	list, _ := enrolled.ByStudent(cmd.Enrollment.StudentId)
+	for _, e := range list {
+		if e.CourseCode == cmd.Enrollment.CourseCode {
+			return cmd.Op == opEnroll && e == *cmd.Enrollment
+		}
+	}
+	return cmd.Op == opDrop
+}
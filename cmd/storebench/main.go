@@ -0,0 +1,107 @@
+// Command storebench measures the student store backends under concurrent
+// load. It runs the storetest benchmarks without the go test harness:
+//
+//	go run ./cmd/storebench -shards 1,32 -sqlite /tmp/bench.db
+//
+// A single shard stands in for a map behind one global lock. The watch/
+// backends wrap the memory store the way the server does, so their numbers
+// include the write locks and event publishing every write goes through.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mygolangproject/store"
+	"mygolangproject/store/sqlstore"
+	"mygolangproject/store/storetest"
+)
+
+var (
+	shards  = flag.String("shards", "1,"+strconv.Itoa(store.DefaultShards), "comma separated shard counts of the memory stores to measure")
+	durable = flag.Bool("durable", false, "also measure the write-ahead log store in a temporary directory")
+	sqlite  = flag.Bool("sqlite", false, "also measure the SQLite store in a temporary directory")
+)
+
+// eventBufferSize matches the event buffer of the server.
+const eventBufferSize = 1024
+
+type backend struct {
+	name string
+	open func() store.StudentStore
+}
+
+func main() {
+	testing.Init()
+	flag.Parse()
+
+	var backends []backend
+	for _, f := range strings.Split(*shards, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 1 {
+			log.Fatalf("bad shard count %q", f)
+		}
+		backends = append(backends, backend{
+			name: fmt.Sprintf("memory/shards=%d", n),
+			open: func() store.StudentStore { return store.NewShardedMemoryStore(n) },
+		}, backend{
+			name: fmt.Sprintf("watch/shards=%d", n),
+			open: func() store.StudentStore {
+				return store.NewWatchableStore(store.NewShardedMemoryStore(n), eventBufferSize)
+			},
+		})
+	}
+	dir, err := ioutil.TempDir("", "storebench")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	runs := 0
+	if *durable {
+		backends = append(backends, backend{name: "durable", open: func() store.StudentStore {
+			runs++
+			d, err := store.OpenDurableStore(filepath.Join(dir, fmt.Sprint("wal", runs)), store.DurableOptions{})
+			if err != nil {
+				log.Fatal(err)
+			}
+			return d
+		}})
+	}
+	if *sqlite {
+		backends = append(backends, backend{name: "sqlite", open: func() store.StudentStore {
+			runs++
+			db, err := sqlstore.Open(filepath.Join(dir, fmt.Sprint("students", runs, ".db")))
+			if err != nil {
+				log.Fatal(err)
+			}
+			return db
+		}})
+	}
+
+	fmt.Printf("GOMAXPROCS=%d, %d students\n", runtime.GOMAXPROCS(0), storetest.BenchStudents)
+	for _, be := range backends {
+		for _, bench := range storetest.Benchmarks {
+			var s store.StudentStore
+			res := testing.Benchmark(func(b *testing.B) {
+				if s == nil {
+					s = be.open()
+					storetest.Fill(b, s)
+				}
+				b.ResetTimer()
+				bench.F(b, s)
+			})
+			if c, ok := s.(interface{ Close() error }); ok {
+				c.Close()
+			}
+			fmt.Printf("%-20s %-10s %s %s\n", be.name, bench.Name, res, res.MemString())
+		}
+	}
+}
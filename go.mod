@@ -4,6 +4,11 @@ go 1.14
 
 require (
 	github.com/golang/protobuf v1.4.1
-	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/google/btree v1.0.1
+	github.com/hashicorp/raft v1.1.2
+	github.com/hashicorp/raft-boltdb v0.0.0-20171010151810-6e5ba93211ea
+	github.com/mattn/go-sqlite3 v1.14.0
+	github.com/satori/go.uuid v1.2.0
+	google.golang.org/genproto v0.0.0-20190819201941-24fa4b261c55
 	google.golang.org/grpc v1.29.1
 )
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"mygolangproject/store"
+)
+
+// Point-in-time reads are answered from the change history rather than the
+// store, so they only reach back to when history recording was enabled.
+
+// getAsOf returns a student as it was at asOf. Students soft deleted or not
+// yet registered at that time are reported as not found. Like findStudent,
+// it takes the student number of a live student, and it follows a retired
+// id to the student it had been merged into by asOf.
+func (s *Server) getAsOf(id string, asOf int64) (store.Student, error) {
+	studentId := id
+	if isStudentNumber(id) {
+		if stu, err := s.getByNumber(id); err == nil {
+			studentId = stu.Id
+		}
+	}
+	for hops := 0; hops < maxAliasHops; hops++ {
+		entries, err := s.history.List(studentId)
+		if err != nil {
+			return store.Student{}, storeError(err)
+		}
+		if stu, ok := store.StateAsOf(entries, asOf); ok && stu.DeletedTime == 0 {
+			return stu, nil
+		}
+		a, err := s.aliases.Get(studentId)
+		if err == store.ErrNoAlias || err == nil && a.Time > asOf {
+			break
+		}
+		if err != nil {
+			return store.Student{}, storeError(err)
+		}
+		studentId = a.SurvivorId
+	}
+	return store.Student{}, status.Errorf(codes.NotFound, "student %v did not exist at %d", id, asOf)
+}
+
+// listAsOf runs q against the students as they were at asOf.
+func (s *Server) listAsOf(q store.ListQuery, asOf int64) (store.ListResult, error) {
+	list, err := s.history.Snapshot(asOf)
+	if err != nil {
+		return store.ListResult{}, storeError(err)
+	}
+	return store.ApplyQuery(list, q), nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+const maxBulkRows = 10000
+
+// BulkRegister registers every student on the stream and reports the outcome
+// of each row. Rows are stored as they arrive, and rows past maxBulkRows
+// fail, unless the first message asks for allOrNothing, in which case the
+// whole batch is validated first, a batch that is too long is refused and
+// the rows are stored together or not at all; see createAll.
+func (s *Server) BulkRegister(stream pb.Service_BulkRegisterServer) error {
+	reply := &pb.BulkRegisterReply{}
+	var pending []*pb.RegisterRequest
+	allOrNothing := false
+	for row := int32(0); ; row++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if row == 0 {
+			allOrNothing = req.GetAllOrNothing()
+		}
+		if row >= maxBulkRows && allOrNothing {
+			// Nothing is written yet, so the whole batch can be refused.
+			return status.Errorf(codes.InvalidArgument, "at most %d rows per bulk register", maxBulkRows)
+		}
+		if row >= maxBulkRows {
+			// The rows before it are stored already and must be reported.
+			reply.Results = append(reply.Results, &pb.BulkRegisterResult{Row: row, Error: fmt.Sprintf("at most %d rows per bulk register", maxBulkRows)})
+			continue
+		}
+		if allOrNothing {
+			pending = append(pending, req.GetStudent())
+			continue
+		}
+		reply.Results = append(reply.Results, s.registerRow(stream.Context(), row, req.GetStudent()))
+	}
+	if allOrNothing {
+		reply.Results = s.registerAll(stream.Context(), pending)
+	}
+	for _, res := range reply.Results {
+		if res.Success {
+			reply.Succeeded++
+		} else {
+			reply.Failed++
+		}
+	}
+	log.Printf("bulk register: %d succeeded, %d failed", reply.Succeeded, reply.Failed)
+	return stream.SendAndClose(reply)
+}
+
+func (s *Server) registerRow(ctx context.Context, row int32, info *pb.RegisterRequest) *pb.BulkRegisterResult {
+	stu := s.studentFromRequest(info)
+	if err := s.checkStudent(&stu, ""); err != nil {
+		return &pb.BulkRegisterResult{Row: row, Error: errorMessage(err)}
+	}
+	if err := s.create(ctx, "bulkRegister", &stu); err != nil {
+		return &pb.BulkRegisterResult{Row: row, Error: errorMessage(err)}
+	}
+	return &pb.BulkRegisterResult{Row: row, Success: true, Id: stu.Id}
+}
+
+// registerAll stores every row if all of them are valid, or none of them.
+func (s *Server) registerAll(ctx context.Context, rows []*pb.RegisterRequest) []*pb.BulkRegisterResult {
+	results := make([]*pb.BulkRegisterResult, len(rows))
+	students := make([]store.Student, len(rows))
+	valid := true
+	for i, info := range rows {
+		results[i] = &pb.BulkRegisterResult{Row: int32(i)}
+		students[i] = s.studentFromRequest(info)
+		if err := s.checkStudent(&students[i], ""); err != nil {
+			results[i].Error = errorMessage(err)
+			valid = false
+		}
+	}
+	if !valid {
+		return rejectRemaining(results, "batch rejected: another row is invalid")
+	}
+
+	return s.createAll(ctx, "bulkRegister", students, results)
+}
+
+// createAll stores every student under action in one write to the store, so
+// that readers and watchers see all of them or none. Each student is checked
+// for duplicates among those stored and those before it in the batch, and
+// the batch as a whole against the capacities of its professions. If one
+// check or the write fails, every row fails; the student numbers drawn by
+// then are lost, as when a single registration fails.
+func (s *Server) createAll(ctx context.Context, action string, students []store.Student, results []*pb.BulkRegisterResult) []*pb.BulkRegisterResult {
+	keys := make([]string, len(students))
+	for i, stu := range students {
+		keys[i] = duplicateKey(stu)
+	}
+	unlock := s.registerLocks.lockAll(keys)
+	defer unlock()
+	valid := true
+	pending := make(pendingStudents)
+	for i := range students {
+		if err := s.checkDuplicate(&students[i], pending); err != nil {
+			results[i].Error = errorMessage(err)
+			valid = false
+		}
+		pending.add(students[i])
+	}
+	if !valid {
+		return rejectRemaining(results, "batch rejected: another row is a duplicate")
+	}
+	s.professionMux.RLock()
+	defer s.professionMux.RUnlock()
+	unlock, err := s.checkCapacities(students)
+	if err != nil {
+		return rejectRemaining(results, "batch rejected: "+errorMessage(storeError(err)))
+	}
+	defer unlock()
+	for i := range students {
+		if students[i].Number != "" {
+			continue
+		}
+		number, err := s.studentNumber(students[i])
+		if err != nil {
+			return rejectRemaining(results, "batch rejected: "+errorMessage(storeError(err)))
+		}
+		students[i].Number = number
+	}
+	if err := s.store.CreateAll(students); err != nil {
+		return rejectRemaining(results, "batch rejected: "+errorMessage(storeError(err)))
+	}
+	for i := range students {
+		stu := &students[i]
+		stu.Version = 1
+		s.record(ctx, action, nil, stu)
+		results[i].Success = true
+		results[i].Id = stu.Id
+	}
+	return results
+}
+
+// rejectRemaining marks every row without its own error as failed with reason.
+func rejectRemaining(results []*pb.BulkRegisterResult, reason string) []*pb.BulkRegisterResult {
+	for _, res := range results {
+		res.Success = false
+		res.Id = ""
+		if res.Error == "" {
+			res.Error = reason
+		}
+	}
+	return results
+}
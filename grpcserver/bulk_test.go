@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	pb "mygolangproject/proto"
+)
+
+// bulkRegister sends rows on one BulkRegister stream.
+func bulkRegister(t *testing.T, c pb.ServiceClient, allOrNothing bool, rows ...*pb.RegisterRequest) *pb.BulkRegisterReply {
+	t.Helper()
+	stream, err := c.BulkRegister(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range rows {
+		if err := stream.Send(&pb.BulkRegisterRequest{Student: row, AllOrNothing: allOrNothing && i == 0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("BulkRegister: %v", err)
+	}
+	return reply
+}
+
+func row(name string, age int32, profession string) *pb.RegisterRequest {
+	return &pb.RegisterRequest{Name: name, Age: age, Profession: profession}
+}
+
+func TestBulkRegisterAllOrNothing(t *testing.T) {
+	s, c := startServer(t)
+	revision := s.store.Revision()
+	reply := bulkRegister(t, c, true, row("alice", 20, "SE"), row("bob", 21, "SE"), row("carol", 22, "CST"))
+	if reply.Succeeded != 3 || reply.Failed != 0 {
+		t.Fatalf("reply = %v, want 3 succeeded", reply)
+	}
+	for _, res := range reply.Results {
+		if _, err := c.Query(context.Background(), &pb.QueryStudentRequest{Id: res.Id}); err != nil {
+			t.Fatalf("Query(%v) of row %d: %v", res.Id, res.Row, err)
+		}
+	}
+	if got := s.store.Revision() - revision; got != 3 {
+		t.Fatalf("published %d events, want 3", got)
+	}
+}
+
+// TestBulkRegisterAllOrNothingRejected checks that a batch that fails a
+// check made at write time leaves no trace: no student, no event and no
+// history entry.
+func TestBulkRegisterAllOrNothingRejected(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		setup func(*Server)
+		rows  []*pb.RegisterRequest
+		want  []string
+	}{{
+		name:  "capacity",
+		setup: func(s *Server) { setCapacity(t, s, "SE", 1) },
+		rows:  []*pb.RegisterRequest{row("alice", 20, "SE"), row("bob", 21, "SE")},
+		want:  []string{"room for 1 more students, not 2", "room for 1 more students, not 2"},
+	}, {
+		name:  "duplicate within the batch",
+		setup: func(s *Server) { s.duplicates = duplicatesReject },
+		rows:  []*pb.RegisterRequest{row("alice", 20, "SE"), row("alicia", 20, "SE"), row("alise", 20, "SE")},
+		want:  []string{"another row is a duplicate", "another row is a duplicate", "duplicate of student"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, c := startServer(t)
+			tc.setup(s)
+			revision := s.store.Revision()
+			reply := bulkRegister(t, c, true, tc.rows...)
+			if reply.Succeeded != 0 || int(reply.Failed) != len(tc.rows) {
+				t.Fatalf("reply = %v, want every row failed", reply)
+			}
+			for i, res := range reply.Results {
+				if res.Id != "" || !strings.Contains(res.Error, tc.want[i]) {
+					t.Errorf("row %d = %v, want no id and an error containing %q", i, res, tc.want[i])
+				}
+			}
+			if n := storedStudents(t, s); n != 0 {
+				t.Fatalf("%d students stored, want none", n)
+			}
+			if s.store.Revision() != revision {
+				t.Fatalf("rejected batch published %d events", s.store.Revision()-revision)
+			}
+		})
+	}
+}
+
+// TestBulkRegisterTooManyRows checks that rows past the limit fail on their
+// own while the rows before them are stored and reported.
+func TestBulkRegisterTooManyRows(t *testing.T) {
+	s, c := startServer(t)
+	s.duplicates = duplicatesOff
+	rows := make([]*pb.RegisterRequest, maxBulkRows+2)
+	for i := range rows {
+		rows[i] = row("tom", int32(10+i%90), "SE")
+	}
+	reply := bulkRegister(t, c, false, rows...)
+	if reply.Succeeded != maxBulkRows || reply.Failed != 2 || len(reply.Results) != len(rows) {
+		t.Fatalf("reply has %d results, %d succeeded and %d failed, want %d results, %d succeeded and 2 failed",
+			len(reply.Results), reply.Succeeded, reply.Failed, len(rows), maxBulkRows)
+	}
+	last := reply.Results[len(rows)-1]
+	if want := fmt.Sprintf("at most %d rows", maxBulkRows); last.Success || !strings.Contains(last.Error, want) {
+		t.Fatalf("last row = %v, want an error containing %q", last, want)
+	}
+	if n := storedStudents(t, s); n != maxBulkRows {
+		t.Fatalf("%d students stored, want %d", n, maxBulkRows)
+	}
+
+	stream, err := c.BulkRegister(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		if err := stream.Send(&pb.BulkRegisterRequest{Student: rows[i], AllOrNothing: i == 0}); err != nil {
+			break
+		}
+	}
+	_, err = stream.CloseAndRecv()
+	wantCode(t, err, codes.InvalidArgument)
+	if n := storedStudents(t, s); n != maxBulkRows {
+		t.Fatalf("refused batch stored %d students", n-maxBulkRows)
+	}
+}
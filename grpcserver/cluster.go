@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"mygolangproject/cluster"
+)
+
+var (
+	nodeId    = flag.String("node-id", "", "unique id of this node in a replicated cluster")
+	raftAddr  = flag.String("raft-addr", "", "host:port for Raft traffic between nodes; setting it enables clustering. Capacity, duplicate and idempotency-key checks only hold among the writes one node serves; purging and key expiry run on the leader")
+	raftDir   = flag.String("raft-dir", "", "directory for the Raft log and snapshots")
+	advertise = flag.String("advertise", "", "host:port other nodes reach this gRPC server at")
+	bootstrap = flag.Bool("bootstrap", false, "start a new cluster with this node as its first member")
+	join      = flag.String("join", "", "gRPC address of a member of the cluster to join")
+)
+
+func openCluster() (*cluster.Node, error) {
+	if *nodeId == "" || *advertise == "" || *raftDir == "" {
+		log.Fatal("-node-id, -advertise and -raft-dir are required with -raft-addr")
+	}
+	return cluster.Open(cluster.Config{
+		ID:          *nodeId,
+		RaftAddress: *raftAddr,
+		GRPCAddress: *advertise,
+		Dir:         *raftDir,
+		Bootstrap:   *bootstrap,
+	})
+}
+
+// joinCluster adds the node to the cluster of the -join member. It needs the
+// gRPC server to be serving, since the leader calls back into it.
+func joinCluster(node *cluster.Node) {
+	if err := node.JoinVia(context.Background(), *join); err != nil {
+		log.Fatalf("failed to join %v: %v", *join, err)
+	}
+	log.Printf("joined the cluster via %v", *join)
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// courseCode keeps codes short enough to read out, such as DB101.
+var courseCode = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,15}$`).MatchString
+
+func toCourse(c *pb.Course) store.Course {
+	return store.Course{
+		Code:        c.GetCode(),
+		Title:       c.GetTitle(),
+		Credits:     c.GetCredits(),
+		Professions: c.GetProfessions(),
+		Capacity:    c.GetCapacity(),
+	}
+}
+
+func (s *Server) toCourseInfo(c store.Course) (*pb.Course, error) {
+	enrolled, err := s.enrollments.ByCourse(c.Code)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.Course{
+		Code:        c.Code,
+		Title:       c.Title,
+		Credits:     c.Credits,
+		Professions: c.Professions,
+		Capacity:    c.Capacity,
+		Enrolled:    int32(len(enrolled)),
+	}, nil
+}
+
+func toEnrollment(e store.Enrollment) *pb.Enrollment {
+	return &pb.Enrollment{CourseCode: e.CourseCode, StudentId: e.StudentId, Time: e.Time}
+}
+
+// openTo reports whether students of the given profession may take c.
+func openTo(c store.Course, profession string) bool {
+	return len(c.Professions) == 0 || listsProfession(c, profession)
+}
+
+// listsProfession reports whether c names profession among those it is open
+// to.
+func listsProfession(c store.Course, profession string) bool {
+	for _, p := range c.Professions {
+		if p == profession {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCourse validates a course about to be stored and replaces the
+// professions it is open to, given as codes or names, with their codes.
+// s.professionMux must be read locked across the check and the write, so
+// that none of the professions is deleted in between.
+func (s *Server) checkCourse(c *store.Course) error {
+	var errs fieldErrors
+	if !courseCode(c.Code) {
+		errs.add("code", "must be up to 16 upper case letters and digits, starting with a letter")
+	}
+	if c.Title == "" {
+		errs.add("title", "is required")
+	}
+	if c.Credits <= 0 {
+		errs.add("credits", "must be positive")
+	}
+	if c.Capacity < 0 {
+		errs.add("capacity", "must not be negative")
+	}
+	known := make([]string, 0, len(c.Professions))
+	seen := make(map[string]bool)
+	for _, profession := range c.Professions {
+		p, err := s.resolveProfession(profession)
+		if err == store.ErrProfessionNotFound {
+			errs.add("professions", "unknown profession %q", profession)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if seen[p.Code] {
+			errs.add("professions", "profession %v is listed twice", p.Code)
+			continue
+		}
+		seen[p.Code] = true
+		known = append(known, p.Code)
+	}
+	c.Professions = known
+	return errs.err()
+}
+
+func (s *Server) CreateCourse(_ context.Context, info *pb.Course) (*pb.Course, error) {
+	c := toCourse(info)
+	s.professionMux.RLock()
+	defer s.professionMux.RUnlock()
+	s.courseMux.Lock()
+	defer s.courseMux.Unlock()
+	if err := s.checkCourse(&c); err != nil {
+		log.Print(err)
+		return &pb.Course{}, storeError(err)
+	}
+	if err := s.courses.Create(c); err != nil {
+		log.Print(err)
+		return &pb.Course{}, storeError(err)
+	}
+	log.Printf("create course %v success", c.Code)
+	return s.toCourseInfo(c)
+}
+
+func (s *Server) GetCourse(_ context.Context, req *pb.CourseRequest) (*pb.Course, error) {
+	c, err := s.courses.Get(req.GetCode())
+	if err != nil {
+		return &pb.Course{}, storeError(err)
+	}
+	return s.toCourseInfo(c)
+}
+
+func (s *Server) ListCourses(_ context.Context, req *pb.ListCoursesRequest) (*pb.CourseList, error) {
+	profession := req.GetProfession()
+	if profession != "" {
+		if p, err := s.resolveProfession(profession); err == nil {
+			profession = p.Code
+		}
+	}
+	list, err := s.courses.List()
+	if err != nil {
+		return &pb.CourseList{}, storeError(err)
+	}
+	reply := &pb.CourseList{}
+	for _, c := range list {
+		if profession != "" && !openTo(c, profession) {
+			continue
+		}
+		info, err := s.toCourseInfo(c)
+		if err != nil {
+			return &pb.CourseList{}, err
+		}
+		reply.Courses = append(reply.Courses, info)
+	}
+	return reply, nil
+}
+
+// UpdateCourse replaces the title, credits, professions and capacity. Like
+// lowering the capacity below the current enrollment, closing a course to a
+// profession only stops new students from enrolling.
+func (s *Server) UpdateCourse(_ context.Context, info *pb.Course) (*pb.Course, error) {
+	c := toCourse(info)
+	s.professionMux.RLock()
+	defer s.professionMux.RUnlock()
+	s.courseMux.Lock()
+	defer s.courseMux.Unlock()
+	if err := s.checkCourse(&c); err != nil {
+		log.Print(err)
+		return &pb.Course{}, storeError(err)
+	}
+	if err := s.courses.Update(c); err != nil {
+		log.Print(err)
+		return &pb.Course{}, storeError(err)
+	}
+	log.Printf("update course %v success", c.Code)
+	return s.toCourseInfo(c)
+}
+
+// DeleteCourse removes a course no student is enrolled in.
+func (s *Server) DeleteCourse(_ context.Context, req *pb.CourseRequest) (*pb.Result, error) {
+	s.courseMux.Lock()
+	defer s.courseMux.Unlock()
+	enrolled, err := s.enrollments.ByCourse(req.GetCode())
+	if err != nil {
+		return &pb.Result{Res: false}, storeError(err)
+	}
+	if len(enrolled) > 0 {
+		return &pb.Result{Res: false}, status.Errorf(codes.FailedPrecondition, "course %v has %d students enrolled", req.GetCode(), len(enrolled))
+	}
+	if err := s.courses.Delete(req.GetCode()); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, storeError(err)
+	}
+	log.Printf("delete course %v success", req.GetCode())
+	return &pb.Result{Res: true}, nil
+}
+
+func checkEnrollmentRequest(req *pb.EnrollmentRequest) error {
+	var errs fieldErrors
+	if req.GetCourseCode() == "" {
+		errs.add("courseCode", "is required")
+	}
+	if req.GetStudentId() == "" {
+		errs.add("studentId", "is required")
+	}
+	return errs.err()
+}
+
+// Enroll enrolls a live student, found like Query finds it, in a course
+// that is open to its profession and not full.
+func (s *Server) Enroll(_ context.Context, req *pb.EnrollmentRequest) (*pb.Enrollment, error) {
+	if err := checkEnrollmentRequest(req); err != nil {
+		return &pb.Enrollment{}, err
+	}
+	stu, err := s.findStudent(req.GetStudentId())
+	if err != nil {
+		log.Print(err)
+		return &pb.Enrollment{}, err
+	}
+	s.courseMux.Lock()
+	defer s.courseMux.Unlock()
+	c, err := s.courses.Get(req.GetCourseCode())
+	if err != nil {
+		log.Print(err)
+		return &pb.Enrollment{}, storeError(err)
+	}
+	if !openTo(c, stu.Profession) {
+		return &pb.Enrollment{}, status.Errorf(codes.FailedPrecondition, "course %v is not open to profession %v", c.Code, stu.Profession)
+	}
+	if c.Capacity != 0 {
+		enrolled, err := s.enrollments.ByCourse(c.Code)
+		if err != nil {
+			return &pb.Enrollment{}, storeError(err)
+		}
+		for _, e := range enrolled {
+			if e.StudentId == stu.Id {
+				return &pb.Enrollment{}, storeError(store.ErrAlreadyEnrolled)
+			}
+		}
+		if len(enrolled) >= int(c.Capacity) {
+			return &pb.Enrollment{}, status.Errorf(codes.FailedPrecondition, "course %v is full with %d students", c.Code, len(enrolled))
+		}
+	}
+	e := store.Enrollment{CourseCode: c.Code, StudentId: stu.Id, Time: time.Now().Unix()}
+	if err := s.enrollments.Enroll(e); err != nil {
+		log.Print(err)
+		return &pb.Enrollment{}, storeError(err)
+	}
+	// A student deleted meanwhile may have been taken out of its courses
+	// before this enrollment was there to drop.
+	if _, err := s.getLive(stu.Id); status.Code(err) == codes.NotFound {
+		s.dropEnrollments(stu.Id)
+		return &pb.Enrollment{}, err
+	}
+	log.Printf("enroll student %v in course %v success", stu.Id, c.Code)
+	return toEnrollment(e), nil
+}
+
+// Drop takes a student out of a course. The id of a student that is gone
+// is taken as it is, so what a failed cleanup left behind can be dropped.
+func (s *Server) Drop(_ context.Context, req *pb.EnrollmentRequest) (*pb.Result, error) {
+	if err := checkEnrollmentRequest(req); err != nil {
+		return &pb.Result{Res: false}, err
+	}
+	studentId := req.GetStudentId()
+	stu, err := s.findStudent(studentId)
+	switch {
+	case err == nil:
+		studentId = stu.Id
+	case status.Code(err) != codes.NotFound:
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
+	if err := s.enrollments.Drop(req.GetCourseCode(), studentId); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, storeError(err)
+	}
+	log.Printf("drop student %v from course %v success", studentId, req.GetCourseCode())
+	return &pb.Result{Res: true}, nil
+}
+
+func (s *Server) ListEnrollments(_ context.Context, req *pb.ListEnrollmentsRequest) (*pb.EnrollmentList, error) {
+	var list []store.Enrollment
+	var err error
+	switch {
+	case req.GetCourseCode() != "" && req.GetStudentId() != "":
+		return &pb.EnrollmentList{}, invalidField("studentId", "must not be given along with courseCode")
+	case req.GetCourseCode() != "":
+		if _, err := s.courses.Get(req.GetCourseCode()); err != nil {
+			return &pb.EnrollmentList{}, storeError(err)
+		}
+		list, err = s.enrollments.ByCourse(req.GetCourseCode())
+	case req.GetStudentId() != "":
+		stu, findErr := s.findStudent(req.GetStudentId())
+		if findErr != nil {
+			return &pb.EnrollmentList{}, findErr
+		}
+		list, err = s.enrollments.ByStudent(stu.Id)
+	default:
+		return &pb.EnrollmentList{}, invalidField("courseCode", "either courseCode or studentId is required")
+	}
+	if err != nil {
+		log.Print(err)
+		return &pb.EnrollmentList{}, storeError(err)
+	}
+	reply := &pb.EnrollmentList{}
+	for _, e := range list {
+		reply.Enrollments = append(reply.Enrollments, toEnrollment(e))
+	}
+	return reply, nil
+}
+
+// dropEnrollments takes a student that is being deleted out of every
+// course. Failures are logged and left for purging the student to retry.
+func (s *Server) dropEnrollments(studentId string) {
+	list, err := s.enrollments.ByStudent(studentId)
+	if err != nil {
+		log.Printf("drop enrollments of %v failed: %v", studentId, err)
+		return
+	}
+	for _, e := range list {
+		if err := s.enrollments.Drop(e.CourseCode, studentId); err != nil && err != store.ErrNotEnrolled {
+			log.Printf("drop enrollment of %v in course %v failed: %v", studentId, e.CourseCode, err)
+		}
+	}
+}
+
+// moveEnrollments hands the courses of a retired student over to the
+// student it was merged into, keeping when it enrolled, unless that one
+// already takes them. Neither capacity nor profession is checked: the
+// survivor only takes over places the retired student held.
+func (s *Server) moveEnrollments(retiredId, survivorId string) {
+	list, err := s.enrollments.ByStudent(retiredId)
+	if err != nil {
+		log.Printf("move enrollments of %v failed: %v", retiredId, err)
+		return
+	}
+	for _, e := range list {
+		moved := e
+		moved.StudentId = survivorId
+		if err := s.enrollments.Enroll(moved); err != nil && err != store.ErrAlreadyEnrolled {
+			log.Printf("move enrollment of %v in course %v failed: %v", retiredId, e.CourseCode, err)
+			continue
+		}
+		if err := s.enrollments.Drop(e.CourseCode, retiredId); err != nil && err != store.ErrNotEnrolled {
+			log.Printf("drop enrollment of %v in course %v failed: %v", retiredId, e.CourseCode, err)
+		}
+	}
+}
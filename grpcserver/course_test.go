@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	pb "mygolangproject/proto"
+)
+
+func mustCreateCourse(t *testing.T, c pb.ServiceClient, course *pb.Course) {
+	t.Helper()
+	if _, err := c.CreateCourse(context.Background(), course); err != nil {
+		t.Fatalf("CreateCourse(%v): %v", course.Code, err)
+	}
+}
+
+func enroll(c pb.ServiceClient, course, studentId string) error {
+	_, err := c.Enroll(context.Background(), &pb.EnrollmentRequest{CourseCode: course, StudentId: studentId})
+	return err
+}
+
+func TestEnrollChecks(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	mustCreateCourse(t, c, &pb.Course{Code: "DB101", Title: "Databases", Credits: 3, Professions: []string{"SE"}, Capacity: 2})
+	mustCreateCourse(t, c, &pb.Course{Code: "ART", Title: "Art", Credits: 1})
+	se := mustRegister(t, c, "alice", 20, "SE")
+	se2 := mustRegister(t, c, "bob", 20, "SE")
+	se3 := mustRegister(t, c, "carol", 20, "SE")
+	cst := mustRegister(t, c, "dave", 20, "CST")
+	deleted := mustRegister(t, c, "erin", 20, "SE")
+	if _, err := c.Delete(ctx, &pb.StudentInfo{Id: deleted.Id}); err != nil {
+		t.Fatal(err)
+	}
+	const missing = "0190f5a8-0000-7000-8000-000000000000"
+	tests := []struct {
+		name      string
+		course    string
+		studentId string
+		want      codes.Code
+	}{
+		{"by id", "DB101", se.Id, codes.OK},
+		{"by number", "DB101", se2.Number, codes.OK},
+		{"again", "DB101", se.Id, codes.AlreadyExists},
+		{"full", "DB101", se3.Id, codes.FailedPrecondition},
+		{"other profession", "DB101", cst.Id, codes.FailedPrecondition},
+		{"open course", "ART", cst.Id, codes.OK},
+		{"deleted student", "ART", deleted.Id, codes.NotFound},
+		{"unknown student", "ART", missing, codes.NotFound},
+		{"unknown course", "NOPE", se.Id, codes.NotFound},
+		{"no course", "", se.Id, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, enroll(c, tt.course, tt.studentId), tt.want)
+		})
+	}
+	course, err := c.GetCourse(ctx, &pb.CourseRequest{Code: "DB101"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if course.Enrolled != 2 {
+		t.Errorf("DB101 has %d students enrolled, want 2", course.Enrolled)
+	}
+	if _, err := c.Drop(ctx, &pb.EnrollmentRequest{CourseCode: "DB101", StudentId: se.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enroll(c, "DB101", se3.Id); err != nil {
+		t.Errorf("enroll after a drop freed a place: %v", err)
+	}
+}
+
+// TestEnrollCapacityConcurrent enrolls more students at once than a course
+// has places for.
+func TestEnrollCapacityConcurrent(t *testing.T) {
+	_, c := startServer(t)
+	const capacity, students = 3, 10
+	mustCreateCourse(t, c, &pb.Course{Code: "DB101", Title: "Databases", Credits: 3, Capacity: capacity})
+	var ids []string
+	for _, name := range []string{"a", "bb", "ccc", "dddd", "eeeee", "ffffff", "ggggggg", "hhhhhhhh", "iiiiiiiii", "jjjjjjjjjj"} {
+		ids = append(ids, mustRegister(t, c, name, 20, "SE").Id)
+	}
+	errs := make(chan error, students)
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			errs <- enroll(c, "DB101", id)
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	enrolled := 0
+	for err := range errs {
+		if err == nil {
+			enrolled++
+			continue
+		}
+		wantCode(t, err, codes.FailedPrecondition)
+	}
+	if enrolled != capacity {
+		t.Errorf("%d students enrolled in a course for %d", enrolled, capacity)
+	}
+}
+
+func TestDeleteDropsEnrollments(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	mustCreateCourse(t, c, &pb.Course{Code: "DB101", Title: "Databases", Credits: 3, Capacity: 1})
+	stu := mustRegister(t, c, "alice", 20, "SE")
+	if err := enroll(c, "DB101", stu.Id); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.DeleteCourse(ctx, &pb.CourseRequest{Code: "DB101"})
+	wantCode(t, err, codes.FailedPrecondition)
+	if _, err := c.Delete(ctx, &pb.StudentInfo{Id: stu.Id}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.ListEnrollments(ctx, &pb.ListEnrollmentsRequest{CourseCode: "DB101"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Enrollments) != 0 {
+		t.Errorf("deleted student still enrolled: %v", list.Enrollments)
+	}
+	if err := enroll(c, "DB101", mustRegister(t, c, "bob", 20, "SE").Id); err != nil {
+		t.Errorf("enroll in the place of a deleted student: %v", err)
+	}
+}
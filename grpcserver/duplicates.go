@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// maxNameDistance bounds how far apart names may be for FindDuplicates; any
+// further and most short names would match each other.
+const maxNameDistance = 3
+
+var (
+	duplicates        = flag.String("duplicates", "flag", "what Register, BulkRegister and ImportStudents do with a student of the same age and profession as an existing one and a matching name: off, flag (store it marked for review) or reject")
+	duplicateDistance = flag.Int("duplicate-distance", 1, "how many letters the names of possible duplicates may differ by, ignoring case; 0 matches equal names only")
+)
+
+// duplicateMode is what Register does with a possible duplicate.
+type duplicateMode int
+
+const (
+	duplicatesOff duplicateMode = iota
+	duplicatesFlag
+	duplicatesReject
+)
+
+var duplicateModes = map[string]duplicateMode{
+	"off":    duplicatesOff,
+	"flag":   duplicatesFlag,
+	"reject": duplicatesReject,
+}
+
+func parseDuplicateMode(mode string) (duplicateMode, error) {
+	m, ok := duplicateModes[mode]
+	if !ok {
+		return 0, fmt.Errorf("unknown duplicate mode %q, want off, flag or reject", mode)
+	}
+	return m, nil
+}
+
+// nameDistance is the number of letters to insert, delete or replace to turn
+// one name into the other, ignoring case.
+func nameDistance(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			next := min(row[j]+1, row[j-1]+1, diag+cost)
+			diag, row[j] = row[j], next
+		}
+	}
+	return row[len(b)]
+}
+
+func min(first int, rest ...int) int {
+	for _, v := range rest {
+		if v < first {
+			first = v
+		}
+	}
+	return first
+}
+
+// duplicateKey is what a student shares with every student it may be taken
+// for a duplicate of.
+func duplicateKey(stu store.Student) string {
+	return fmt.Sprintf("%v/%d", stu.Profession, stu.Age)
+}
+
+// pendingStudents are live students about to be stored ahead of others, by
+// duplicateKey. They count as registered after every stored student.
+type pendingStudents map[string][]store.Student
+
+func (p pendingStudents) add(stu store.Student) {
+	if stu.DeletedTime == 0 {
+		key := duplicateKey(stu)
+		p[key] = append(p[key], stu)
+	}
+}
+
+// findDuplicate returns the live student stu most likely duplicates among
+// those stored and pending: one of the same age and profession whose name is
+// closest to stu's, within s.nameDistance, the earliest registered of
+// equally close ones.
+func (s *Server) findDuplicate(stu store.Student, pending pendingStudents) (*store.Student, error) {
+	res, err := s.store.List(store.ListQuery{
+		Filter:    store.Filter{Profession: stu.Profession, MinAge: stu.Age, MaxAge: stu.Age},
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Students = append(res.Students, pending[duplicateKey(stu)]...)
+	var found *store.Student
+	best := s.nameDistance + 1
+	for i, other := range res.Students {
+		if d := nameDistance(stu.Name, other.Name); d < best {
+			found, best = &res.Students[i], d
+		}
+	}
+	return found, nil
+}
+
+// checkDuplicate applies the duplicate mode to a student about to be
+// registered: it flags stu or refuses it with ALREADY_EXISTS naming the
+// existing student. The lock of duplicateKey(stu) in s.registerLocks must be
+// held across the check and the write.
+func (s *Server) checkDuplicate(stu *store.Student, pending pendingStudents) error {
+	if s.duplicates == duplicatesOff {
+		return nil
+	}
+	dup, err := s.findDuplicate(*stu, pending)
+	if err != nil || dup == nil {
+		return storeError(err)
+	}
+	if s.duplicates == duplicatesFlag {
+		stu.DuplicateOf = dup.Id
+		return nil
+	}
+	st := status.Newf(codes.AlreadyExists, "student %v looks like a duplicate of student %v", stu.Name, dup.Id)
+	if detailed, err := st.WithDetails(&errdetails.ResourceInfo{
+		ResourceType: "student",
+		ResourceName: dup.Id,
+		Description:  "registered student of the same age and profession with a matching name",
+	}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// FindDuplicates reports the groups of live students that Register would
+// have taken for duplicates of each other. Names match across a chain, so a
+// group may hold names further apart than maxDistance.
+func (s *Server) FindDuplicates(_ context.Context, req *pb.FindDuplicatesRequest) (*pb.DuplicateReport, error) {
+	distance := s.nameDistance
+	switch {
+	case req.GetExactOnly():
+		distance = 0
+	case req.GetMaxDistance() < 0 || req.GetMaxDistance() > maxNameDistance:
+		return &pb.DuplicateReport{}, invalidField("maxDistance", "must be between 0 and %d", maxNameDistance)
+	case req.GetMaxDistance() != 0:
+		distance = int(req.GetMaxDistance())
+	}
+	profession := req.GetProfession()
+	if profession != "" {
+		if p, err := s.resolveProfession(profession); err == nil {
+			profession = p.Code
+		}
+	}
+	res, err := s.store.List(store.ListQuery{Filter: store.Filter{Profession: profession}, Ascending: true})
+	if err != nil {
+		log.Print(err)
+		return &pb.DuplicateReport{}, storeError(err)
+	}
+	report := &pb.DuplicateReport{}
+	for _, group := range groupDuplicates(res.Students, distance) {
+		g := &pb.DuplicateGroup{}
+		for _, stu := range group {
+			g.Students = append(g.Students, s.toStudentInfo(stu))
+		}
+		report.Groups = append(report.Groups, g)
+	}
+	log.Printf("found %d groups of possible duplicates", len(report.Groups))
+	return report, nil
+}
+
+// groupDuplicates joins students, oldest first, of the same age and
+// profession whose names are at most distance apart into groups, in the
+// order of their oldest members. Students without a match are left out.
+func groupDuplicates(list []store.Student, distance int) [][]store.Student {
+	type bucket struct {
+		profession string
+		age        int32
+	}
+	parent := make([]int, len(list))
+	for i := range parent {
+		parent[i] = i
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	buckets := make(map[bucket][]int)
+	for i, stu := range list {
+		b := bucket{stu.Profession, stu.Age}
+		for _, j := range buckets[b] {
+			if nameDistance(stu.Name, list[j].Name) <= distance {
+				parent[root(i)] = root(j)
+			}
+		}
+		buckets[b] = append(buckets[b], i)
+	}
+	members := make(map[int][]store.Student)
+	var roots []int
+	for i, stu := range list {
+		r := root(i)
+		if members[r] == nil {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], stu)
+	}
+	var groups [][]store.Student
+	for _, r := range roots {
+		if len(members[r]) > 1 {
+			groups = append(groups, members[r])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+)
+
+func TestNameDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"alice", "alice", 0},
+		{"Alice", "aLICE", 0},
+		{"alice", "alicd", 1},
+		{"alice", "alce", 1},
+		{"alice", "alicia", 2},
+		{"", "bob", 3},
+		{"alice", "bob", 5},
+	}
+	for _, tt := range tests {
+		if got := nameDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("nameDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := nameDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("nameDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterDuplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        pb.RegisterRequest
+		duplicates bool
+	}{
+		{"same", pb.RegisterRequest{Name: "alice", Age: 20, Profession: "SE"}, true},
+		{"other case", pb.RegisterRequest{Name: "ALICE", Age: 20, Profession: "SE"}, true},
+		{"one letter off", pb.RegisterRequest{Name: "alicd", Age: 20, Profession: "SE"}, true},
+		{"profession by name", pb.RegisterRequest{Name: "alice", Age: 20, Profession: "Software Engineering"}, true},
+		{"two letters off", pb.RegisterRequest{Name: "alicia", Age: 20, Profession: "SE"}, false},
+		{"other age", pb.RegisterRequest{Name: "alice", Age: 21, Profession: "SE"}, false},
+		{"other profession", pb.RegisterRequest{Name: "alice", Age: 20, Profession: "CST"}, false},
+	}
+	modes := []struct {
+		mode duplicateMode
+		name string
+	}{{duplicatesOff, "off"}, {duplicatesFlag, "flag"}, {duplicatesReject, "reject"}}
+	for _, m := range modes {
+		for _, tt := range tests {
+			t.Run(m.name+"/"+tt.name, func(t *testing.T) {
+				s, c := startServer(t)
+				s.duplicates = m.mode
+				ctx := context.Background()
+				first := mustRegister(t, c, "alice", 20, "SE")
+				reply, err := c.Register(ctx, &tt.req)
+				if m.mode == duplicatesReject && tt.duplicates {
+					wantCode(t, err, codes.AlreadyExists)
+					if got := existingStudent(err); got != first.Id {
+						t.Errorf("rejection names %q, want %v", got, first.Id)
+					}
+					if n := storedStudents(t, s); n != 1 {
+						t.Errorf("%d students stored after a rejection, want 1", n)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := c.Query(ctx, &pb.QueryStudentRequest{Id: reply.Id})
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := ""
+				if m.mode == duplicatesFlag && tt.duplicates {
+					want = first.Id
+				}
+				if got.DuplicateOf != want {
+					t.Errorf("duplicateOf = %q, want %q", got.DuplicateOf, want)
+				}
+			})
+		}
+	}
+}
+
+// existingStudent returns the student an ALREADY_EXISTS error names.
+func existingStudent(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if resource, ok := detail.(*errdetails.ResourceInfo); ok {
+			return resource.GetResourceName()
+		}
+	}
+	return ""
+}
+
+func TestRegisterDuplicateOfDeleted(t *testing.T) {
+	s, c := startServer(t)
+	s.duplicates = duplicatesReject
+	ctx := context.Background()
+	first := mustRegister(t, c, "alice", 20, "SE")
+	if _, err := c.Delete(ctx, &pb.StudentInfo{Id: first.Id}); err != nil {
+		t.Fatal(err)
+	}
+	mustRegister(t, c, "alice", 20, "SE")
+}
+
+func TestUpdateStudentClearsDuplicateFlag(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	first := mustRegister(t, c, "alice", 20, "SE")
+	second := mustRegister(t, c, "alice", 20, "SE")
+	flagged, err := c.Query(ctx, &pb.QueryStudentRequest{Id: second.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flagged.DuplicateOf != first.Id {
+		t.Fatalf("duplicateOf = %q, want %v", flagged.DuplicateOf, first.Id)
+	}
+	// An empty mask leaves the flag alone.
+	got, err := c.UpdateStudent(ctx, &pb.UpdateStudentRequest{Student: &pb.StudentInfo{
+		Id: second.Id, Name: "alicia", Age: 20, Profession: "SE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DuplicateOf != first.Id {
+		t.Errorf("duplicateOf = %q after an update without it, want %v", got.DuplicateOf, first.Id)
+	}
+	got, err = c.UpdateStudent(ctx, &pb.UpdateStudentRequest{
+		Student: &pb.StudentInfo{Id: second.Id}, UpdateMask: &field_mask.FieldMask{Paths: []string{"duplicateOf"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DuplicateOf != "" || got.Name != "alicia" {
+		t.Errorf("got duplicateOf %q, name %v, want the flag cleared only", got.DuplicateOf, got.Name)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	s, c := startServer(t)
+	s.duplicates = duplicatesOff
+	ctx := context.Background()
+	alice := mustRegister(t, c, "alice", 20, "SE")
+	alicd := mustRegister(t, c, "alicd", 20, "SE")
+	alicia := mustRegister(t, c, "alicia", 20, "SE")
+	mustRegister(t, c, "alice", 21, "SE")
+	mustRegister(t, c, "alice", 20, "CST")
+	bob := mustRegister(t, c, "bob", 30, "CST")
+	bOB := mustRegister(t, c, "BOB", 30, "CST")
+	deleted := mustRegister(t, c, "bob", 30, "CST")
+	if _, err := c.Delete(ctx, &pb.StudentInfo{Id: deleted.Id}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		req  pb.FindDuplicatesRequest
+		want [][]string
+	}{
+		{"server distance", pb.FindDuplicatesRequest{}, [][]string{{alice.Id, alicd.Id}, {bob.Id, bOB.Id}}},
+		{"wider", pb.FindDuplicatesRequest{MaxDistance: 2}, [][]string{{alice.Id, alicd.Id, alicia.Id}, {bob.Id, bOB.Id}}},
+		{"exact", pb.FindDuplicatesRequest{ExactOnly: true, MaxDistance: 2}, [][]string{{bob.Id, bOB.Id}}},
+		{"one profession", pb.FindDuplicatesRequest{Profession: "Software Engineering"}, [][]string{{alice.Id, alicd.Id}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := c.FindDuplicates(ctx, &tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got [][]string
+			for _, g := range report.Groups {
+				var ids []string
+				for _, stu := range g.Students {
+					ids = append(ids, stu.Id)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	_, err := c.FindDuplicates(ctx, &pb.FindDuplicatesRequest{MaxDistance: maxNameDistance + 1})
+	wantCode(t, err, codes.InvalidArgument)
+}
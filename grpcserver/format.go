@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// utf8BOM starts every CSV export so that spreadsheets read the Chinese
+// profession names as UTF-8.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// transferColumns are the exported fields, in CSV column order.
+var transferColumns = []string{"id", "name", "age", "profession", "createTime", "modifiedTime"}
+
+// transferRecord is a student as it is exported and imported. Times are
+// RFC 3339 with nanoseconds; on import id and both times are optional.
+type transferRecord struct {
+	Id           string `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Age          int32  `json:"age"`
+	Profession   string `json:"profession"`
+	CreateTime   string `json:"createTime,omitempty"`
+	ModifiedTime string `json:"modifiedTime,omitempty"`
+}
+
+func toTransferRecord(stu store.Student) transferRecord {
+	return transferRecord{
+		Id:           stu.Id,
+		Name:         stu.Name,
+		Age:          stu.Age,
+		Profession:   stu.Profession,
+		CreateTime:   time.Unix(0, stu.CreateTime).Format(time.RFC3339Nano),
+		ModifiedTime: time.Unix(0, stu.ModifiedTime).Format(time.RFC3339Nano),
+	}
+}
+
+// student converts an imported record. Missing times default to now for
+// createTime and to createTime for modifiedTime.
+func (r transferRecord) student() (store.Student, error) {
+	stu := store.Student{Id: r.Id, Name: r.Name, Age: r.Age, Profession: r.Profession, CreateTime: time.Now().UnixNano()}
+	if r.CreateTime != "" {
+		t, err := time.Parse(time.RFC3339, r.CreateTime)
+		if err != nil {
+			return stu, errors.New("createTime error")
+		}
+		stu.CreateTime = t.UnixNano()
+	}
+	stu.ModifiedTime = stu.CreateTime
+	if r.ModifiedTime != "" {
+		t, err := time.Parse(time.RFC3339, r.ModifiedTime)
+		if err != nil {
+			return stu, errors.New("modifiedTime error")
+		}
+		stu.ModifiedTime = t.UnixNano()
+	}
+	return stu, nil
+}
+
+// studentWriter encodes students one at a time in one of the file formats.
+type studentWriter interface {
+	write(stu store.Student) error
+	// close finishes the file; nothing may be written after it.
+	close() error
+}
+
+func newStudentWriter(format pb.FileFormat, w io.Writer) (studentWriter, error) {
+	switch format {
+	case pb.FileFormat_CSV:
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(transferColumns)
+	case pb.FileFormat_JSON:
+		return &jsonWriter{w: w}, nil
+	case pb.FileFormat_NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown file format %d", format)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) write(stu store.Student) error {
+	r := toTransferRecord(stu)
+	return c.w.Write([]string{r.Id, r.Name, strconv.Itoa(int(r.Age)), r.Profession, r.CreateTime, r.ModifiedTime})
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes a single array with one student per line.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) write(stu store.Student) error {
+	b, err := json.Marshal(toTransferRecord(stu))
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+	_, err = io.WriteString(j.w, sep+string(b))
+	return err
+}
+
+func (j *jsonWriter) close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) write(stu store.Student) error {
+	return n.enc.Encode(toTransferRecord(stu))
+}
+
+func (n *ndjsonWriter) close() error {
+	return nil
+}
+
+// importRow is one record of an imported file, or why it could not be read.
+type importRow struct {
+	student store.Student
+	err     error
+}
+
+// readStudents decodes every record of r. A record that cannot be converted
+// becomes a row with an error; a file that cannot be parsed any further
+// fails as a whole. More than max records are an error too.
+func readStudents(format pb.FileFormat, r io.Reader, max int) ([]importRow, error) {
+	var rows []importRow
+	add := func(rec transferRecord, err error) error {
+		if len(rows) == max {
+			return fmt.Errorf("at most %d students per import", max)
+		}
+		row := importRow{err: err}
+		if err == nil {
+			row.student, row.err = rec.student()
+		}
+		rows = append(rows, row)
+		return nil
+	}
+	var err error
+	switch format {
+	case pb.FileFormat_CSV:
+		err = readCSV(r, add)
+	case pb.FileFormat_JSON:
+		err = readJSON(r, add)
+	case pb.FileFormat_NDJSON:
+		err = readNDJSON(r, add)
+	default:
+		err = fmt.Errorf("unknown file format %d", format)
+	}
+	return rows, err
+}
+
+// readCSV reads a header row naming the columns, in any order, followed by
+// one student per row. A leading BOM is skipped.
+func readCSV(r io.Reader, add func(transferRecord, error) error) error {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(len(utf8BOM)); bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	cr := csv.NewReader(br)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"name", "age", "profession"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if perr, ok := err.(*csv.ParseError); ok && perr.Err == csv.ErrFieldCount {
+			if err := add(transferRecord{}, fmt.Errorf("line %d: wrong number of fields", perr.Line)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rec := transferRecord{
+			Id:           field(record, "id"),
+			Name:         field(record, "name"),
+			Profession:   field(record, "profession"),
+			CreateTime:   field(record, "createTime"),
+			ModifiedTime: field(record, "modifiedTime"),
+		}
+		age, err := strconv.ParseInt(field(record, "age"), 10, 32)
+		if err != nil {
+			err = errors.New("age error")
+		}
+		rec.Age = int32(age)
+		if err := add(rec, err); err != nil {
+			return err
+		}
+	}
+}
+
+// readJSON reads an array of student objects.
+func readJSON(r io.Reader, add func(transferRecord, error) error) error {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return errors.New("expected a JSON array of students")
+	}
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		if err := add(decodeRecord(raw)); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// readNDJSON reads one student object per line, skipping blank lines.
+func readNDJSON(r io.Reader, add func(transferRecord, error) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := add(decodeRecord(line)); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func decodeRecord(b []byte) (transferRecord, error) {
+	var rec transferRecord
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return rec, fmt.Errorf("invalid student: %v", err)
+	}
+	return rec, nil
+}
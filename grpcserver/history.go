@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// Metadata keys identifying who made a change. The HTTP gateway fills them in
+// from the X-Actor and X-Request-Id headers.
+const (
+	actorKey     = "x-actor"
+	requestIdKey = "x-request-id"
+)
+
+func firstMetadata(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// auditInfo returns the actor and request id of ctx. Requests without an id
+// are given a fresh one so that entries of one call can still be grouped.
+func auditInfo(ctx context.Context) (string, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	actor := firstMetadata(md, actorKey)
+	if actor == "" {
+		actor = "unknown"
+	}
+	requestId := firstMetadata(md, requestIdKey)
+	if requestId == "" {
+		requestId = getUUID()
+	}
+	return actor, requestId
+}
+
+// record appends a history entry for a change that has already been stored.
+// A failure only gets logged; the change itself cannot be undone any more.
+func (s *Server) record(ctx context.Context, action string, before, after *store.Student) {
+	s.recordEntry(ctx, store.HistoryEntry{Action: action, Before: before, After: after})
+}
+
+// recordEntry is record for an entry with more than the action and the
+// images. Who made the change and when is filled in unless e has it.
+func (s *Server) recordEntry(ctx context.Context, e store.HistoryEntry) {
+	if e.RequestId == "" {
+		e.Actor, e.RequestId = auditInfo(ctx)
+	}
+	if e.Time == 0 {
+		e.Time = time.Now().Unix()
+	}
+	if e.Before != nil {
+		e.StudentId = e.Before.Id
+	} else {
+		e.StudentId = e.After.Id
+	}
+	if err := s.history.Append(e); err != nil {
+		log.Printf("record %v of %v failed: %v", e.Action, e.StudentId, err)
+	}
+}
+
+// create stores a new student, handing out its student number unless it has
+// one, and records it under action. Whether registered one by one, in bulk or
+// imported, the student is flagged or refused as a duplicate of one already
+// there according to s.duplicates. stu is updated to the stored record.
+func (s *Server) create(ctx context.Context, action string, stu *store.Student) error {
+	unlock := s.registerLocks.lock(duplicateKey(*stu))
+	defer unlock()
+	if err := s.checkDuplicate(stu, nil); err != nil {
+		return err
+	}
+	s.professionMux.RLock()
+	defer s.professionMux.RUnlock()
+	unlock, err := s.checkCapacity(nil, stu)
+	if err != nil {
+		return storeError(err)
+	}
+	defer unlock()
+	if stu.Number == "" {
+		number, err := s.studentNumber(*stu)
+		if err != nil {
+			return storeError(err)
+		}
+		stu.Number = number
+	}
+	if err := s.store.Create(*stu); err != nil {
+		return storeError(err)
+	}
+	stu.Version = 1
+	s.record(ctx, action, nil, stu)
+	return nil
+}
+
+// update writes after over before, which must be the version read from the
+// store, records the change under action and returns the stored record.
+func (s *Server) update(ctx context.Context, action string, before, after store.Student) (store.Student, error) {
+	s.professionMux.RLock()
+	defer s.professionMux.RUnlock()
+	unlock, err := s.checkCapacity(&before, &after)
+	if err != nil {
+		return before, storeError(err)
+	}
+	defer unlock()
+	if err := s.store.Update(after); err != nil {
+		return before, storeError(err)
+	}
+	after.Version++
+	s.record(ctx, action, &before, &after)
+	return after, nil
+}
+
+// purge removes a student for good, along with its enrollments, and records
+// it under action.
+func (s *Server) purge(ctx context.Context, action string, stu store.Student) error {
+	if err := s.store.Delete(stu.Id, stu.Version); err != nil {
+		return err
+	}
+	s.record(ctx, action, &stu, nil)
+	s.dropEnrollments(stu.Id)
+	return nil
+}
+
+func (s *Server) toHistoryEntry(e store.HistoryEntry) *pb.HistoryEntry {
+	entry := &pb.HistoryEntry{
+		Action:     e.Action,
+		Actor:      e.Actor,
+		RequestId:  e.RequestId,
+		Time:       e.Time,
+		MergedWith: e.MergedWith,
+	}
+	if e.Before != nil {
+		entry.Before = s.toStudentInfo(*e.Before)
+	}
+	if e.After != nil {
+		entry.After = s.toStudentInfo(*e.After)
+	}
+	return entry
+}
+
+// GetStudentHistory returns every recorded change of a student, including
+// students that have since been purged.
+func (s *Server) GetStudentHistory(_ context.Context, req *pb.HistoryRequest) (*pb.StudentHistory, error) {
+	entries, err := s.history.List(req.GetId())
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentHistory{}, storeError(err)
+	}
+	if len(entries) == 0 {
+		return &pb.StudentHistory{}, status.Errorf(codes.NotFound, "no history for student %v", req.GetId())
+	}
+	reply := &pb.StudentHistory{}
+	for _, e := range entries {
+		reply.Entries = append(reply.Entries, s.toHistoryEntry(e))
+	}
+	log.Printf("query history of %v success", req.GetId())
+	return reply, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+const (
+	// idempotencyKey is the metadata key of the client's idempotency key.
+	// The HTTP gateway fills it in from the Idempotency-Key header.
+	idempotencyKey           = "idempotency-key"
+	defaultIdempotencyWindow = 24 * time.Hour
+)
+
+// idempotentReplies are the methods that take an idempotency key, with a
+// constructor of their reply to decode a kept one into.
+var idempotentReplies = map[string]func() proto.Message{
+	serviceMethodPrefix + "Register":        func() proto.Message { return &pb.RegisterReply{} },
+	serviceMethodPrefix + "AlterProfession": func() proto.Message { return &pb.Result{} },
+	serviceMethodPrefix + "Delete":          func() proto.Message { return &pb.Result{} },
+	serviceMethodPrefix + "MergeStudents":   func() proto.Message { return &pb.StudentInfo{} },
+	serviceMethodPrefix + "Enroll":          func() proto.Message { return &pb.Enrollment{} },
+	serviceMethodPrefix + "Drop":            func() proto.Message { return &pb.Result{} },
+}
+
+// keyLocks serializes the work on one key, such as the requests carrying the
+// same idempotency key.
+type keyLocks struct {
+	mux   sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func (k *keyLocks) lock(key string) (unlock func()) {
+	k.mux.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	l := k.locks[key]
+	if l == nil {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.waiters++
+	k.mux.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mux.Lock()
+		if l.waiters--; l.waiters == 0 {
+			delete(k.locks, key)
+		}
+		k.mux.Unlock()
+	}
+}
+
+// lockAll locks every key, in order so that two callers cannot wait for
+// each other, and returns a function that unlocks them all.
+func (k *keyLocks) lockAll(keys []string) (unlock func()) {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	var unlocks []func()
+	for i, key := range sorted {
+		if i == 0 || key != sorted[i-1] {
+			unlocks = append(unlocks, k.lock(key))
+		}
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+func requestDigest(req proto.Message) ([]byte, error) {
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	return sum[:], nil
+}
+
+// idempotencyInterceptor answers a retry of a successful mutation, one that
+// carries the same idempotency key, with the reply kept from the first call
+// instead of making the change again. A retry that overtakes the call it
+// repeats waits for it. Failed calls are not kept, so retrying them tries
+// again.
+func (s *Server) idempotencyInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	newReply, ok := idempotentReplies[info.FullMethod]
+	md, _ := metadata.FromIncomingContext(ctx)
+	key := firstMetadata(md, idempotencyKey)
+	if !ok || key == "" || s.replayWindow <= 0 {
+		return handler(ctx, req)
+	}
+	method := strings.TrimPrefix(info.FullMethod, serviceMethodPrefix)
+	unlock := s.keyLocks.lock(key)
+	defer unlock()
+
+	digest, err := requestDigest(req.(proto.Message))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	kept, err := s.idempotency.Get(key)
+	if err != nil && err != store.ErrNoIdempotentResult {
+		return nil, storeError(err)
+	}
+	if err == nil && kept.Time >= time.Now().Add(-s.replayWindow).Unix() {
+		if kept.Method != method || !bytes.Equal(kept.Request, digest) {
+			return nil, status.Errorf(codes.FailedPrecondition, "idempotency key %v was used for a different request", key)
+		}
+		reply := newReply()
+		if err := proto.Unmarshal(kept.Response, reply); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		log.Printf("replay %v for idempotency key %v", method, key)
+		return reply, nil
+	}
+
+	reply, err := handler(ctx, req)
+	if err != nil {
+		return reply, err
+	}
+	response, err := proto.Marshal(reply.(proto.Message))
+	if err == nil {
+		err = s.idempotency.Put(store.IdempotentResult{
+			Key:      key,
+			Method:   method,
+			Request:  digest,
+			Response: response,
+			Time:     time.Now().Unix(),
+		})
+	}
+	if err != nil {
+		// The change is made either way; only a retry would repeat it.
+		log.Printf("keep reply for idempotency key %v failed: %v", key, err)
+	}
+	return reply, nil
+}
+
+// runIdempotencyExpiry drops the replies that have been kept for longer than
+// the idempotency window, on the leader only in a cluster.
+func (s *Server) runIdempotencyExpiry(interval time.Duration) {
+	for range time.Tick(interval) {
+		if !s.runsJobs() {
+			continue
+		}
+		n, err := s.idempotency.Expire(time.Now().Add(-s.replayWindow).Unix())
+		if err != nil {
+			log.Printf("expire idempotency keys failed: %v", err)
+		}
+		if n > 0 {
+			log.Printf("expired %d idempotency keys", n)
+		}
+	}
+}
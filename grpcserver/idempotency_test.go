@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// withKey is a context sending the idempotency key like the gateway does.
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), idempotencyKey, key)
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	s, c := startServer(t)
+	req := &pb.RegisterRequest{Name: "alice", Age: 20, Profession: "SE"}
+	first, err := c.Register(withKey("k1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry, err := c.Register(withKey("k1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retry.Id != first.Id || retry.Number != first.Number {
+		t.Errorf("retry registered %v %v, want the reply %v %v replayed", retry.Id, retry.Number, first.Id, first.Number)
+	}
+	other, err := c.Register(withKey("k2"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Id == first.Id {
+		t.Error("another key was answered with the reply of k1")
+	}
+	if n := storedStudents(t, s); n != 2 {
+		t.Errorf("%d students stored, want 2", n)
+	}
+
+	deleteReq := &pb.StudentInfo{Id: first.Id}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Delete(withKey("k3"), deleteReq); err != nil {
+			t.Fatalf("delete %d: %v", i, err)
+		}
+	}
+	_, err = c.Delete(withKey("k4"), deleteReq)
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestIdempotencyKeyReused(t *testing.T) {
+	_, c := startServer(t)
+	first := mustRegister(t, c, "alice", 20, "SE")
+	if _, err := c.Register(withKey("k1"), &pb.RegisterRequest{Name: "bob", Age: 20, Profession: "SE"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"other request", func(ctx context.Context) error {
+			_, err := c.Register(ctx, &pb.RegisterRequest{Name: "carol", Age: 20, Profession: "SE"})
+			return err
+		}},
+		{"other method", func(ctx context.Context) error {
+			_, err := c.Delete(ctx, &pb.StudentInfo{Id: first.Id})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.call(withKey("k1")), codes.FailedPrecondition)
+		})
+	}
+	if _, err := c.Query(context.Background(), &pb.QueryStudentRequest{Id: first.Id}); err != nil {
+		t.Errorf("a rejected reuse of a key deleted %v: %v", first.Id, err)
+	}
+}
+
+func TestIdempotencyFailedCallsNotKept(t *testing.T) {
+	s, c := startServer(t)
+	req := &pb.RegisterRequest{Name: "alice", Age: 20, Profession: "SE"}
+	setCapacity(t, s, "SE", 1)
+	mustRegister(t, c, "bob", 20, "SE")
+	_, err := c.Register(withKey("k1"), req)
+	wantCode(t, err, codes.FailedPrecondition)
+	setCapacity(t, s, "SE", 2)
+	if _, err := c.Register(withKey("k1"), req); err != nil {
+		t.Errorf("retry after a failure: %v", err)
+	}
+}
+
+func TestIdempotencyWindow(t *testing.T) {
+	s, c := startServer(t)
+	req := &pb.RegisterRequest{Name: "alice", Age: 20, Profession: "SE"}
+	first, err := c.Register(withKey("k1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := s.idempotency.Get("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept.Time = time.Now().Add(-s.replayWindow - time.Minute).Unix()
+	if err := s.idempotency.Put(kept); err != nil {
+		t.Fatal(err)
+	}
+	late, err := c.Register(withKey("k1"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if late.Id == first.Id {
+		t.Error("reply replayed after the idempotency window")
+	}
+	if n, err := s.idempotency.Expire(time.Now().Add(-s.replayWindow).Unix()); err != nil || n != 0 {
+		t.Errorf("Expire = %d, %v; the late call should have kept a new reply", n, err)
+	}
+	if _, err := s.idempotency.Get("k1"); err == store.ErrNoIdempotentResult {
+		t.Error("reply of the late call not kept")
+	}
+}
+
+// TestIdempotentConcurrentRetries sends retries that overtake the call they
+// repeat; only one of them may register.
+func TestIdempotentConcurrentRetries(t *testing.T) {
+	s, c := startServer(t)
+	req := &pb.RegisterRequest{Name: "alice", Age: 20, Profession: "SE"}
+	const retries = 8
+	ids := make(chan string, retries)
+	var wg sync.WaitGroup
+	for i := 0; i < retries; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := c.Register(withKey("k1"), req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids <- reply.Id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := make(map[string]bool)
+	for id := range ids {
+		seen[id] = true
+	}
+	if len(seen) != 1 || storedStudents(t, s) != 1 {
+		t.Errorf("retries got ids %v and stored %d students, want one", seen, storedStudents(t, s))
+	}
+}
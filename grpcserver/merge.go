@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// maxAliasHops bounds the chain of merges followed from a retired id.
+const maxAliasHops = 16
+
+func mergeable(path string) bool {
+	for _, field := range updatableFields {
+		if path == field {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedStudent combines survivor and retired. Name, age and profession come
+// from the survivor, or from the retired student if the rule or fromRetired
+// says so. The merged student counts as registered since the earlier of
+// both and is no longer a possible duplicate of the retired one.
+func mergedStudent(survivor, retired store.Student, rule pb.MergeRule, fromRetired []string) store.Student {
+	take := make(map[string]bool)
+	if rule == pb.MergeRule_NEWEST && retired.ModifiedTime > survivor.ModifiedTime {
+		for _, field := range updatableFields {
+			take[field] = true
+		}
+	}
+	for _, field := range fromRetired {
+		take[field] = true
+	}
+	merged := survivor
+	if take["name"] {
+		merged.Name = retired.Name
+	}
+	if take["age"] {
+		merged.Age = retired.Age
+	}
+	if take["profession"] {
+		merged.Profession = retired.Profession
+	}
+	if retired.CreateTime < merged.CreateTime {
+		merged.CreateTime = retired.CreateTime
+	}
+	if merged.DuplicateOf == retired.Id {
+		merged.DuplicateOf = ""
+	}
+	return merged
+}
+
+// MergeStudents folds a duplicate into the student that survives it. The
+// retired student is purged and its id resolves to the survivor from then
+// on; the history of both records the merge and the survivor takes over its
+// courses.
+func (s *Server) MergeStudents(ctx context.Context, req *pb.MergeStudentsRequest) (*pb.StudentInfo, error) {
+	var errs fieldErrors
+	if req.GetSurvivorId() == "" {
+		errs.add("survivorId", "is required")
+	}
+	switch req.GetRetiredId() {
+	case "":
+		errs.add("retiredId", "is required")
+	case req.GetSurvivorId():
+		errs.add("retiredId", "must differ from survivorId")
+	}
+	if _, ok := pb.MergeRule_name[int32(req.GetRule())]; !ok {
+		errs.add("rule", "unknown merge rule %d", req.GetRule())
+	}
+	fromRetired := req.GetFromRetired().GetPaths()
+	for _, path := range fromRetired {
+		if !mergeable(path) {
+			errs.add("fromRetired", "field %q cannot be merged", path)
+		}
+	}
+	if err := errs.err(); err != nil {
+		return &pb.StudentInfo{}, err
+	}
+
+	survivor, err := s.getLive(req.GetSurvivorId())
+	if err == nil {
+		err = checkVersion(survivor, req.GetSurvivorVersion())
+	}
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	retired, err := s.getLive(req.GetRetiredId())
+	if err == nil {
+		err = checkVersion(retired, req.GetRetiredVersion())
+	}
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	merged := mergedStudent(survivor, retired, req.GetRule(), fromRetired)
+	merged.ModifiedTime = time.Now().UnixNano()
+	merged, err = s.merge(ctx, survivor, retired, merged)
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	log.Printf("merge student %v into %v success", retired.Id, survivor.Id)
+	return s.toStudentInfo(merged), nil
+}
+
+// merge writes merged over survivor and purges retired, both as read from
+// the store, and returns the stored survivor. The aliases of the id and the
+// student number of retired are written before it is purged, so that they
+// resolve from the moment it is gone, even if the server stops right after;
+// lookups prefer a live student to an alias, so until then they change
+// nothing. If retired cannot be purged, the aliases are removed again.
+func (s *Server) merge(ctx context.Context, survivor, retired, merged store.Student) (store.Student, error) {
+	s.professionMux.RLock()
+	defer s.professionMux.RUnlock()
+	// Taking over the profession of the retired student frees the place it
+	// held, so only a move to a third profession needs room.
+	if merged.Profession != retired.Profession {
+		unlock, err := s.checkCapacity(&survivor, &merged)
+		if err != nil {
+			return survivor, storeError(err)
+		}
+		defer unlock()
+	}
+	now := time.Now()
+	if err := s.store.Update(merged); err != nil {
+		return survivor, storeError(err)
+	}
+	merged.Version++
+	actor, requestId := auditInfo(ctx)
+	entry := store.HistoryEntry{Action: "merge", Actor: actor, RequestId: requestId, Time: now.Unix()}
+	survivorEntry := entry
+	survivorEntry.Before, survivorEntry.After, survivorEntry.MergedWith = &survivor, &merged, retired.Id
+	s.recordEntry(ctx, survivorEntry)
+	retiredIds := []string{retired.Id}
+	if retired.Number != "" {
+		retiredIds = append(retiredIds, retired.Number)
+	}
+	var err error
+	for _, retiredId := range retiredIds {
+		if err = s.aliases.Put(store.Alias{RetiredId: retiredId, SurvivorId: survivor.Id, Time: now.Unix()}); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = s.store.Delete(retired.Id, retired.Version)
+	}
+	if err != nil {
+		// The retired student changed since it was read, through another
+		// member of a cluster, or an alias could not be written; give the
+		// survivor its fields back and the retired ids their own meaning.
+		for _, retiredId := range retiredIds {
+			if err := s.aliases.Delete(retiredId); err != nil {
+				log.Printf("remove alias %v of %v failed: %v", retiredId, survivor.Id, err)
+			}
+		}
+		undo := survivor
+		undo.Version = merged.Version
+		undo.ModifiedTime = time.Now().UnixNano()
+		if undoErr := s.store.Update(undo); undoErr != nil {
+			log.Printf("undo merge of %v into %v failed: %v", retired.Id, survivor.Id, undoErr)
+		} else {
+			undo.Version++
+			s.record(ctx, "mergeRollback", &merged, &undo)
+		}
+		return survivor, storeError(err)
+	}
+	retiredEntry := entry
+	retiredEntry.Before, retiredEntry.MergedWith = &retired, survivor.Id
+	s.recordEntry(ctx, retiredEntry)
+	s.moveEnrollments(retired.Id, survivor.Id)
+	return merged, nil
+}
+
+// getMerged returns the live student a retired id or student number was
+// merged into, following later merges of the survivor, or notFound if it was
+// never retired.
+func (s *Server) getMerged(id string, notFound error) (store.Student, error) {
+	survivor := id
+	for hops := 0; hops < maxAliasHops; hops++ {
+		a, err := s.aliases.Get(survivor)
+		if err == store.ErrNoAlias {
+			break
+		}
+		if err != nil {
+			return store.Student{}, storeError(err)
+		}
+		survivor = a.SurvivorId
+	}
+	if survivor == id {
+		return store.Student{}, notFound
+	}
+	return s.getLive(survivor)
+}
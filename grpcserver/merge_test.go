@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc/codes"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+func TestMergeResolvesRetiredIds(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	survivor := mustRegister(t, c, "alice", 20, "SE")
+	retired := mustRegister(t, c, "bob", 21, "SE")
+	if _, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: survivor.Id, RetiredId: retired.Id}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{retired.Id, retired.Number, survivor.Id, survivor.Number} {
+		got, err := c.Query(ctx, &pb.QueryStudentRequest{Id: id})
+		if err != nil {
+			t.Fatalf("Query(%v): %v", id, err)
+		}
+		if got.Id != survivor.Id {
+			t.Errorf("Query(%v) = %v, want the survivor %v", id, got.Id, survivor.Id)
+		}
+	}
+	_, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: survivor.Id, RetiredId: retired.Id})
+	wantCode(t, err, codes.NotFound)
+}
+
+// failingDeletes is a store that cannot delete students.
+type failingDeletes struct {
+	store.StudentStore
+}
+
+func (failingDeletes) Delete(string, int64) error {
+	return errors.New("disk full")
+}
+
+func TestMergeRollback(t *testing.T) {
+	s, c := startServerWith(t, failingDeletes{store.NewMemoryStore()})
+	ctx := context.Background()
+	survivor := mustRegister(t, c, "alice", 20, "SE")
+	retired := mustRegister(t, c, "bob", 21, "SE")
+	_, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{
+		SurvivorId: survivor.Id, RetiredId: retired.Id, Rule: pb.MergeRule_NEWEST})
+	if err == nil {
+		t.Fatal("merge succeeded without deleting the retired student")
+	}
+	for _, id := range []string{retired.Id, retired.Number} {
+		if a, err := s.aliases.Get(id); err != store.ErrNoAlias {
+			t.Errorf("alias of %v = %+v, %v after a failed merge", id, a, err)
+		}
+		got, err := c.Query(ctx, &pb.QueryStudentRequest{Id: id})
+		if err != nil {
+			t.Fatalf("Query(%v): %v", id, err)
+		}
+		if got.Id != retired.Id {
+			t.Errorf("Query(%v) = %v, want the retired student %v", id, got.Id, retired.Id)
+		}
+	}
+	got, err := c.Query(ctx, &pb.QueryStudentRequest{Id: survivor.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "alice" || got.Age != 20 {
+		t.Errorf("survivor after a failed merge = %v, %v, want alice, 20", got.Name, got.Age)
+	}
+}
+
+func TestMergeChain(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	a := mustRegister(t, c, "alice", 20, "SE")
+	b := mustRegister(t, c, "alicd", 20, "SE")
+	d := mustRegister(t, c, "alica", 20, "SE")
+	if _, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: b.Id, RetiredId: a.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: d.Id, RetiredId: b.Id}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{a.Id, a.Number, b.Id, b.Number} {
+		got, err := c.Query(ctx, &pb.QueryStudentRequest{Id: id})
+		if err != nil {
+			t.Fatalf("Query(%v): %v", id, err)
+		}
+		if got.Id != d.Id {
+			t.Errorf("Query(%v) = %v, want the last survivor %v", id, got.Id, d.Id)
+		}
+	}
+}
+
+func TestMergeRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        pb.MergeRule
+		fromRetired []string
+		want        pb.StudentInfo
+	}{
+		{"keep survivor", pb.MergeRule_KEEP_SURVIVOR, nil, pb.StudentInfo{Name: "alice", Age: 20, Profession: "SE"}},
+		{"newest", pb.MergeRule_NEWEST, nil, pb.StudentInfo{Name: "alicd", Age: 21, Profession: "CST"}},
+		{"from retired", pb.MergeRule_KEEP_SURVIVOR, []string{"age"}, pb.StudentInfo{Name: "alice", Age: 21, Profession: "SE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := startServer(t)
+			ctx := context.Background()
+			survivor := mustRegister(t, c, "alice", 20, "SE")
+			retired := mustRegister(t, c, "alicd", 21, "CST")
+			before, err := c.Query(ctx, &pb.QueryStudentRequest{Id: survivor.Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{
+				SurvivorId: survivor.Id, RetiredId: retired.Id, Rule: tt.rule,
+				FromRetired: &field_mask.FieldMask{Paths: tt.fromRetired}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Id != survivor.Id || got.Name != tt.want.Name || got.Age != tt.want.Age || got.Profession != tt.want.Profession {
+				t.Errorf("merged into %v %v, %v, %v, want %v %v, %v, %v", got.Id, got.Name, got.Age, got.Profession,
+					survivor.Id, tt.want.Name, tt.want.Age, tt.want.Profession)
+			}
+			if got.Version != before.Version+1 {
+				t.Errorf("merged version %d, want %d", got.Version, before.Version+1)
+			}
+		})
+	}
+}
+
+func TestMergeVersions(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	survivor := mustRegister(t, c, "alice", 20, "SE")
+	retired := mustRegister(t, c, "alicd", 20, "SE")
+	_, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: survivor.Id, RetiredId: retired.Id, RetiredVersion: 2})
+	wantCode(t, err, codes.Aborted)
+	_, err = c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: survivor.Id, RetiredId: retired.Id, SurvivorVersion: 2})
+	wantCode(t, err, codes.Aborted)
+	if _, err := c.Query(ctx, &pb.QueryStudentRequest{Id: retired.Id}); err != nil {
+		t.Errorf("aborted merge retired %v: %v", retired.Id, err)
+	}
+}
+
+func TestMergeMovesEnrollments(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	for _, code := range []string{"MATH", "ART"} {
+		if _, err := c.CreateCourse(ctx, &pb.Course{Code: code, Title: code, Credits: 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	survivor := mustRegister(t, c, "alice", 20, "SE")
+	retired := mustRegister(t, c, "alicd", 20, "SE")
+	for _, e := range []pb.EnrollmentRequest{
+		{CourseCode: "MATH", StudentId: survivor.Id},
+		{CourseCode: "MATH", StudentId: retired.Id},
+		{CourseCode: "ART", StudentId: retired.Id},
+	} {
+		if _, err := c.Enroll(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{SurvivorId: survivor.Id, RetiredId: retired.Id}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.ListEnrollments(ctx, &pb.ListEnrollmentsRequest{StudentId: survivor.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var courses []string
+	for _, e := range list.Enrollments {
+		courses = append(courses, e.CourseCode)
+	}
+	if !reflect.DeepEqual(courses, []string{"ART", "MATH"}) {
+		t.Errorf("survivor enrolled in %v, want [ART MATH]", courses)
+	}
+	for _, code := range []string{"MATH", "ART"} {
+		list, err := c.ListEnrollments(ctx, &pb.ListEnrollmentsRequest{CourseCode: code})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range list.Enrollments {
+			if e.StudentId != survivor.Id {
+				t.Errorf("course %v still lists %v", code, e.StudentId)
+			}
+		}
+	}
+}
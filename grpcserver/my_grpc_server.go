@@ -2,59 +2,195 @@ package main
 
 import (
 	"context"
-	"errors"
+	"flag"
 	"log"
 	"net"
-	"sort"
 	"sync"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"mygolangproject/cluster"
+	"mygolangproject/idgen"
 	pb "mygolangproject/proto"
+	"mygolangproject/store"
+	"mygolangproject/store/sqlstore"
 )
 
-const port = ":50052"
+const (
+	port            = ":50052"
+	eventBufferSize = 1024
+)
+
+var (
+	listen            = flag.String("listen", port, "address the gRPC server listens on")
+	dataDir           = flag.String("data", "", "directory for the write-ahead log and snapshots; empty keeps students in memory only")
+	snapshotInterval  = flag.Duration("snapshot-interval", time.Minute, "how often the write-ahead log is compacted into a snapshot")
+	sqlitePath        = flag.String("sqlite", "", "path of an embedded SQLite database file; takes precedence over -data")
+	retention         = flag.Duration("retention", 30*24*time.Hour, "how long deleted students stay restorable; 0 keeps them forever")
+	purgeInterval     = flag.Duration("purge-interval", time.Hour, "how often deleted students past the retention period are purged")
+	idempotencyWindow = flag.Duration("idempotency-window", defaultIdempotencyWindow, "how long replies to requests with an idempotency key are kept for retries; 0 ignores the keys")
+	idFormat          = flag.String("id-format", "uuid7", "format of new student ids: uuid7 or ulid, which sort by creation time, or uuid4")
+)
 
 type Server struct {
 	pb.UnimplementedServiceServer
+	store       *store.WatchableStore
+	history     store.HistoryStore
+	professions store.ProfessionStore
+	// Writes of students read lock professionMux, so that changes to the
+	// catalogue, which lock it, see no write half done: a deleted profession
+	// is checked to be unused and capacities are checked against settled
+	// counts. professionLocks hands out the places of one profession with a
+	// capacity at a time.
+	professionMux   sync.RWMutex
+	professionLocks keyLocks
+	idempotency     store.IdempotencyStore
+	aliases         store.AliasStore
+	sequences       store.SequenceStore
+	ids             idgen.Generator
+	courses         store.CourseStore
+	enrollments     store.EnrollmentStore
+	// courseMux makes capacity checks and the enrollments they admit, as
+	// well as the check that a deleted course is empty, atomic.
+	courseMux sync.Mutex
+	// replayWindow is how long a reply is replayed to retries.
+	replayWindow time.Duration
+	keyLocks     keyLocks
+	// duplicates is what creating a student does when it matches an
+	// existing one; names match within nameDistance letters. registerLocks
+	// keeps two creations of the same student from missing each other.
+	duplicates    duplicateMode
+	nameDistance  int
+	registerLocks keyLocks
+	// epoch identifies this run of the server; revisions restart with it.
+	epoch string
+	// clustered is set on members of a cluster, which replicas refuse to
+	// follow.
+	clustered bool
+	// leading reports whether this member of a cluster is its leader; it is
+	// nil on a server of its own.
+	leading func() bool
 }
 
-type student struct {
-	id           string //唯一
-	name         string //仅支持英文，非空
-	age          int32  //非空，范围【10，100】
-	profession   string //枚举：计算机科学与技术/软件工程
-	createTime   int64  //创建时间
-	modifiedTime int64  //修改时间
-}
-type safeStudentInfo struct {
-	studentInfo map[string]student
-	mux         sync.RWMutex
+// NewServer returns a Server that keeps its students in s, records every
+// change to them in h and checks their professions against p.
+func NewServer(st stores) *Server {
+	return &Server{
+		store:        store.NewWatchableStore(st.students, eventBufferSize),
+		history:      st.history,
+		professions:  st.professions,
+		idempotency:  st.idempotency,
+		aliases:      st.aliases,
+		sequences:    st.sequences,
+		ids:          &idgen.UUIDv7{},
+		courses:      st.courses,
+		enrollments:  st.enrollments,
+		replayWindow: defaultIdempotencyWindow,
+		duplicates:   duplicatesFlag,
+		nameDistance: 1,
+		epoch:        getUUID(),
+	}
 }
 
-var allStudentInfo = safeStudentInfo{studentInfo: make(map[string]student)}
-
 func getUUID() string {
 	return uuid.NewV4().String()
 }
 
-type studentList []student
+func (s *Server) toStudentInfo(stu store.Student) *pb.StudentInfo {
+	return &pb.StudentInfo{
+		Id:                stu.Id,
+		Name:              stu.Name,
+		Age:               stu.Age,
+		Profession:        stu.Profession,
+		ProfessionName:    s.professionName(stu.Profession),
+		CreateTime:        stu.CreateTime / int64(time.Second),
+		ModifiedTime:      stu.ModifiedTime / int64(time.Second),
+		Version:           stu.Version,
+		DeletedTime:       stu.DeletedTime,
+		CreateTimeNanos:   stu.CreateTime,
+		ModifiedTimeNanos: stu.ModifiedTime,
+		DuplicateOf:       stu.DuplicateOf,
+		Number:            stu.Number,
+	}
+}
+
+// getLive returns a student that has not been soft deleted.
+func (s *Server) getLive(id string) (store.Student, error) {
+	stu, err := s.store.Get(id)
+	if err == nil && stu.DeletedTime != 0 {
+		return store.Student{}, storeError(store.ErrNotFound)
+	}
+	return stu, storeError(err)
+}
+
+// findStudent returns the live student with the given id or student number,
+// or the student it was merged into.
+func (s *Server) findStudent(id string) (store.Student, error) {
+	stu, err := s.getLive(id)
+	if status.Code(err) == codes.NotFound && isStudentNumber(id) {
+		stu, err = s.getByNumber(id)
+	}
+	if status.Code(err) == codes.NotFound {
+		stu, err = s.getMerged(id, err)
+	}
+	return stu, err
+}
 
-func (stu studentList) Swap(i, j int)      { stu[i], stu[j] = stu[j], stu[i] }
-func (stu studentList) Len() int           { return len(stu) }
-func (stu studentList) Less(i, j int) bool { return stu[i].createTime > stu[j].createTime }
+// checkVersion fails with ABORTED when the caller expects a version other
+// than the stored one. An expected version of 0 skips the check.
+func checkVersion(stu store.Student, expected int64) error {
+	if expected != 0 && expected != stu.Version {
+		return status.Errorf(codes.Aborted, "student %v is at version %d, not %d", stu.Id, stu.Version, expected)
+	}
+	return nil
+}
+
+// statusCodes are the status codes of the errors the stores report about
+// the request rather than about themselves.
+var statusCodes = map[error]codes.Code{
+	store.ErrNotFound:                codes.NotFound,
+	store.ErrAlreadyExists:           codes.AlreadyExists,
+	store.ErrVersionMismatch:         codes.Aborted,
+	store.ErrProfessionNotFound:      codes.NotFound,
+	store.ErrProfessionAlreadyExists: codes.AlreadyExists,
+	store.ErrSequenceConflict:        codes.Unavailable,
+	store.ErrCourseNotFound:          codes.NotFound,
+	store.ErrCourseAlreadyExists:     codes.AlreadyExists,
+	store.ErrAlreadyEnrolled:         codes.AlreadyExists,
+	store.ErrNotEnrolled:             codes.NotFound,
+	context.Canceled:                 codes.Canceled,
+	context.DeadlineExceeded:         codes.DeadlineExceeded,
+}
 
-// A function to turn a map into a PairList, then sort and return it.
-func sortByCreateTime(m map[string]student) studentList {
-	p := make(studentList, len(m))
-	i := 0
-	for _, v := range m {
-		p[i] = v
-		i++
+// storeError converts an error of a store into a status. Statuses, such as
+// the UNAVAILABLE of a cluster without a leader, pass unchanged; any other
+// failure is INTERNAL.
+func storeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if code, ok := statusCodes[err]; ok {
+		return status.Error(code, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (s *Server) studentFromRequest(info *pb.RegisterRequest) store.Student {
+	now := time.Now().UnixNano()
+	return store.Student{
+		Id:           s.ids.New(),
+		Name:         info.GetName(),
+		Age:          info.GetAge(),
+		Profession:   info.GetProfession(),
+		CreateTime:   now,
+		ModifiedTime: now,
 	}
-	sort.Sort(p)
-	return p
 }
 
 // SayHello implements helloworld.GreeterServer
@@ -64,95 +200,320 @@ func (s *Server) SayHello(_ context.Context, in *pb.HelloRequest) (*pb.HelloRepl
 }
 
 //Register implements helloworld.GreeterServer
-func (s *Server) Register(_ context.Context, info *pb.RegisterRequest) (*pb.RegisterReply, error) {
-
-	newStudent := student{
-		id:           getUUID(),
-		name:         info.GetName(),
-		age:          info.GetAge(),
-		profession:   info.GetProfession(),
-		createTime:   time.Now().Unix(),
-		modifiedTime: time.Now().Unix(),
-	}
-	allStudentInfo.mux.Lock()
-	allStudentInfo.studentInfo[newStudent.id] = newStudent
-	defer allStudentInfo.mux.Unlock()
-	log.Printf("register %v success", newStudent.id)
-	return &pb.RegisterReply{Id: newStudent.id}, nil
-}
-
-func (s *Server) Query(_ context.Context, studentId *pb.StudentInfo) (*pb.StudentInfo, error) {
-	allStudentInfo.mux.Lock()
-	studentInfo, ok := allStudentInfo.studentInfo[studentId.Id]
-	defer allStudentInfo.mux.Unlock()
-	if !ok {
-		log.Print("student is not exist")
-		return &pb.StudentInfo{}, errors.New("student is not exist")
+func (s *Server) Register(ctx context.Context, info *pb.RegisterRequest) (*pb.RegisterReply, error) {
+	newStudent := s.studentFromRequest(info)
+	if err := s.checkStudent(&newStudent, ""); err != nil {
+		log.Printf("register failed: %v", err)
+		return &pb.RegisterReply{}, err
+	}
+	if err := s.create(ctx, "register", &newStudent); err != nil {
+		log.Printf("register %v failed: %v", newStudent.Id, err)
+		return &pb.RegisterReply{}, err
+	}
+	if newStudent.DuplicateOf != "" {
+		log.Printf("register %v flagged as a possible duplicate of %v", newStudent.Id, newStudent.DuplicateOf)
+	}
+	log.Printf("register %v as %v success", newStudent.Id, newStudent.Number)
+	return &pb.RegisterReply{Id: newStudent.Id, Number: newStudent.Number}, nil
+}
+
+func (s *Server) Query(_ context.Context, studentId *pb.QueryStudentRequest) (*pb.StudentInfo, error) {
+	if studentId.AsOf < 0 {
+		return &pb.StudentInfo{}, invalidField("asOf", "must not be negative")
+	}
+	var studentInfo store.Student
+	var err error
+	if studentId.AsOf != 0 {
+		studentInfo, err = s.getAsOf(studentId.Id, studentId.AsOf)
+	} else {
+		studentInfo, err = s.findStudent(studentId.Id)
+	}
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
 	}
 	log.Printf("find student %v", studentId.Id)
-	return &pb.StudentInfo{
-		Id:         studentInfo.id,
-		Name:       studentInfo.name,
-		Age:        studentInfo.age,
-		Profession: studentInfo.profession,
-	}, nil
-}
-
-func (s *Server) AlterProfession(_ context.Context, alterInfo *pb.StudentInfo) (*pb.Result, error) {
-	allStudentInfo.mux.Lock()
-	studentInfo, ok := allStudentInfo.studentInfo[alterInfo.Id]
-	defer allStudentInfo.mux.Unlock()
-	if !ok {
-		log.Print("student is not exist")
-		return &pb.Result{Res: false}, errors.New("student is nor exist")
-	}
-	studentInfo.profession = alterInfo.Profession
-	studentInfo.modifiedTime = time.Now().Unix()
-	allStudentInfo.studentInfo[alterInfo.Id] = studentInfo
+	return s.toStudentInfo(studentInfo), nil
+}
+
+func (s *Server) AlterProfession(ctx context.Context, alterInfo *pb.StudentInfo) (*pb.Result, error) {
+	studentInfo, err := s.getLive(alterInfo.Id)
+	if err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
+	if err := checkVersion(studentInfo, alterInfo.Version); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
+	var errs fieldErrors
+	profession, err := s.checkProfession(&errs, alterInfo.Profession, studentInfo.Profession)
+	if err != nil {
+		err = storeError(err)
+	} else {
+		err = errs.err()
+	}
+	if err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
+	altered := studentInfo
+	altered.Profession = profession
+	altered.ModifiedTime = time.Now().UnixNano()
+	if _, err := s.update(ctx, "alterProfession", studentInfo, altered); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
 	log.Printf("Alter student %v profession success", alterInfo.Id)
 	return &pb.Result{Res: true}, nil
 }
 
-func (s *Server) Delete(_ context.Context, studentId *pb.StudentInfo) (*pb.Result, error) {
-	allStudentInfo.mux.Lock()
-	_, ok := allStudentInfo.studentInfo[studentId.Id]
-	defer allStudentInfo.mux.Unlock()
-	if !ok {
-		log.Print("student is not exist")
-		return &pb.Result{Res: false}, errors.New("student is nor exist")
+// Delete soft deletes a student. It stays restorable until the purge job
+// removes it once the retention period has passed.
+func (s *Server) Delete(ctx context.Context, studentId *pb.StudentInfo) (*pb.Result, error) {
+	studentInfo, err := s.getLive(studentId.Id)
+	if err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
+	if err := checkVersion(studentInfo, studentId.Version); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
 	}
-	delete(allStudentInfo.studentInfo, studentId.Id)
+	deleted := studentInfo
+	now := time.Now()
+	deleted.DeletedTime = now.Unix()
+	deleted.ModifiedTime = now.UnixNano()
+	if _, err := s.update(ctx, "delete", studentInfo, deleted); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, err
+	}
+	s.dropEnrollments(studentInfo.Id)
 	log.Printf("delete student %v success", studentId.Id)
 	return &pb.Result{Res: true}, nil
 }
 
-func (s *Server) QueryList(_ context.Context, _ *pb.QueryRequest) (*pb.StudentList, error) {
-	allStudentInfo.mux.Lock()
-	defer allStudentInfo.mux.Unlock()
-	list := sortByCreateTime(allStudentInfo.studentInfo)
-	studentList := &pb.StudentList{}
-	for _, studentInfo := range list {
-		studentInfo := &pb.StudentInfo{
-			Id:           studentInfo.id,
-			Name:         studentInfo.name,
-			Age:          studentInfo.age,
-			Profession:   studentInfo.profession,
-			CreateTime:   studentInfo.createTime,
-			ModifiedTime: studentInfo.modifiedTime,
-		}
-		studentList.StudentInfo = append(studentList.StudentInfo, studentInfo)
+func (s *Server) RestoreStudent(ctx context.Context, studentId *pb.StudentInfo) (*pb.StudentInfo, error) {
+	studentInfo, err := s.store.Get(studentId.Id)
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, storeError(err)
+	}
+	if studentInfo.DeletedTime == 0 {
+		return &pb.StudentInfo{}, status.Errorf(codes.FailedPrecondition, "student %v is not deleted", studentId.Id)
+	}
+	if err := checkVersion(studentInfo, studentId.Version); err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	restored := studentInfo
+	restored.DeletedTime = 0
+	restored.ModifiedTime = time.Now().UnixNano()
+	restored, err = s.update(ctx, "restore", studentInfo, restored)
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	log.Printf("restore student %v success", studentId.Id)
+	return s.toStudentInfo(restored), nil
+}
+
+func (s *Server) QueryList(_ context.Context, req *pb.QueryRequest) (*pb.StudentList, error) {
+	s.normalizeFilter(req.GetFilter())
+	q, err := toListQuery(req)
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentList{}, err
+	}
+	var res store.ListResult
+	if req.AsOf != 0 {
+		res, err = s.listAsOf(q, req.AsOf)
+	} else {
+		res, err = s.store.List(q)
+	}
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentList{}, storeError(err)
+	}
+	studentList := &pb.StudentList{Total: int32(res.Total)}
+	for _, studentInfo := range res.Students {
+		studentList.StudentInfo = append(studentList.StudentInfo, s.toStudentInfo(studentInfo))
+	}
+	if res.More {
+		studentList.NextPageToken = encodePageToken(q, req.AsOf, res.Students[len(res.Students)-1])
 	}
 	log.Print("query list success")
 	return studentList, nil
 }
 
+// stores are the backends a Server keeps its state in, and how to close
+// them.
+type stores struct {
+	students    store.StudentStore
+	history     store.HistoryStore
+	professions store.ProfessionStore
+	idempotency store.IdempotencyStore
+	aliases     store.AliasStore
+	sequences   store.SequenceStore
+	courses     store.CourseStore
+	enrollments store.EnrollmentStore
+	close       func() error
+}
+
+func openStore() (stores, error) {
+	if *sqlitePath != "" {
+		db, err := sqlstore.Open(*sqlitePath)
+		if err != nil {
+			return stores{}, err
+		}
+		return stores{db, db.History(), db.Professions(), db.Idempotency(), db.Aliases(), db.Sequences(), db.Courses(), db.Enrollments(), db.Close}, nil
+	}
+	if *dataDir == "" {
+		return memoryStores(store.NewMemoryStore()), nil
+	}
+	d, err := store.OpenDurableStore(*dataDir, store.DurableOptions{SnapshotInterval: *snapshotInterval})
+	if err != nil {
+		return stores{}, err
+	}
+	h, err := store.OpenFileHistory(*dataDir)
+	if err != nil {
+		d.Close()
+		return stores{}, err
+	}
+	p, err := store.OpenFileProfessions(*dataDir)
+	if err != nil {
+		h.Close()
+		d.Close()
+		return stores{}, err
+	}
+	i, err := store.OpenFileIdempotency(*dataDir)
+	if err != nil {
+		h.Close()
+		d.Close()
+		return stores{}, err
+	}
+	a, err := store.OpenFileAliases(*dataDir)
+	if err != nil {
+		i.Close()
+		h.Close()
+		d.Close()
+		return stores{}, err
+	}
+	q, err := store.OpenFileSequences(*dataDir)
+	if err != nil {
+		a.Close()
+		i.Close()
+		h.Close()
+		d.Close()
+		return stores{}, err
+	}
+	c, err := store.OpenFileCourses(*dataDir)
+	if err != nil {
+		a.Close()
+		i.Close()
+		h.Close()
+		d.Close()
+		return stores{}, err
+	}
+	e, err := store.OpenFileEnrollments(*dataDir)
+	if err != nil {
+		a.Close()
+		i.Close()
+		h.Close()
+		d.Close()
+		return stores{}, err
+	}
+	return stores{d, h, p, i, a, q, c, e, func() error {
+		e.Close()
+		a.Close()
+		i.Close()
+		h.Close()
+		return d.Close()
+	}}, nil
+}
+
+// memoryStores keeps everything but the students in memory, starting with the
+// default professions.
+func memoryStores(students store.StudentStore) stores {
+	return stores{
+		students:    students,
+		history:     store.NewMemoryHistory(),
+		professions: store.NewMemoryProfessions(store.DefaultProfessions),
+		idempotency: store.NewMemoryIdempotency(),
+		aliases:     store.NewMemoryAliases(),
+		sequences:   store.NewMemorySequences(nil),
+		courses:     store.NewMemoryCourses(nil),
+		enrollments: store.NewMemoryEnrollments(),
+		close:       func() error { return nil },
+	}
+}
+
 func main() {
-	lis, err := net.Listen("tcp", port)
+	flag.Parse()
+	var node *cluster.Node
+	var st stores
+	var rep *replica
+	var err error
+	if *replicaOf != "" {
+		rep, err = newReplica(*replicaOf)
+		if err == nil {
+			st = memoryStores(rep.students)
+			st.professions = rep.professions
+		}
+	} else if *raftAddr != "" {
+		node, err = openCluster()
+		if err == nil {
+			st = stores{node, node.History(), node.Professions(), node.Idempotency(), node.Aliases(), node.Sequences(), node.Courses(), node.Enrollments(), node.Shutdown}
+		}
+	} else {
+		st, err = openStore()
+	}
+	if err != nil {
+		log.Fatalf("failed to open store: %v", err)
+	}
+	defer st.close()
+
+	lis, err := net.Listen("tcp", *listen)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
-	pb.RegisterServiceServer(s, &Server{})
+	server := NewServer(st)
+	server.clustered = node != nil
+	if node != nil {
+		server.leading = node.IsLeader
+	}
+	server.replayWindow = *idempotencyWindow
+	if server.duplicates, err = parseDuplicateMode(*duplicates); err != nil {
+		log.Fatal(err)
+	}
+	if *duplicateDistance < 0 || *duplicateDistance > maxNameDistance {
+		log.Fatalf("-duplicate-distance must be between 0 and %d", maxNameDistance)
+	}
+	server.nameDistance = *duplicateDistance
+	if server.ids, err = idgen.New(*idFormat); err != nil {
+		log.Fatal(err)
+	}
+	var opts []grpc.ServerOption
+	if rep != nil {
+		go rep.run()
+		opts = append(opts, grpc.UnaryInterceptor(rep.unaryInterceptor), grpc.StreamInterceptor(rep.streamInterceptor))
+	} else {
+		opts = append(opts, grpc.UnaryInterceptor(server.idempotencyInterceptor))
+		if *retention > 0 {
+			go server.runPurger(*retention, *purgeInterval)
+		}
+		if *idempotencyWindow > 0 {
+			go server.runIdempotencyExpiry(*purgeInterval)
+		}
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterServiceServer(s, server)
+	if node != nil {
+		cluster.Register(s, node)
+		if *join != "" {
+			go joinCluster(node)
+		}
+	}
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// startServer serves a Server over an in-memory connection with the
+// interceptor main installs on a standalone server, and returns it with a
+// client. It is stopped when the test ends.
+func startServer(t *testing.T) (*Server, pb.ServiceClient) {
+	return startServerWith(t, store.NewMemoryStore())
+}
+
+// startServerWith is startServer keeping the students in st.
+func startServerWith(t *testing.T, st store.StudentStore) (*Server, pb.ServiceClient) {
+	server := NewServer(memoryStores(st))
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(grpc.UnaryInterceptor(server.idempotencyInterceptor))
+	pb.RegisterServiceServer(s, server)
+	go s.Serve(lis)
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		s.Stop()
+	})
+	return server, pb.NewServiceClient(conn)
+}
+
+func mustRegister(t *testing.T, c pb.ServiceClient, name string, age int32, profession string) *pb.RegisterReply {
+	t.Helper()
+	info, err := c.Register(context.Background(), &pb.RegisterRequest{Name: name, Age: age, Profession: profession})
+	if err != nil {
+		t.Fatalf("Register(%v): %v", name, err)
+	}
+	return info
+}
+
+// wantCode fails the test unless err carries code.
+func wantCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Fatalf("got %v (%v), want %v", got, err, code)
+	}
+}
+
+// storedStudents returns the number of students stored, soft-deleted ones
+// included.
+func storedStudents(t *testing.T, s *Server) int {
+	t.Helper()
+	res, err := s.store.List(store.ListQuery{Filter: store.Filter{Deleted: store.IncludeDeleted}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(res.Students)
+}
+
+// setCapacity limits a profession of the catalogue to capacity live students.
+func setCapacity(t *testing.T, s *Server, code string, capacity int32) {
+	t.Helper()
+	p, err := s.professions.Get(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Capacity = capacity
+	if err := s.professions.Update(p); err != nil {
+		t.Fatal(err)
+	}
+}
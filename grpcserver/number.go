@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"mygolangproject/store"
+)
+
+// isStudentNumber tells a student number, such as 2026-SE-0042, from an id.
+var isStudentNumber = regexp.MustCompile(`^[0-9]{4}-[A-Z][A-Z0-9]{0,9}-[0-9]{4,}$`).MatchString
+
+// studentNumber hands out the next number of the profession of stu in the
+// year it registered. Numbers keep the profession they were handed out
+// with when the student changes profession.
+func (s *Server) studentNumber(stu store.Student) (string, error) {
+	prefix := fmt.Sprintf("%04d-%s", time.Unix(0, stu.CreateTime).Year(), stu.Profession)
+	n, err := s.sequences.Next(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%04d", prefix, n), nil
+}
+
+// getByNumber returns the live student with the given student number.
+func (s *Server) getByNumber(number string) (store.Student, error) {
+	res, err := s.store.List(store.ListQuery{Filter: store.Filter{Number: number}, Limit: 1})
+	if err != nil {
+		return store.Student{}, storeError(err)
+	}
+	if len(res.Students) == 0 {
+		return store.Student{}, storeError(store.ErrNotFound)
+	}
+	return res.Students[0], nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+const maxPageSize = 1000
+
+var errBadPageToken = errors.New("invalid page token")
+
+// pageToken records the position of the last student of a page: its sort key
+// and id. Resuming from a key rather than an offset keeps pages stable while
+// other students are inserted or deleted.
+type pageToken struct {
+	Query uint64 `json:"q"` // fingerprint of the filter, sort order and asOf
+	Num   int64  `json:"n,omitempty"`
+	Str   string `json:"s,omitempty"`
+	Id    string `json:"id"`
+}
+
+func queryFingerprint(q store.ListQuery, asOf int64) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%+v|%d|%t|%d", q.Filter, q.SortBy, q.Ascending, asOf)
+	return h.Sum64()
+}
+
+func encodePageToken(q store.ListQuery, asOf int64, last store.Student) string {
+	tok := pageToken{Query: queryFingerprint(q, asOf), Id: last.Id}
+	switch q.SortBy {
+	case store.SortByModifiedTime:
+		tok.Num = last.ModifiedTime
+	case store.SortByName:
+		tok.Str = last.Name
+	case store.SortByAge:
+		tok.Num = int64(last.Age)
+	default:
+		tok.Num = last.CreateTime
+	}
+	b, _ := json.Marshal(tok)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageToken turns a token back into the cursor student for q.
+func decodePageToken(q store.ListQuery, asOf int64, s string) (*store.Student, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errBadPageToken
+	}
+	var tok pageToken
+	if err := json.Unmarshal(b, &tok); err != nil || tok.Id == "" {
+		return nil, errBadPageToken
+	}
+	if tok.Query != queryFingerprint(q, asOf) {
+		return nil, errors.New("page token does not match the filter, sort order or asOf")
+	}
+	after := &store.Student{Id: tok.Id}
+	switch q.SortBy {
+	case store.SortByModifiedTime:
+		after.ModifiedTime = tok.Num
+	case store.SortByName:
+		after.Name = tok.Str
+	case store.SortByAge:
+		after.Age = int32(tok.Num)
+	default:
+		after.CreateTime = tok.Num
+	}
+	return after, nil
+}
+
+// fromSeconds and toSeconds widen an inclusive range in unix seconds to the
+// nanoseconds students are stored with. Zero stays unbounded.
+func fromSeconds(sec int64) int64 {
+	return sec * int64(time.Second)
+}
+
+func toSeconds(sec int64) int64 {
+	if sec == 0 {
+		return 0
+	}
+	return sec*int64(time.Second) + int64(time.Second) - 1
+}
+
+func toListQuery(req *pb.QueryRequest) (store.ListQuery, error) {
+	f := req.GetFilter()
+	q := store.ListQuery{
+		Filter: store.Filter{
+			Profession:   f.GetProfession(),
+			MinAge:       f.GetMinAge(),
+			MaxAge:       f.GetMaxAge(),
+			NamePrefix:   f.GetNamePrefix(),
+			CreatedFrom:  fromSeconds(f.GetCreatedFrom()),
+			CreatedTo:    toSeconds(f.GetCreatedTo()),
+			ModifiedFrom: fromSeconds(f.GetModifiedFrom()),
+			ModifiedTo:   toSeconds(f.GetModifiedTo()),
+			Deleted:      store.DeletedFilter(f.GetDeleted()),
+			Flagged:      f.GetFlagged(),
+			Number:       f.GetNumber(),
+		},
+		SortBy:    store.SortKey(req.GetSortBy()),
+		Ascending: req.GetAscending(),
+		Limit:     int(req.GetPageSize()),
+	}
+	var errs fieldErrors
+	if req.GetPageSize() < 0 || req.GetPageSize() > maxPageSize {
+		errs.add("pageSize", "must be between 0 and %d", maxPageSize)
+	}
+	if _, ok := pb.SortKey_name[int32(req.GetSortBy())]; !ok {
+		errs.add("sortBy", "unknown sort key %d", req.GetSortBy())
+	}
+	if req.GetAsOf() < 0 {
+		errs.add("asOf", "must not be negative")
+	}
+	if req.GetPageToken() != "" {
+		after, err := decodePageToken(q, req.GetAsOf(), req.GetPageToken())
+		if err != nil {
+			errs.add("pageToken", "%v", err)
+		}
+		q.After = after
+	}
+	return q, errs.err()
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+// professionCode keeps codes short enough to appear in student numbers.
+var professionCode = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,9}$`).MatchString
+
+// displayLanguage is the language of the profession names students and
+// exports show.
+const displayLanguage = "zh"
+
+func toProfession(p *pb.Profession) store.Profession {
+	return store.Profession{Code: p.GetCode(), Names: p.GetNames(), Active: p.GetActive(), Capacity: p.GetCapacity()}
+}
+
+func (s *Server) toProfessionInfo(p store.Profession) (*pb.Profession, error) {
+	enrolled, err := s.countStudents(p.Code, store.ExcludeDeleted)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.Profession{Code: p.Code, Names: p.Names, Active: p.Active, Capacity: p.Capacity, Enrolled: int32(enrolled)}, nil
+}
+
+// professionName returns the name a profession is shown under: its Chinese
+// name, which is what students were registered under before the catalogue,
+// or the code if the catalogue has none.
+func (s *Server) professionName(code string) string {
+	if p, err := s.professions.Get(code); err == nil && p.Names[displayLanguage] != "" {
+		return p.Names[displayLanguage]
+	}
+	return code
+}
+
+func (s *Server) countStudents(code string, deleted store.DeletedFilter) (int, error) {
+	res, err := s.store.List(store.ListQuery{Filter: store.Filter{Profession: code, Deleted: deleted}, Limit: 1})
+	return res.Total, err
+}
+
+// resolveProfession finds a profession by its code or by any of its names.
+func (s *Server) resolveProfession(profession string) (store.Profession, error) {
+	p, err := s.professions.Get(profession)
+	if err != store.ErrProfessionNotFound {
+		return p, err
+	}
+	list, err := s.professions.List()
+	if err != nil {
+		return store.Profession{}, err
+	}
+	for _, p := range list {
+		for _, name := range p.Names {
+			if name == profession {
+				return p, nil
+			}
+		}
+	}
+	return store.Profession{}, store.ErrProfessionNotFound
+}
+
+// checkProfession returns the code of the profession, given as a code or a
+// name, that a student is about to be stored with. It must be active, unless
+// it is kept, the code of the profession the student already has. What is
+// wrong with it is added to errs; the error is left for failures of the
+// catalogue itself.
+func (s *Server) checkProfession(errs *fieldErrors, profession, kept string) (string, error) {
+	if profession == "" {
+		errs.add("profession", "is required")
+		return "", nil
+	}
+	p, err := s.resolveProfession(profession)
+	if err == store.ErrProfessionNotFound {
+		errs.add("profession", "unknown profession %q", profession)
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !p.Active && p.Code != kept {
+		errs.add("profession", "profession %v is not active", p.Code)
+		return "", nil
+	}
+	return p.Code, nil
+}
+
+// normalizeFilter lets a filter name its profession like a registration
+// does.
+func (s *Server) normalizeFilter(f *pb.StudentFilter) {
+	if f.GetProfession() == "" {
+		return
+	}
+	if p, err := s.resolveProfession(f.Profession); err == nil {
+		f.Profession = p.Code
+	}
+}
+
+// checkCapacity fails when storing after over before would take a student
+// into a profession that is already full. s.professionMux must be read
+// locked across the check and the write. A write that takes a place in a
+// profession with a capacity also holds the lock of that profession until it
+// calls unlock, so places are handed out one at a time.
+func (s *Server) checkCapacity(before, after *store.Student) (unlock func(), err error) {
+	if after == nil || after.DeletedTime != 0 {
+		return func() {}, nil
+	}
+	if before != nil && before.DeletedTime == 0 && before.Profession == after.Profession {
+		return func() {}, nil
+	}
+	return s.reserve(map[string]int{after.Profession: 1})
+}
+
+// checkCapacities is checkCapacity for a batch of new students.
+func (s *Server) checkCapacities(students []store.Student) (unlock func(), err error) {
+	added := make(map[string]int)
+	for _, stu := range students {
+		if stu.DeletedTime == 0 {
+			added[stu.Profession]++
+		}
+	}
+	return s.reserve(added)
+}
+
+// reserve checks that each profession in added exists and has room for as
+// many more students. The locks of those with a capacity are taken in order
+// of their codes and held until unlock is called.
+func (s *Server) reserve(added map[string]int) (unlock func(), err error) {
+	sorted := make([]string, 0, len(added))
+	for code := range added {
+		sorted = append(sorted, code)
+	}
+	sort.Strings(sorted)
+	var unlocks []func()
+	unlock = func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, code := range sorted {
+		p, err := s.professions.Get(code)
+		if err == store.ErrProfessionNotFound {
+			err = status.Errorf(codes.FailedPrecondition, "unknown profession %q", code)
+		}
+		if err != nil {
+			unlock()
+			return func() {}, err
+		}
+		if p.Capacity == 0 {
+			continue
+		}
+		unlocks = append(unlocks, s.professionLocks.lock(p.Code))
+		enrolled, err := s.countStudents(p.Code, store.ExcludeDeleted)
+		if err == nil && enrolled+added[code] > int(p.Capacity) {
+			if room := int(p.Capacity) - enrolled; room > 0 {
+				err = status.Errorf(codes.FailedPrecondition, "profession %v has room for %d more students, not %d", p.Code, room, added[code])
+			} else {
+				err = status.Errorf(codes.FailedPrecondition, "profession %v is full with %d students", p.Code, enrolled)
+			}
+		}
+		if err != nil {
+			unlock()
+			return func() {}, err
+		}
+	}
+	return unlock, nil
+}
+
+// checkCatalogue validates a profession about to be stored. Every name must
+// identify it alone, so no name may be the code or a name of another
+// profession.
+func (s *Server) checkCatalogue(p store.Profession) error {
+	var errs fieldErrors
+	if !professionCode(p.Code) {
+		errs.add("code", "must be up to 10 upper case letters and digits, starting with a letter")
+	}
+	if len(p.Names) == 0 {
+		errs.add("names", "a profession needs at least one name")
+	}
+	if p.Capacity < 0 {
+		errs.add("capacity", "must not be negative")
+	}
+	list, err := s.professions.List()
+	if err != nil {
+		return err
+	}
+	langs := make([]string, 0, len(p.Names))
+	for lang := range p.Names {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		field, name := "names."+lang, p.Names[lang]
+		if name == "" {
+			errs.add(field, "must not be empty")
+			continue
+		}
+		for _, other := range list {
+			if other.Code == p.Code {
+				continue
+			}
+			if other.Code == name {
+				errs.add(field, "%q is the code of another profession", name)
+			}
+			for _, otherName := range other.Names {
+				if otherName == name {
+					errs.add(field, "%q is taken by profession %v", name, other.Code)
+				}
+			}
+		}
+	}
+	return errs.err()
+}
+
+func (s *Server) CreateProfession(_ context.Context, info *pb.Profession) (*pb.Profession, error) {
+	p := toProfession(info)
+	s.professionMux.Lock()
+	defer s.professionMux.Unlock()
+	if err := s.checkCatalogue(p); err != nil {
+		log.Print(err)
+		return &pb.Profession{}, storeError(err)
+	}
+	if err := s.professions.Create(p); err != nil {
+		log.Print(err)
+		return &pb.Profession{}, storeError(err)
+	}
+	log.Printf("create profession %v success", p.Code)
+	return s.toProfessionInfo(p)
+}
+
+func (s *Server) GetProfession(_ context.Context, req *pb.ProfessionRequest) (*pb.Profession, error) {
+	p, err := s.professions.Get(req.GetCode())
+	if err != nil {
+		return &pb.Profession{}, storeError(err)
+	}
+	return s.toProfessionInfo(p)
+}
+
+func (s *Server) ListProfessions(_ context.Context, req *pb.ListProfessionsRequest) (*pb.ProfessionList, error) {
+	list, err := s.professions.List()
+	if err != nil {
+		return &pb.ProfessionList{}, storeError(err)
+	}
+	reply := &pb.ProfessionList{}
+	for _, p := range list {
+		if req.GetActiveOnly() && !p.Active {
+			continue
+		}
+		info, err := s.toProfessionInfo(p)
+		if err != nil {
+			return &pb.ProfessionList{}, err
+		}
+		reply.Professions = append(reply.Professions, info)
+	}
+	return reply, nil
+}
+
+// UpdateProfession replaces the names, active flag and capacity. Lowering the
+// capacity below the current enrolment only stops new students from joining.
+func (s *Server) UpdateProfession(_ context.Context, info *pb.Profession) (*pb.Profession, error) {
+	p := toProfession(info)
+	s.professionMux.Lock()
+	defer s.professionMux.Unlock()
+	if err := s.checkCatalogue(p); err != nil {
+		log.Print(err)
+		return &pb.Profession{}, storeError(err)
+	}
+	if err := s.professions.Update(p); err != nil {
+		log.Print(err)
+		return &pb.Profession{}, storeError(err)
+	}
+	log.Printf("update profession %v success", p.Code)
+	return s.toProfessionInfo(p)
+}
+
+// DeleteProfession removes a profession no student or course refers to,
+// counting the soft deleted students that could still be restored into it.
+func (s *Server) DeleteProfession(_ context.Context, req *pb.ProfessionRequest) (*pb.Result, error) {
+	s.professionMux.Lock()
+	defer s.professionMux.Unlock()
+	used, err := s.countStudents(req.GetCode(), store.IncludeDeleted)
+	if err != nil {
+		return &pb.Result{Res: false}, storeError(err)
+	}
+	if used > 0 {
+		return &pb.Result{Res: false}, status.Errorf(codes.FailedPrecondition, "profession %v is used by %d students", req.GetCode(), used)
+	}
+	courses, err := s.courses.List()
+	if err != nil {
+		return &pb.Result{Res: false}, storeError(err)
+	}
+	for _, c := range courses {
+		if listsProfession(c, req.GetCode()) {
+			return &pb.Result{Res: false}, status.Errorf(codes.FailedPrecondition, "profession %v is referred to by course %v", req.GetCode(), c.Code)
+		}
+	}
+	if err := s.professions.Delete(req.GetCode()); err != nil {
+		log.Print(err)
+		return &pb.Result{Res: false}, storeError(err)
+	}
+	log.Printf("delete profession %v success", req.GetCode())
+	return &pb.Result{Res: true}, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"mygolangproject/store"
+)
+
+// purgeDeleted permanently removes students soft deleted before cutoff. A
+// student restored or changed meanwhile is skipped thanks to the version
+// check on delete.
+func (s *Server) purgeDeleted(cutoff time.Time) (int, error) {
+	res, err := s.store.List(store.ListQuery{
+		Filter: store.Filter{Deleted: store.OnlyDeleted, DeletedTo: cutoff.Unix()},
+	})
+	if err != nil {
+		return 0, err
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(actorKey, "purger"))
+	purged := 0
+	for _, stu := range res.Students {
+		switch err := s.purge(ctx, "purge", stu); err {
+		case nil:
+			purged++
+		case store.ErrNotFound, store.ErrVersionMismatch:
+		default:
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// runsJobs reports whether the background jobs, which work on the data of the
+// whole cluster, are this server's to run: every member runs them, but only
+// the current leader does the work, checked anew each time.
+func (s *Server) runsJobs() bool {
+	return s.leading == nil || s.leading()
+}
+
+// runPurger purges the students deleted longer than retention ago, on the
+// leader only in a cluster.
+func (s *Server) runPurger(retention, interval time.Duration) {
+	for range time.Tick(interval) {
+		if !s.runsJobs() {
+			continue
+		}
+		n, err := s.purgeDeleted(time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("purge deleted students failed: %v", err)
+		}
+		if n > 0 {
+			log.Printf("purged %d deleted students", n)
+		}
+	}
+}
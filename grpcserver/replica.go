@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+const (
+	primaryTrailer      = "x-primary"
+	lagHeader           = "x-replication-lag-ms"
+	replicaRetry        = time.Second
+	replicaPageSize     = 500
+	serviceMethodPrefix = "/proto.Service/"
+	// catalogueRefresh is how often a replica copies the profession
+	// catalogue, which has no watch stream, from the primary.
+	catalogueRefresh = 10 * time.Second
+)
+
+var replicaOf = flag.String("replica-of", "", "gRPC address of a primary, not a cluster member, to follow; the server then only serves reads")
+
+// replicaReads are the methods a replica answers itself. Everything else
+// either writes or needs the change history, which a replica does not copy,
+// and is sent back with the address of the primary.
+var replicaReads = map[string]bool{
+	serviceMethodPrefix + "SayHello":  true,
+	serviceMethodPrefix + "Query":     true,
+	serviceMethodPrefix + "QueryList": true,
+
+	serviceMethodPrefix + "GetProfession":   true,
+	serviceMethodPrefix + "ListProfessions": true,
+
+	serviceMethodPrefix + "FindDuplicates": true,
+
+	serviceMethodPrefix + "ExportStudents": true,
+}
+
+var (
+	errPrimaryRestarted = errors.New("primary restarted, reloading all students")
+	// errClusterMember refuses to follow a member of a cluster: its watch
+	// stream only carries the writes that member took itself.
+	errClusterMember = errors.New("primary is a member of a cluster, whose watch streams miss the writes of the other members")
+)
+
+// replica keeps a copy of the primary's students by loading them once and
+// then tailing the primary's watch stream, and a copy of its profession
+// catalogue, reloaded every catalogueRefresh. It copies no aliases: Query
+// passes an id the replica does not hold on to the primary, which may know it
+// as one retired by a merge.
+type replica struct {
+	primary     string
+	client      pb.ServiceClient
+	students    *store.MemoryStore
+	professions *store.MemoryProfessions
+
+	mux      sync.Mutex
+	revision int64  // last revision of the primary that has been applied
+	epoch    string // the primary's epoch that revision belongs to
+	caughtUp int64  // unix nanoseconds up to which every change is applied
+}
+
+func newReplica(primary string) (*replica, error) {
+	conn, err := grpc.Dial(primary, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return &replica{
+		primary:     primary,
+		client:      pb.NewServiceClient(conn),
+		students:    store.NewMemoryStore(),
+		professions: store.NewMemoryProfessions(nil),
+	}, nil
+}
+
+// run follows the primary until the process exits, reconnecting after every
+// interruption.
+func (r *replica) run() {
+	go r.refreshCatalogue()
+	for {
+		err := r.follow(context.Background())
+		if err == errClusterMember {
+			log.Fatalf("cannot replicate %v: %v", r.primary, err)
+		}
+		log.Printf("replication from %v interrupted: %v", r.primary, err)
+		time.Sleep(replicaRetry)
+	}
+}
+
+func (r *replica) refreshCatalogue() {
+	for range time.Tick(catalogueRefresh) {
+		if err := r.loadProfessions(context.Background()); err != nil {
+			log.Printf("copying the professions of %v failed: %v", r.primary, err)
+		}
+	}
+}
+
+// follow resumes the watch from the last applied revision, or loads every
+// student first when there is none, and applies events until the stream
+// breaks.
+func (r *replica) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.mux.Lock()
+	from, epoch := r.revision, r.epoch
+	r.mux.Unlock()
+
+	stream, err := r.client.WatchStudents(ctx, &pb.WatchRequest{FromRevision: from, ProgressNotify: true})
+	if err != nil {
+		return err
+	}
+	// The header arrives once the primary is watching, so nothing written
+	// after it can be missing from both the load below and the stream.
+	header, err := stream.Header()
+	if err == nil && len(header.Get(revisionHeader)) == 0 {
+		// The watch failed before it started; Recv returns the status.
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		return r.streamError(err)
+	}
+	if firstValue(header, clusterHeader) != "" {
+		return errClusterMember
+	}
+	start, err := strconv.ParseInt(firstValue(header, revisionHeader), 10, 64)
+	if err != nil {
+		return err
+	}
+	if from != 0 && firstValue(header, epochHeader) != epoch {
+		r.setRevision(0, "")
+		return errPrimaryRestarted
+	}
+	if from == 0 {
+		loaded := time.Now().UnixNano()
+		if err := r.load(ctx); err != nil {
+			return err
+		}
+		r.setRevision(start, firstValue(header, epochHeader))
+		r.setCaughtUp(loaded)
+		log.Printf("loaded the students of %v at revision %d", r.primary, start)
+	}
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return r.streamError(err)
+		}
+		r.apply(ev)
+	}
+}
+
+// streamError forgets the applied revision when the primary can no longer
+// resume from it, so that the next attempt reloads every student.
+func (r *replica) streamError(err error) error {
+	if status.Code(err) == codes.OutOfRange {
+		r.setRevision(0, "")
+	}
+	return err
+}
+
+// load copies the profession catalogue and every student of the primary, the
+// students page by page in creation order. Students created while it runs
+// land on the last pages and updates do not move a student between pages;
+// whatever changes meanwhile is also in the watch stream.
+func (r *replica) load(ctx context.Context) error {
+	if err := r.loadProfessions(ctx); err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	req := &pb.QueryRequest{
+		Filter:    &pb.StudentFilter{Deleted: pb.DeletedFilter_INCLUDE_DELETED},
+		SortBy:    pb.SortKey_CREATE_TIME,
+		Ascending: true,
+		PageSize:  replicaPageSize,
+	}
+	for {
+		list, err := r.client.QueryList(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, info := range list.StudentInfo {
+			stu := fromStudentInfo(info)
+			seen[stu.Id] = true
+			r.put(stu)
+		}
+		if list.NextPageToken == "" {
+			break
+		}
+		req.PageToken = list.NextPageToken
+	}
+	// Students purged while the replica was not watching.
+	all, err := r.students.List(store.ListQuery{Filter: store.Filter{Deleted: store.IncludeDeleted}})
+	if err != nil {
+		return err
+	}
+	for _, stu := range all.Students {
+		if !seen[stu.Id] {
+			r.students.Delete(stu.Id, 0)
+		}
+	}
+	return nil
+}
+
+// loadProfessions makes the catalogue of the replica that of the primary.
+func (r *replica) loadProfessions(ctx context.Context) error {
+	list, err := r.client.ListProfessions(ctx, &pb.ListProfessionsRequest{})
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, info := range list.Professions {
+		p := toProfession(info)
+		seen[p.Code] = true
+		err := r.professions.Update(p)
+		if err == store.ErrProfessionNotFound {
+			err = r.professions.Create(p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	current, err := r.professions.List()
+	if err != nil {
+		return err
+	}
+	for _, p := range current {
+		if !seen[p.Code] {
+			r.professions.Delete(p.Code)
+		}
+	}
+	return nil
+}
+
+func (r *replica) apply(ev *pb.StudentEvent) {
+	switch ev.Type {
+	case pb.StudentEvent_PROGRESS:
+	case pb.StudentEvent_PURGED:
+		r.students.Delete(ev.Student.GetId(), 0)
+	default:
+		r.put(fromStudentInfo(ev.Student))
+	}
+	if ev.Type != pb.StudentEvent_PROGRESS {
+		r.setRevision(ev.Revision, "")
+	}
+	r.setCaughtUp(ev.Time)
+}
+
+// put stores stu unless the replica already holds the same or a later
+// version of it, as it may after loading while the stream replays.
+func (r *replica) put(stu store.Student) {
+	if old, err := r.students.Get(stu.Id); err == nil && old.Version >= stu.Version {
+		return
+	}
+	r.students.Put(stu)
+}
+
+// setRevision records the last applied revision; an empty epoch keeps the
+// current one.
+func (r *replica) setRevision(revision int64, epoch string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.revision = revision
+	if epoch != "" || revision == 0 {
+		r.epoch = epoch
+	}
+}
+
+func (r *replica) setCaughtUp(t int64) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if t > r.caughtUp {
+		r.caughtUp = t
+	}
+}
+
+// lag is how far the copy trails the primary: the time since the primary
+// last reported a change, or its progress, that the replica has applied.
+// It grows while the stream is down and is false before the first load.
+func (r *replica) lag() (time.Duration, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.caughtUp == 0 {
+		return 0, false
+	}
+	lag := time.Since(time.Unix(0, r.caughtUp))
+	if lag < 0 {
+		lag = 0
+	}
+	return lag, true
+}
+
+// check admits the reads a replica can serve and tags them with the current
+// lag; anything else is refused with the primary to send it to.
+func (r *replica) check(ctx context.Context, method string, req interface{}) error {
+	asOf, ok := req.(interface{ GetAsOf() int64 })
+	if !replicaReads[method] || ok && asOf.GetAsOf() != 0 {
+		grpc.SetTrailer(ctx, metadata.Pairs(primaryTrailer, r.primary))
+		return status.Errorf(codes.FailedPrecondition, "this server is a read-only replica, send %v to the primary at %v", method, r.primary)
+	}
+	lag, ok := r.lag()
+	if !ok {
+		return status.Errorf(codes.Unavailable, "replica has not loaded the students of %v yet", r.primary)
+	}
+	return grpc.SetHeader(ctx, metadata.Pairs(lagHeader, strconv.FormatInt(int64(lag/time.Millisecond), 10)))
+}
+
+func (r *replica) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := r.check(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	reply, err := handler(ctx, req)
+	if info.FullMethod == serviceMethodPrefix+"Query" && status.Code(err) == codes.NotFound {
+		// The id may be one the primary retired by a merge.
+		return r.client.Query(ctx, req.(*pb.QueryStudentRequest))
+	}
+	return reply, err
+}
+
+// streamInterceptor lets exports through. Watches are refused along with the
+// writes, since the replica publishes no events of its own.
+func (r *replica) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := r.check(ss.Context(), info.FullMethod, nil); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// fromStudentInfo is the inverse of toStudentInfo.
+func fromStudentInfo(info *pb.StudentInfo) store.Student {
+	return store.Student{
+		Id:           info.GetId(),
+		Name:         info.GetName(),
+		Age:          info.GetAge(),
+		Profession:   info.GetProfession(),
+		CreateTime:   info.GetCreateTimeNanos(),
+		ModifiedTime: info.GetModifiedTimeNanos(),
+		Version:      info.GetVersion(),
+		DeletedTime:  info.GetDeletedTime(),
+		DuplicateOf:  info.GetDuplicateOf(),
+		Number:       info.GetNumber(),
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+)
+
+func TestStoreError(t *testing.T) {
+	if err := storeError(nil); err != nil {
+		t.Errorf("storeError(nil) = %v", err)
+	}
+	for err, code := range statusCodes {
+		if got := status.Code(storeError(err)); got != code {
+			t.Errorf("storeError(%v) = %v, want %v", err, got, code)
+		}
+	}
+	noLeader := status.Error(codes.Unavailable, "no leader")
+	if got := storeError(noLeader); got != noLeader {
+		t.Errorf("storeError(%v) = %v, want it unchanged", noLeader, got)
+	}
+	if got := status.Code(storeError(errors.New("disk full"))); got != codes.Internal {
+		t.Errorf("unknown store error became %v, want %v", got, codes.Internal)
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	_, c := startServer(t)
+	ctx := context.Background()
+	live := mustRegister(t, c, "alice", 20, "SE")
+	deleted := mustRegister(t, c, "bob", 20, "SE")
+	if _, err := c.Delete(ctx, &pb.StudentInfo{Id: deleted.Id}); err != nil {
+		t.Fatal(err)
+	}
+	const missing = "0190f5a8-0000-7000-8000-000000000000"
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"query unknown", func() error {
+			_, err := c.Query(ctx, &pb.QueryStudentRequest{Id: missing})
+			return err
+		}, codes.NotFound},
+		{"query deleted", func() error {
+			_, err := c.Query(ctx, &pb.QueryStudentRequest{Id: deleted.Id})
+			return err
+		}, codes.NotFound},
+		{"query negative asOf", func() error {
+			_, err := c.Query(ctx, &pb.QueryStudentRequest{Id: live.Id, AsOf: -1})
+			return err
+		}, codes.InvalidArgument},
+		{"register invalid", func() error {
+			_, err := c.Register(ctx, &pb.RegisterRequest{Name: "alice", Age: 500, Profession: "SE"})
+			return err
+		}, codes.InvalidArgument},
+		{"alter unknown", func() error {
+			_, err := c.AlterProfession(ctx, &pb.StudentInfo{Id: missing, Profession: "CST"})
+			return err
+		}, codes.NotFound},
+		{"delete unknown", func() error {
+			_, err := c.Delete(ctx, &pb.StudentInfo{Id: missing})
+			return err
+		}, codes.NotFound},
+		{"delete deleted", func() error {
+			_, err := c.Delete(ctx, &pb.StudentInfo{Id: deleted.Id})
+			return err
+		}, codes.NotFound},
+		{"delete stale version", func() error {
+			_, err := c.Delete(ctx, &pb.StudentInfo{Id: live.Id, Version: 7})
+			return err
+		}, codes.Aborted},
+		{"restore live", func() error {
+			_, err := c.RestoreStudent(ctx, &pb.StudentInfo{Id: live.Id})
+			return err
+		}, codes.FailedPrecondition},
+		{"restore unknown", func() error {
+			_, err := c.RestoreStudent(ctx, &pb.StudentInfo{Id: missing})
+			return err
+		}, codes.NotFound},
+		{"get unknown profession", func() error {
+			_, err := c.GetProfession(ctx, &pb.ProfessionRequest{Code: "XX"})
+			return err
+		}, codes.NotFound},
+		{"create existing profession", func() error {
+			_, err := c.CreateProfession(ctx, &pb.Profession{Code: "SE", Names: map[string]string{"en": "Software"}, Active: true})
+			return err
+		}, codes.AlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.call(), tt.want)
+		})
+	}
+	if _, err := c.Query(ctx, &pb.QueryStudentRequest{Id: live.Id}); err != nil {
+		t.Errorf("failed calls broke %v: %v", live.Id, err)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"mygolangproject/idgen"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+const (
+	maxImportRows   = 100000
+	exportPageSize  = 500
+	exportChunkSize = 32 << 10
+)
+
+// ExportStudents streams every student matching the filter, oldest first,
+// as a file in the requested format.
+func (s *Server) ExportStudents(req *pb.ExportRequest, stream pb.Service_ExportStudentsServer) error {
+	s.normalizeFilter(req.GetFilter())
+	q, err := toListQuery(&pb.QueryRequest{Filter: req.GetFilter(), Ascending: true, PageSize: exportPageSize})
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriterSize(chunkWriter{stream}, exportChunkSize)
+	w, err := newStudentWriter(req.GetFormat(), out)
+	if err != nil {
+		return invalidField("format", "%v", err)
+	}
+	count := 0
+	for {
+		res, err := s.store.List(q)
+		if err != nil {
+			return storeError(err)
+		}
+		for _, stu := range res.Students {
+			// Files carry the name, which imports resolve back to the code.
+			stu.Profession = s.professionName(stu.Profession)
+			if err := w.write(stu); err != nil {
+				return err
+			}
+		}
+		count += len(res.Students)
+		if !res.More {
+			break
+		}
+		last := res.Students[len(res.Students)-1]
+		q.After = &last
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	log.Printf("exported %d students as %v", count, req.GetFormat())
+	return nil
+}
+
+// chunkWriter sends every write as one FileChunk.
+type chunkWriter struct {
+	stream pb.Service_ExportStudentsServer
+}
+
+func (c chunkWriter) Write(p []byte) (int, error) {
+	if err := c.stream.Send(&pb.FileChunk{Data: append([]byte(nil), p...)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ImportStudents registers the students of an uploaded file. Every row is
+// validated before anything is written, so a dry run reports exactly what
+// an import would do, short of failures of the store itself.
+func (s *Server) ImportStudents(stream pb.Service_ImportStudentsServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return stream.SendAndClose(&pb.ImportReport{})
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := readStudents(first.GetFormat(), &importReader{stream: stream, data: first.GetData()}, maxImportRows)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Errorf(codes.InvalidArgument, "cannot read %v file: %v", first.GetFormat(), err)
+		}
+		return err
+	}
+	report := &pb.ImportReport{DryRun: first.GetDryRun()}
+	report.Results = s.importRows(stream.Context(), rows, first.GetDryRun(), first.GetAllOrNothing())
+	for _, res := range report.Results {
+		if res.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+	log.Printf("import of %d %v rows (dry run %v): %d succeeded, %d failed",
+		len(rows), first.GetFormat(), report.DryRun, report.Succeeded, report.Failed)
+	return stream.SendAndClose(report)
+}
+
+// importRows validates and stores the rows of a file. With allOrNothing a
+// single invalid row rejects the file and the rows are stored together by
+// createAll.
+func (s *Server) importRows(ctx context.Context, rows []importRow, dryRun, allOrNothing bool) []*pb.BulkRegisterResult {
+	results := make([]*pb.BulkRegisterResult, len(rows))
+	var valid []store.Student
+	var validResults []*pb.BulkRegisterResult
+	check := newImportCheck()
+	for i, row := range rows {
+		results[i] = &pb.BulkRegisterResult{Row: int32(i + 1), Id: row.student.Id}
+		err := s.checkImportRow(&row, check)
+		if err != nil {
+			results[i].Error = errorMessage(err)
+			continue
+		}
+		results[i].Success = true
+		if row.student.Id == "" {
+			row.student.Id = s.ids.New()
+		}
+		check.accept(row.student)
+		valid = append(valid, row.student)
+		validResults = append(validResults, results[i])
+	}
+	switch {
+	case dryRun:
+		return results
+	case allOrNothing && len(valid) < len(rows):
+		return rejectRemaining(results, "import rejected: another row is invalid")
+	case allOrNothing:
+		return s.createAll(ctx, "import", valid, results)
+	}
+	for i := range valid {
+		stu, res := &valid[i], validResults[i]
+		if err := s.create(ctx, "import", stu); err != nil {
+			res.Success = false
+			res.Error = errorMessage(err)
+			continue
+		}
+		res.Id = stu.Id
+	}
+	return results
+}
+
+// importCheck is what checking a row of a file needs to know of the rows
+// accepted before it, which an import stores first.
+type importCheck struct {
+	ids      map[string]bool // given in the file
+	accepted pendingStudents
+	taken    map[string]int // places in each profession
+}
+
+func newImportCheck() *importCheck {
+	return &importCheck{ids: make(map[string]bool), accepted: make(pendingStudents), taken: make(map[string]int)}
+}
+
+func (c *importCheck) accept(stu store.Student) {
+	c.accepted.add(stu)
+	if stu.DeletedTime == 0 {
+		c.taken[stu.Profession]++
+	}
+}
+
+// checkImportRow validates a row like a registration, duplicates and
+// capacity included, counting the rows accepted before it as stored, and
+// makes sure a given id is written like the ids the server hands out and is
+// neither repeated in the file nor taken already.
+func (s *Server) checkImportRow(row *importRow, check *importCheck) error {
+	if row.err != nil {
+		return row.err
+	}
+	if err := s.checkStudent(&row.student, ""); err != nil {
+		return err
+	}
+	stu := row.student
+	if stu.Id != "" {
+		if !idgen.Valid(stu.Id) {
+			return invalidField("id", "must be a UUID or a ULID")
+		}
+		if check.ids[stu.Id] {
+			return errors.New("id repeated in the file")
+		}
+		check.ids[stu.Id] = true
+		if _, err := s.store.Get(stu.Id); err == nil {
+			return store.ErrAlreadyExists
+		}
+	}
+	// Both are checked again when the row is stored; this tells a dry run.
+	if err := s.checkDuplicate(&stu, check.accepted); err != nil {
+		return err
+	}
+	if stu.DeletedTime != 0 {
+		return nil
+	}
+	p, err := s.professions.Get(stu.Profession)
+	if err != nil || p.Capacity == 0 {
+		return storeError(err)
+	}
+	enrolled, err := s.countStudents(p.Code, store.ExcludeDeleted)
+	if err != nil {
+		return storeError(err)
+	}
+	if enrolled += check.taken[p.Code]; enrolled >= int(p.Capacity) {
+		return status.Errorf(codes.FailedPrecondition, "profession %v is full with %d students", p.Code, enrolled)
+	}
+	return nil
+}
+
+// importReader reads the file content carried by an import stream.
+type importReader struct {
+	stream pb.Service_ImportStudentsServer
+	data   []byte
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data = req.GetData()
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "mygolangproject/proto"
+)
+
+func importFile(t *testing.T, c pb.ServiceClient, data string, dryRun bool) *pb.ImportReport {
+	t.Helper()
+	stream, err := c.ImportStudents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&pb.ImportRequest{Format: pb.FileFormat_CSV, DryRun: dryRun, Data: []byte(data)}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("ImportStudents: %v", err)
+	}
+	return report
+}
+
+// TestImportDryRun checks that a dry run reports for every row what the
+// import then does, including rows that only fail because of the rows
+// before them in the file.
+func TestImportDryRun(t *testing.T) {
+	s, c := startServer(t)
+	s.duplicates = duplicatesReject
+	setCapacity(t, s, "SE", 2)
+	const file = "name,age,profession\nalice,20,SE\nalise,20,SE\nbob,21,SE\ncarol,22,SE\ndave,23,CST\n"
+	want := []string{"", "duplicate of student", "", "profession SE is full with 2 students", ""}
+
+	for _, dryRun := range []bool{true, false} {
+		report := importFile(t, c, file, dryRun)
+		if len(report.Results) != len(want) {
+			t.Fatalf("dry run %v: %d results, want %d", dryRun, len(report.Results), len(want))
+		}
+		for i, res := range report.Results {
+			if res.Success != (want[i] == "") || !strings.Contains(res.Error, want[i]) {
+				t.Errorf("dry run %v: row %d = %v, want error %q", dryRun, res.Row, res, want[i])
+			}
+		}
+		stored := 0
+		if !dryRun {
+			stored = 3
+		}
+		if n := storedStudents(t, s); n != stored {
+			t.Fatalf("dry run %v: %d students stored, want %d", dryRun, n, stored)
+		}
+	}
+}
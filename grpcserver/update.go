@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "mygolangproject/proto"
+)
+
+// updatableFields are the updateMask paths accepted by UpdateStudent and
+// the ones an empty mask stands for.
+var updatableFields = []string{"name", "age", "profession"}
+
+// reviewedField clears the duplicate flag of a student. It is only updated
+// when the mask names it, so that clients unaware of it never clear it.
+const reviewedField = "duplicateOf"
+
+func updatable(path string) bool {
+	if path == reviewedField {
+		return true
+	}
+	for _, field := range updatableFields {
+		if path == field {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateStudent overwrites the fields named in the update mask, or all
+// updatable fields when the mask is empty, and returns the stored record.
+// A non-zero student.version makes the update conditional.
+func (s *Server) UpdateStudent(ctx context.Context, req *pb.UpdateStudentRequest) (*pb.StudentInfo, error) {
+	info := req.GetStudent()
+	if info.GetId() == "" {
+		return &pb.StudentInfo{}, invalidField("id", "is required")
+	}
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = updatableFields
+	}
+	var errs fieldErrors
+	for _, path := range paths {
+		if !updatable(path) {
+			errs.add("updateMask", "field %q cannot be updated", path)
+		}
+		if path == reviewedField && info.GetDuplicateOf() != "" {
+			errs.add("duplicateOf", "can only be cleared")
+		}
+	}
+	if err := errs.err(); err != nil {
+		return &pb.StudentInfo{}, err
+	}
+
+	studentInfo, err := s.getLive(info.GetId())
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	if err := checkVersion(studentInfo, info.GetVersion()); err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	updated := studentInfo
+	for _, path := range paths {
+		switch path {
+		case "name":
+			updated.Name = info.GetName()
+		case "age":
+			updated.Age = info.GetAge()
+		case "profession":
+			updated.Profession = info.GetProfession()
+		case reviewedField:
+			updated.DuplicateOf = ""
+		}
+	}
+	if err := s.checkStudent(&updated, studentInfo.Profession); err != nil {
+		return &pb.StudentInfo{}, err
+	}
+	updated.ModifiedTime = time.Now().UnixNano()
+	updated, err = s.update(ctx, "updateStudent", studentInfo, updated)
+	if err != nil {
+		log.Print(err)
+		return &pb.StudentInfo{}, err
+	}
+	log.Printf("update student %v %v success", info.GetId(), paths)
+	return s.toStudentInfo(updated), nil
+}
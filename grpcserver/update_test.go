@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genproto/protobuf/field_mask"
+	pb "mygolangproject/proto"
+)
+
+func TestUpdateStudentMask(t *testing.T) {
+	tests := []struct {
+		name  string
+		info  pb.StudentInfo
+		paths []string
+		want  pb.StudentInfo
+	}{
+		{"name only", pb.StudentInfo{Name: "bob", Age: 30, Profession: "CST"}, []string{"name"},
+			pb.StudentInfo{Name: "bob", Age: 20, Profession: "SE"}},
+		{"age and profession", pb.StudentInfo{Name: "bob", Age: 30, Profession: "CST"}, []string{"age", "profession"},
+			pb.StudentInfo{Name: "alice", Age: 30, Profession: "CST"}},
+		{"empty mask", pb.StudentInfo{Name: "bob", Age: 30, Profession: "CST"}, nil,
+			pb.StudentInfo{Name: "bob", Age: 30, Profession: "CST"}},
+		{"profession by name", pb.StudentInfo{Profession: "Computer Science and Technology"}, []string{"profession"},
+			pb.StudentInfo{Name: "alice", Age: 20, Profession: "CST"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := startServer(t)
+			ctx := context.Background()
+			reply := mustRegister(t, c, "alice", 20, "SE")
+			before, err := c.Query(ctx, &pb.QueryStudentRequest{Id: reply.Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.info.Id = reply.Id
+			got, err := c.UpdateStudent(ctx, &pb.UpdateStudentRequest{
+				Student: &tt.info, UpdateMask: &field_mask.FieldMask{Paths: tt.paths}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != tt.want.Name || got.Age != tt.want.Age || got.Profession != tt.want.Profession {
+				t.Errorf("updated to %v, %v, %v, want %v, %v, %v",
+					got.Name, got.Age, got.Profession, tt.want.Name, tt.want.Age, tt.want.Profession)
+			}
+			if got.Version != before.Version+1 {
+				t.Errorf("version %d after an update of version %d", got.Version, before.Version)
+			}
+			if got.ModifiedTimeNanos <= before.ModifiedTimeNanos || got.CreateTimeNanos != before.CreateTimeNanos {
+				t.Errorf("update changed times %d, %d to %d, %d", before.CreateTimeNanos, before.ModifiedTimeNanos,
+					got.CreateTimeNanos, got.ModifiedTimeNanos)
+			}
+			stored, err := c.Query(ctx, &pb.QueryStudentRequest{Id: reply.Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Name != got.Name || stored.Age != got.Age || stored.Profession != got.Profession || stored.Version != got.Version {
+				t.Errorf("stored %v, returned %v", stored, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"mygolangproject/store"
+)
+
+const (
+	minAge = 10
+	maxAge = 100
+)
+
+var nameCheck = regexp.MustCompile(`^[a-zA-Z]+$`).MatchString
+
+// fieldErrors collects what is wrong with the fields of a request, so that
+// one reply tells the client about all of them.
+type fieldErrors []*errdetails.BadRequest_FieldViolation
+
+func (f *fieldErrors) add(field, format string, args ...interface{}) {
+	*f = append(*f, &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// err returns nil if every field is fine, or else an INVALID_ARGUMENT status
+// with the violations attached as errdetails.BadRequest. The message lists
+// them too, for clients that do not read details.
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(f))
+	for i, v := range f {
+		msgs[i] = v.Field + ": " + v.Description
+	}
+	st := status.New(codes.InvalidArgument, strings.Join(msgs, "; "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: f}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// invalidField is the error for a request with a single wrong field.
+func invalidField(field, format string, args ...interface{}) error {
+	var errs fieldErrors
+	errs.add(field, format, args...)
+	return errs.err()
+}
+
+// errorMessage is the text of err without the status code, for reporting
+// errors of single rows.
+func errorMessage(err error) string {
+	return status.Convert(err).Message()
+}
+
+// checkStudent checks every field of a student about to be stored and
+// replaces the profession, which may be given by name, with its code. kept is
+// the profession the student already has, which may stay even if it is no
+// longer active.
+func (s *Server) checkStudent(stu *store.Student, kept string) error {
+	var errs fieldErrors
+	if !nameCheck(stu.Name) {
+		errs.add("name", "must be one or more letters a-z or A-Z")
+	}
+	if stu.Age < minAge || stu.Age > maxAge {
+		errs.add("age", "must be between %d and %d", minAge, maxAge)
+	}
+	profession, err := s.checkProfession(&errs, stu.Profession, kept)
+	if err != nil {
+		return storeError(err)
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	stu.Profession = profession
+	return nil
+}
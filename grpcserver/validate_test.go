@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+)
+
+// violations returns the fields of the BadRequest an INVALID_ARGUMENT error
+// carries, in order.
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	wantCode(t, err, codes.InvalidArgument)
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	if fields == nil {
+		t.Fatalf("%v carries no BadRequest", err)
+	}
+	return fields
+}
+
+func TestRegisterValidation(t *testing.T) {
+	s, c := startServer(t)
+	p, err := s.professions.Get("CST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Active = false
+	if err := s.professions.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		req  pb.RegisterRequest
+		want []string
+	}{
+		{"all wrong", pb.RegisterRequest{Name: "", Age: 500, Profession: "XX"}, []string{"name", "age", "profession"}},
+		{"empty name", pb.RegisterRequest{Name: "", Age: 20, Profession: "SE"}, []string{"name"}},
+		{"digits in name", pb.RegisterRequest{Name: "al1ce", Age: 20, Profession: "SE"}, []string{"name"}},
+		{"too young", pb.RegisterRequest{Name: "alice", Age: minAge - 1, Profession: "SE"}, []string{"age"}},
+		{"too old", pb.RegisterRequest{Name: "alice", Age: maxAge + 1, Profession: "SE"}, []string{"age"}},
+		{"no profession", pb.RegisterRequest{Name: "alice", Age: 20}, []string{"profession"}},
+		{"unknown profession", pb.RegisterRequest{Name: "alice", Age: 20, Profession: "XX"}, []string{"profession"}},
+		{"inactive profession", pb.RegisterRequest{Name: "alice", Age: 20, Profession: "CST"}, []string{"profession"}},
+		{"age and profession", pb.RegisterRequest{Name: "alice", Age: 0, Profession: "XX"}, []string{"age", "profession"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Register(context.Background(), &tt.req)
+			if got := violations(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if n := storedStudents(t, s); n != 0 {
+		t.Errorf("%d students stored by invalid registrations", n)
+	}
+}
+
+func TestUpdateStudentValidation(t *testing.T) {
+	_, c := startServer(t)
+	stu := mustRegister(t, c, "alice", 20, "SE")
+	tests := []struct {
+		name  string
+		info  pb.StudentInfo
+		paths []string
+		want  []string
+	}{
+		{"no id", pb.StudentInfo{Name: "bob"}, []string{"name"}, []string{"id"}},
+		{"unknown paths", pb.StudentInfo{Id: stu.Id}, []string{"id", "createTime"}, []string{"updateMask", "updateMask"}},
+		{"flag set", pb.StudentInfo{Id: stu.Id, DuplicateOf: stu.Id}, []string{"duplicateOf"}, []string{"duplicateOf"}},
+		{"all fields", pb.StudentInfo{Id: stu.Id, Name: "b0b", Age: 5, Profession: "XX"}, nil, []string{"name", "age", "profession"}},
+		{"masked fields only", pb.StudentInfo{Id: stu.Id, Name: "b0b", Age: 5}, []string{"age"}, []string{"age"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.UpdateStudent(context.Background(), &pb.UpdateStudentRequest{
+				Student: &tt.info, UpdateMask: &field_mask.FieldMask{Paths: tt.paths}})
+			if got := violations(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeValidation(t *testing.T) {
+	_, c := startServer(t)
+	_, err := c.MergeStudents(context.Background(), &pb.MergeStudentsRequest{
+		Rule: pb.MergeRule(42), FromRetired: &field_mask.FieldMask{Paths: []string{"name", "id"}}})
+	want := []string{"survivorId", "retiredId", "rule", "fromRetired"}
+	if got := violations(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc/codes"
+	pb "mygolangproject/proto"
+)
+
+// conditionalWrites are the writes that take the version a client last saw,
+// as the gateway passes on an If-Match.
+var conditionalWrites = []struct {
+	name  string
+	write func(c pb.ServiceClient, id string, version int64) error
+}{
+	{"AlterProfession", func(c pb.ServiceClient, id string, version int64) error {
+		_, err := c.AlterProfession(context.Background(), &pb.StudentInfo{Id: id, Profession: "CST", Version: version})
+		return err
+	}},
+	{"UpdateStudent", func(c pb.ServiceClient, id string, version int64) error {
+		_, err := c.UpdateStudent(context.Background(), &pb.UpdateStudentRequest{
+			Student:    &pb.StudentInfo{Id: id, Age: 30, Version: version},
+			UpdateMask: &field_mask.FieldMask{Paths: []string{"age"}}})
+		return err
+	}},
+	{"Delete", func(c pb.ServiceClient, id string, version int64) error {
+		_, err := c.Delete(context.Background(), &pb.StudentInfo{Id: id, Version: version})
+		return err
+	}},
+}
+
+func TestConditionalWrites(t *testing.T) {
+	for _, tt := range conditionalWrites {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := startServer(t)
+			ctx := context.Background()
+			reply := mustRegister(t, c, "alice", 20, "SE")
+			stu, err := c.Query(ctx, &pb.QueryStudentRequest{Id: reply.Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stu.Version != 1 {
+				t.Fatalf("registered at version %d, want 1", stu.Version)
+			}
+			// Another admin changes the student in between.
+			if _, err := c.UpdateStudent(ctx, &pb.UpdateStudentRequest{
+				Student:    &pb.StudentInfo{Id: reply.Id, Name: "alicia", Version: stu.Version},
+				UpdateMask: &field_mask.FieldMask{Paths: []string{"name"}}}); err != nil {
+				t.Fatal(err)
+			}
+			wantCode(t, tt.write(c, reply.Id, stu.Version), codes.Aborted)
+			after, err := c.Query(ctx, &pb.QueryStudentRequest{Id: reply.Id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.Version != 2 || after.Name != "alicia" || after.Age != 20 || after.Profession != "SE" {
+				t.Errorf("aborted write changed the student to %v", after)
+			}
+			if err := tt.write(c, reply.Id, after.Version); err != nil {
+				t.Errorf("write at the current version: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnconditionalWrites(t *testing.T) {
+	for _, tt := range conditionalWrites {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := startServer(t)
+			reply := mustRegister(t, c, "alice", 20, "SE")
+			// Move past version 1, so that 0 cannot pass for a real version.
+			if _, err := c.AlterProfession(context.Background(), &pb.StudentInfo{Id: reply.Id, Profession: "SE"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.write(c, reply.Id, 0); err != nil {
+				t.Errorf("write without a version: %v", err)
+			}
+		})
+	}
+}
+
+// TestConcurrentConditionalWrites has several clients write the version
+// they read at once; exactly one of them may win.
+func TestConcurrentConditionalWrites(t *testing.T) {
+	_, c := startServer(t)
+	reply := mustRegister(t, c, "alice", 20, "SE")
+	const writers = 8
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(age int32) {
+			defer wg.Done()
+			_, err := c.UpdateStudent(context.Background(), &pb.UpdateStudentRequest{
+				Student:    &pb.StudentInfo{Id: reply.Id, Age: age, Version: 1},
+				UpdateMask: &field_mask.FieldMask{Paths: []string{"age"}}})
+			errs <- err
+		}(int32(20 + i))
+	}
+	wg.Wait()
+	close(errs)
+	won := 0
+	for err := range errs {
+		if err == nil {
+			won++
+			continue
+		}
+		wantCode(t, err, codes.Aborted)
+	}
+	if won != 1 {
+		t.Errorf("%d writers of version 1 succeeded, want 1", won)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+const (
+	revisionHeader = "x-revision"
+	epochHeader    = "x-epoch"
+	// clusterHeader is set by members of a cluster, whose streams only
+	// carry the writes they took themselves.
+	clusterHeader = "x-cluster-member"
+	// progressInterval is how long a watch with progressNotify stays silent
+	// before it sends a PROGRESS event.
+	progressInterval = time.Second
+)
+
+var errIdle = errors.New("no event within the progress interval")
+
+var eventTypes = map[store.EventType]pb.StudentEvent_EventType{
+	store.Created:  pb.StudentEvent_CREATED,
+	store.Updated:  pb.StudentEvent_UPDATED,
+	store.Deleted:  pb.StudentEvent_DELETED,
+	store.Restored: pb.StudentEvent_RESTORED,
+	store.Purged:   pb.StudentEvent_PURGED,
+}
+
+// WatchStudents streams student changes until the client goes away. A client
+// that falls too far behind is disconnected and should reconnect with the
+// last revision it received.
+func (s *Server) WatchStudents(req *pb.WatchRequest, stream pb.Service_WatchStudentsServer) error {
+	watcher, err := s.store.Watch(req.GetFromRevision())
+	switch err {
+	case nil:
+	case store.ErrCompacted:
+		return status.Errorf(codes.OutOfRange, "revision %d is no longer available, reload with QueryList", req.GetFromRevision())
+	case store.ErrFutureRevision:
+		return status.Errorf(codes.OutOfRange, "revision %d has not been reached yet", req.GetFromRevision())
+	default:
+		return storeError(err)
+	}
+	defer watcher.Close()
+	log.Printf("watch started from revision %d", req.GetFromRevision())
+	// The header tells the client which revision the stream continues from,
+	// and, through the epoch, whether revisions it kept still mean the same.
+	header := metadata.Pairs(
+		revisionHeader, strconv.FormatInt(watcher.Start(), 10),
+		epochHeader, s.epoch,
+	)
+	if s.clustered {
+		header.Set(clusterHeader, "true")
+	}
+	if err := stream.SendHeader(header); err != nil {
+		return err
+	}
+
+	last := watcher.Start()
+	for {
+		ev, err := nextEvent(stream.Context(), watcher, req.GetProgressNotify())
+		if err == errIdle {
+			err = stream.Send(&pb.StudentEvent{
+				Type:     pb.StudentEvent_PROGRESS,
+				Revision: last,
+				Time:     time.Now().UnixNano(),
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err == store.ErrSlowConsumer {
+			log.Printf("watcher dropped at revision %d: %v", last, err)
+			return status.Errorf(codes.ResourceExhausted, "%v, resume from revision %d", err, last)
+		}
+		if err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(&pb.StudentEvent{
+			Type:     eventTypes[ev.Type],
+			Student:  s.toStudentInfo(ev.Student),
+			Revision: ev.Revision,
+			Time:     ev.Time,
+		}); err != nil {
+			return err
+		}
+		last = ev.Revision
+	}
+}
+
+// nextEvent waits for the next event of watcher. With progress set it gives
+// up after progressInterval with errIdle.
+func nextEvent(ctx context.Context, watcher *store.Watcher, progress bool) (store.Event, error) {
+	if !progress {
+		return watcher.Next(ctx)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, progressInterval)
+	defer cancel()
+	ev, err := watcher.Next(waitCtx)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return ev, errIdle
+	}
+	return ev, err
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	pb "mygolangproject/proto"
+	"mygolangproject/store"
+)
+
+func watch(t *testing.T, c pb.ServiceClient, req *pb.WatchRequest) pb.Service_WatchStudentsClient {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	stream, err := c.WatchStudents(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stream
+}
+
+func TestWatchStudentsResume(t *testing.T) {
+	_, c := startServer(t)
+	var ids []string
+	for _, name := range []string{"alice", "bob", "carol"} {
+		ids = append(ids, mustRegister(t, c, name, 20, "SE").Id)
+	}
+	stream := watch(t, c, &pb.WatchRequest{FromRevision: 1})
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(revisionHeader); len(got) != 1 || got[0] != "1" {
+		t.Errorf("%v header %v, want 1", revisionHeader, got)
+	}
+	ids = append(ids, mustRegister(t, c, "dave", 20, "SE").Id)
+	for i, want := range ids[1:] {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type != pb.StudentEvent_CREATED || ev.Revision != int64(i+2) || ev.Student.GetId() != want {
+			t.Errorf("event %v %d %v, want CREATED %d %v", ev.Type, ev.Revision, ev.Student.GetId(), i+2, want)
+		}
+	}
+}
+
+func TestWatchStudentsOutOfRange(t *testing.T) {
+	s, c := startServer(t)
+	s.store = store.NewWatchableStore(store.NewMemoryStore(), 2)
+	for _, name := range []string{"alice", "bob", "carol", "dave"} {
+		mustRegister(t, c, name, 20, "SE")
+	}
+	for _, from := range []int64{1, 5} {
+		_, err := watch(t, c, &pb.WatchRequest{FromRevision: from}).Recv()
+		wantCode(t, err, codes.OutOfRange)
+	}
+	ev, err := watch(t, c, &pb.WatchRequest{FromRevision: 2}).Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Revision != 3 {
+		t.Errorf("resumed at revision %d, want 3", ev.Revision)
+	}
+}
+
+func TestWatchStudentsProgress(t *testing.T) {
+	_, c := startServer(t)
+	mustRegister(t, c, "alice", 20, "SE")
+	stream := watch(t, c, &pb.WatchRequest{ProgressNotify: true})
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != pb.StudentEvent_PROGRESS || ev.Revision != 1 || ev.Student != nil {
+		t.Errorf("idle stream sent %v at revision %d, want PROGRESS at 1", ev.Type, ev.Revision)
+	}
+	mustRegister(t, c, "bob", 20, "SE")
+	if ev, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != pb.StudentEvent_CREATED || ev.Revision != 2 {
+		t.Errorf("got %v at revision %d, want CREATED at 2", ev.Type, ev.Revision)
+	}
+}
+
+// stalledStream is a watch stream whose client stops reading after the
+// first event: later sends block until release is closed.
+type stalledStream struct {
+	grpc.ServerStream
+	ready   chan struct{}
+	first   chan *pb.StudentEvent
+	release chan struct{}
+}
+
+func (s *stalledStream) Context() context.Context { return context.Background() }
+
+// SendHeader is called once the watch is in place.
+func (s *stalledStream) SendHeader(metadata.MD) error {
+	close(s.ready)
+	return nil
+}
+
+func (s *stalledStream) Send(ev *pb.StudentEvent) error {
+	select {
+	case s.first <- ev:
+	default:
+		<-s.release
+	}
+	return nil
+}
+
+func TestWatchStudentsSlowConsumer(t *testing.T) {
+	s, c := startServer(t)
+	// Watchers queue up to twice the buffer.
+	s.store = store.NewWatchableStore(store.NewMemoryStore(), 2)
+	stream := &stalledStream{ready: make(chan struct{}), first: make(chan *pb.StudentEvent, 1), release: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() { done <- s.WatchStudents(&pb.WatchRequest{}, stream) }()
+	<-stream.ready
+	// The first event is taken, the second is being sent and the next five
+	// overflow the queue; none of the writes waits for the stream.
+	for _, name := range []string{"a", "bb", "ccc", "dddd", "eeeee", "ffffff", "ggggggg"} {
+		mustRegister(t, c, name, 20, "SE")
+	}
+	close(stream.release)
+	select {
+	case err := <-done:
+		wantCode(t, err, codes.ResourceExhausted)
+		if !strings.Contains(err.Error(), "resume from revision 2") {
+			t.Errorf("%v does not tell the revision to resume from", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow consumer not disconnected")
+	}
+}
@@ -0,0 +1,180 @@
+// Package idgen generates the ids of new students.
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Generator hands out ids. Implementations must be safe for concurrent use.
+type Generator interface {
+	New() string
+}
+
+// Formats are the names New accepts.
+var Formats = []string{"uuid4", "uuid7", "ulid"}
+
+// New returns the generator of the named format: uuid4 for random UUIDs,
+// uuid7 for UUIDs that sort by creation time and ulid for the shorter,
+// equally sortable ULIDs.
+func New(format string) (Generator, error) {
+	switch format {
+	case "uuid4":
+		return UUIDv4{}, nil
+	case "uuid7":
+		return &UUIDv7{}, nil
+	case "ulid":
+		return &ULID{}, nil
+	}
+	return nil, fmt.Errorf("unknown id format %q, want one of %v", format, Formats)
+}
+
+// Valid reports whether id is written the way the formats write ids: a UUID
+// in lower case with hyphens, of any version, or a ULID. Ids that come from
+// elsewhere, such as imported ones, must pass it.
+func Valid(id string) bool {
+	if u, err := uuid.FromString(id); err == nil {
+		return u.String() == id
+	}
+	if len(id) != 26 || id[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(crockford, id[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// UUIDv4 generates random UUIDs.
+type UUIDv4 struct{}
+
+func (UUIDv4) New() string {
+	return uuid.NewV4().String()
+}
+
+// clock is the millisecond timestamp of the time-ordered formats. It never
+// goes backwards, so the ids of one generator ascend even if the wall clock
+// is set back.
+type clock struct {
+	last int64
+}
+
+func (c *clock) now() int64 {
+	ms := time.Now().UnixNano() / int64(time.Millisecond)
+	if ms < c.last {
+		ms = c.last
+	}
+	return ms
+}
+
+func putTime(b []byte, ms int64) {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], uint64(ms))
+	copy(b, t[2:])
+}
+
+func random(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("idgen: reading random bytes: %v", err))
+	}
+}
+
+// UUIDv7 generates version 7 UUIDs: a millisecond timestamp followed by a
+// 12 bit counter and random bits. The counter starts at a random value below
+// half its range every millisecond, so the ids of one generator ascend
+// strictly.
+type UUIDv7 struct {
+	mux     sync.Mutex
+	clock   clock
+	counter uint16
+}
+
+func (g *UUIDv7) New() string {
+	g.mux.Lock()
+	ms := g.clock.now()
+	switch {
+	case ms > g.clock.last:
+		var c [2]byte
+		random(c[:])
+		g.counter = binary.BigEndian.Uint16(c[:]) & 0x7ff
+	case g.counter == 0xfff:
+		// Out of counter values: borrow the next millisecond.
+		ms++
+		g.counter = 0
+	default:
+		g.counter++
+	}
+	g.clock.last = ms
+	counter := g.counter
+	g.mux.Unlock()
+
+	var u uuid.UUID
+	putTime(u[:6], ms)
+	binary.BigEndian.PutUint16(u[6:8], 0x7000|counter)
+	random(u[8:])
+	u[8] = u[8]&0x3f | 0x80
+	return u.String()
+}
+
+// crockford is the base32 alphabet of ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates ULIDs: a millisecond timestamp and 80 random bits, written
+// as 26 characters of Crockford's base32. Within one millisecond the random
+// part of the previous id is incremented, so the ids ascend strictly.
+type ULID struct {
+	mux   sync.Mutex
+	clock clock
+	last  [16]byte
+}
+
+func (g *ULID) New() string {
+	g.mux.Lock()
+	ms := g.clock.now()
+	if ms > g.clock.last || !increment(g.last[6:]) {
+		if ms == g.clock.last {
+			// The random part overflowed: borrow the next millisecond.
+			ms++
+		}
+		random(g.last[6:])
+	}
+	g.clock.last = ms
+	putTime(g.last[:6], ms)
+	id := g.last
+	g.mux.Unlock()
+	return encodeULID(id)
+}
+
+// increment adds one to the big-endian number b and reports whether it did
+// not overflow.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID writes the 128 bits of id as 26 base32 digits, the first of
+// which holds only the top 3 bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
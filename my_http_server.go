@@ -2,40 +2,65 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"log"
 	"net/http"
-	"regexp"
+	"path"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	pb "mygolangproject/proto"
 )
 
 const (
-	gprcAddress                  = "172.17.0.3:50052"
-	computerScienceAndTechnology = "计算机科学与技术"
-	softwareEngineering          = "软件工程"
+	gprcAddress       = "172.17.0.3:50052"
+	transferTimeout   = 5 * time.Minute
+	transferChunkSize = 32 << 10
 )
 
-var nameCheck = regexp.MustCompile(`^[a-zA-Z]+$`).MatchString
+// forwardedHeaders maps HTTP headers to the gRPC metadata keys they are passed
+// on as: who made a change, which the server records in a student's history,
+// and the idempotency key that lets writes such as /register, /delete and
+// /enroll be retried safely.
+var forwardedHeaders = map[string]string{
+	"X-Actor":         "x-actor",
+	"X-Request-Id":    "x-request-id",
+	"Idempotency-Key": "idempotency-key",
+}
 
-func helloHandlerFunc(name string) string {
-	conn, ctx, cancel := connectWithGrpc()
+func helloHandlerFunc(name string) (string, error) {
+	conn, ctx, cancel := connectWithGrpc(nil)
 	c := pb.NewServiceClient(conn)
 	defer conn.Close()
 	defer cancel()
 
 	r, err := c.SayHello(ctx, &pb.HelloRequest{Name: string(name)})
 	if err != nil {
-		log.Fatalf("could not greet: %v", err)
+		log.Printf("could not greet: %v", err)
+		return "", err
 	}
 	log.Printf("Greeting: %s", r.GetMessage())
-	return r.GetMessage()
+	return r.GetMessage(), nil
+}
+
+// connectWithGrpc dials the gRPC server. The forwardedHeaders of req, if any,
+// are passed on as metadata.
+func connectWithGrpc(req *http.Request) (*grpc.ClientConn, context.Context, context.CancelFunc) {
+	return connectWithGrpcTimeout(req, time.Second)
 }
 
-func connectWithGrpc() (*grpc.ClientConn, context.Context, context.CancelFunc) {
+// connectWithGrpcTimeout is connectWithGrpc for calls that may take longer
+// than a second, such as file transfers.
+func connectWithGrpcTimeout(req *http.Request, timeout time.Duration) (*grpc.ClientConn, context.Context, context.CancelFunc) {
 	// Set up a connection to the server.
 	conn, err := grpc.Dial(gprcAddress, grpc.WithInsecure(), grpc.WithBlock())
 	if err != nil {
@@ -43,76 +68,195 @@ func connectWithGrpc() (*grpc.ClientConn, context.Context, context.CancelFunc) {
 	}
 
 	// Contact the server and print out its response.
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	if req != nil {
+		for header, key := range forwardedHeaders {
+			if v := req.Header.Get(header); v != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, key, v)
+			}
+		}
+	}
 
 	return conn, ctx, cancel
 }
 
 // Hello world, the web server
 func helloHandler(w http.ResponseWriter, req *http.Request) {
-	io.WriteString(w, helloHandlerFunc(req.RemoteAddr))
+	message, err := helloHandlerFunc(req.RemoteAddr)
+	if err != nil {
+		grpcError(w, err)
+		return
+	}
+	io.WriteString(w, message)
+}
+
+// fieldViolation and errorBody are the JSON the gateway answers failed
+// requests with, whether it found the problem itself or the server did. Code
+// is the name of the gRPC status code, such as NotFound.
+type fieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+type errorBody struct {
+	Code            string           `json:"code"`
+	Error           string           `json:"error"`
+	FieldViolations []fieldViolation `json:"fieldViolations,omitempty"`
+	// Resource is the id of the student an ALREADY_EXISTS refers to.
+	Resource string `json:"resource,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, code int, body errorBody) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// formErrors collects the form values that cannot even be passed on to the
+// server, such as an age that is not a number. Everything else is checked by
+// the server.
+type formErrors []fieldViolation
+
+func (f *formErrors) add(field, description string) {
+	log.Printf("%v: %v", field, description)
+	*f = append(*f, fieldViolation{Field: field, Description: description})
+}
+
+// write answers 400 with the collected violations, if there are any, and
+// reports whether it did.
+func (f formErrors) write(w http.ResponseWriter) bool {
+	if len(f) == 0 {
+		return false
+	}
+	writeError(w, http.StatusBadRequest, errorBody{Code: codes.InvalidArgument.String(), Error: "invalid argument", FieldViolations: f})
+	return true
+}
+
+// httpStatus is the HTTP status answering each gRPC status code; codes not
+// listed are answered with 500.
+var httpStatus = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.FailedPrecondition: http.StatusConflict,
+	// The server only aborts conditional writes whose If-Match no longer
+	// holds.
+	codes.Aborted:           http.StatusPreconditionFailed,
+	codes.ResourceExhausted: http.StatusTooManyRequests,
+	codes.Unimplemented:     http.StatusNotImplemented,
+	codes.Unavailable:       http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:  http.StatusGatewayTimeout,
 }
 
-func registerInfoCheck(w http.ResponseWriter, req *http.Request) (bool, string, int, string) {
-	isOk := true
-	name := req.PostFormValue("name")
-	if name == "" || !nameCheck(name) {
-		io.WriteString(w, "name error")
-		log.Print("name error")
-		isOk = false
+// grpcError answers a failed gRPC call with the matching HTTP status and the
+// error as JSON, including the field violations of an INVALID_ARGUMENT and
+// the existing student of an ALREADY_EXISTS.
+func grpcError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code, ok := httpStatus[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
 	}
-	age, err := strconv.Atoi(req.PostFormValue("age"))
-	if err != nil || age < 10 || age > 100 {
-		io.WriteString(w, "age error")
-		log.Print("age error")
-		isOk = false
+	body := errorBody{Code: st.Code().String(), Error: st.Message()}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.FieldViolations {
+				body.FieldViolations = append(body.FieldViolations, fieldViolation{Field: v.Field, Description: v.Description})
+			}
+		}
+		if resource, ok := detail.(*errdetails.ResourceInfo); ok {
+			body.Resource = resource.ResourceName
+		}
 	}
-	profession, res := professionCheck(w, req)
-	if !res {
-		isOk = false
+	writeError(w, code, body)
+}
+
+// formInt32 parses an optional int32 form value like formInt.
+func formInt32(errs *formErrors, req *http.Request, key string) int32 {
+	n, ok := formInt(req, key)
+	if !ok || n != int64(int32(n)) {
+		errs.add(key, "must be a whole number")
 	}
-	return isOk, name, age, profession
+	return int32(n)
 }
 
 func registerHandler(w http.ResponseWriter, req *http.Request) {
-	isOk, name, age, profession := registerInfoCheck(w, req)
-	if !isOk {
-		io.WriteString(w, "register error")
+	var errs formErrors
+	age := formInt32(&errs, req, "age")
+	if errs.write(w) {
 		return
 	}
 
-	conn, ctx, cancel := connectWithGrpc()
+	conn, ctx, cancel := connectWithGrpc(req)
 	c := pb.NewServiceClient(conn)
 	defer conn.Close()
 	defer cancel()
 
-	r, err := c.Register(ctx, &pb.RegisterRequest{Name: name, Age: int32(age), Profession: profession})
+	r, err := c.Register(ctx, &pb.RegisterRequest{
+		Name:       req.PostFormValue("name"),
+		Age:        age,
+		Profession: req.PostFormValue("profession"),
+	})
 	if err != nil {
-		log.Fatalf("could not register: %v", err)
+		log.Printf("could not register: %v", err)
+		grpcError(w, err)
 		return
 	}
-	log.Printf("register: %v success", r.Id)
+	log.Printf("register: %v %v success", r.Id, r.Number)
+	w.Header().Set("X-Student-Number", r.Number)
 	io.WriteString(w, r.Id)
 }
 
 func idCheck(w http.ResponseWriter, req *http.Request) (string, bool) {
-	id := req.PostFormValue("id")
+	var errs formErrors
+	id := req.FormValue("id")
 	if id == "" {
-		log.Printf("id is nil")
-		io.WriteString(w, "query error")
-		return "", false
+		errs.add("id", "is required")
 	}
-	return id, true
+	return id, !errs.write(w)
 }
 
-func professionCheck(w http.ResponseWriter, req *http.Request) (string, bool) {
-	profession := req.PostFormValue("profession")
-	if profession == "" || (profession != computerScienceAndTechnology && profession != softwareEngineering) {
-		log.Print("profession error")
-		io.WriteString(w, "profession error")
-		return "", false
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion returns the version named by the If-Match header. A missing
+// header or "*" makes the write unconditional and yields 0.
+func ifMatchVersion(w http.ResponseWriter, req *http.Request) (int64, bool) {
+	tag := strings.TrimPrefix(req.Header.Get("If-Match"), "W/")
+	if tag == "" || tag == "*" {
+		return 0, true
+	}
+	version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		log.Printf("If-Match error: %v", tag)
+		writeError(w, http.StatusPreconditionFailed, errorBody{
+			Code:  codes.FailedPrecondition.String(),
+			Error: "If-Match must be an ETag of this gateway or *",
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+// replicationLag passes on how far behind its primary the answering server
+// is, when it is a replica.
+func replicationLag(w http.ResponseWriter, header metadata.MD) {
+	if lag := header.Get("x-replication-lag-ms"); len(lag) > 0 {
+		w.Header().Set("X-Replication-Lag-Ms", lag[0])
 	}
-	return profession, true
+}
+
+// professionName is the name a student's profession is shown under. Servers
+// without a profession catalogue send only the profession itself.
+func professionName(studentInfo *pb.StudentInfo) string {
+	if studentInfo.ProfessionName != "" {
+		return studentInfo.ProfessionName
+	}
+	return studentInfo.Profession
 }
 
 func responseStudentInfo(w http.ResponseWriter, studentInfo *pb.StudentInfo) {
@@ -120,7 +264,15 @@ func responseStudentInfo(w http.ResponseWriter, studentInfo *pb.StudentInfo) {
 		"id: "+studentInfo.Id+
 			" name: "+studentInfo.Name+
 			" age: "+strconv.Itoa(int(studentInfo.Age))+
-			" profession:"+studentInfo.Profession)
+			" profession:"+professionName(studentInfo)+
+			" professionCode: "+studentInfo.Profession+
+			" version: "+strconv.FormatInt(studentInfo.Version, 10))
+	if studentInfo.Number != "" {
+		io.WriteString(w, " number: "+studentInfo.Number)
+	}
+	if studentInfo.DuplicateOf != "" {
+		io.WriteString(w, " duplicateOf: "+studentInfo.DuplicateOf)
+	}
 }
 
 func queryHandler(w http.ResponseWriter, req *http.Request) {
@@ -129,19 +281,27 @@ func queryHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	conn, ctx, cancel := connectWithGrpc()
+	conn, ctx, cancel := connectWithGrpc(req)
 	c := pb.NewServiceClient(conn)
 	defer conn.Close()
 	defer cancel()
 
-	r, err := c.Query(ctx, &pb.StudentInfo{Id: id})
+	var errs formErrors
+	asOf := formTime(&errs, req, "asOf")
+	if errs.write(w) {
+		return
+	}
+	var header metadata.MD
+	r, err := c.Query(ctx, &pb.QueryStudentRequest{Id: id, AsOf: asOf}, grpc.Header(&header))
 	if err != nil {
 		log.Printf("%v", err)
-		io.WriteString(w, "query error")
+		grpcError(w, err)
 		return
 	}
+	replicationLag(w, header)
 	log.Printf("query: %v success", id)
-	io.WriteString(w, "id: "+r.Id+" name: "+r.Name+" age: "+strconv.Itoa(int(r.Age))+" profession:"+r.Profession)
+	w.Header().Set("ETag", etag(r.Version))
+	io.WriteString(w, "id: "+r.Id+" name: "+r.Name+" age: "+strconv.Itoa(int(r.Age))+" profession:"+professionName(r))
 	responseStudentInfo(w, r)
 }
 
@@ -150,20 +310,20 @@ func alterProfessionHandler(w http.ResponseWriter, req *http.Request) {
 	if !res {
 		return
 	}
-	profession, res := professionCheck(w, req)
+	version, res := ifMatchVersion(w, req)
 	if !res {
 		return
 	}
 
-	conn, ctx, cancel := connectWithGrpc()
+	conn, ctx, cancel := connectWithGrpc(req)
 	c := pb.NewServiceClient(conn)
 	defer conn.Close()
 	defer cancel()
 
-	r, err := c.AlterProfession(ctx, &pb.StudentInfo{Id: id, Profession: profession})
+	r, err := c.AlterProfession(ctx, &pb.StudentInfo{Id: id, Profession: req.PostFormValue("profession"), Version: version})
 	if err != nil {
 		log.Printf("%v", err)
-		io.WriteString(w, "alter error")
+		grpcError(w, err)
 		return
 	}
 	log.Printf("alterProfession: %v success", id)
@@ -175,38 +335,814 @@ func deleteHandler(w http.ResponseWriter, req *http.Request) {
 	if !res {
 		return
 	}
+	version, res := ifMatchVersion(w, req)
+	if !res {
+		return
+	}
 
-	conn, ctx, cancel := connectWithGrpc()
+	conn, ctx, cancel := connectWithGrpc(req)
 	c := pb.NewServiceClient(conn)
 	defer conn.Close()
 	defer cancel()
 
-	r, err := c.Delete(ctx, &pb.StudentInfo{Id: id})
+	r, err := c.Delete(ctx, &pb.StudentInfo{Id: id, Version: version})
 	if err != nil {
 		log.Printf("%v", err)
-		io.WriteString(w, "delete error")
+		grpcError(w, err)
 		return
 	}
 	log.Printf("delete student: %v success", id)
 	io.WriteString(w, strconv.FormatBool(r.Res))
 }
 
-func queryListHandler(w http.ResponseWriter, _ *http.Request) {
+// updateInfoCheck returns the fields present in the form together with the
+// matching update mask. An empty duplicateOf marks the student as reviewed.
+func updateInfoCheck(w http.ResponseWriter, req *http.Request) (*pb.StudentInfo, []string, bool) {
+	info := &pb.StudentInfo{}
+	var paths []string
+	var errs formErrors
+	if _, ok := req.PostForm["name"]; ok {
+		info.Name = req.PostFormValue("name")
+		paths = append(paths, "name")
+	}
+	if _, ok := req.PostForm["age"]; ok {
+		info.Age = formInt32(&errs, req, "age")
+		paths = append(paths, "age")
+	}
+	if _, ok := req.PostForm["profession"]; ok {
+		info.Profession = req.PostFormValue("profession")
+		paths = append(paths, "profession")
+	}
+	if _, ok := req.PostForm["duplicateOf"]; ok {
+		info.DuplicateOf = req.PostFormValue("duplicateOf")
+		paths = append(paths, "duplicateOf")
+	}
+	if len(paths) == 0 {
+		errs.add("name", "at least one of name, age, profession and duplicateOf is required")
+	}
+	if errs.write(w) {
+		return nil, nil, false
+	}
+	return info, paths, true
+}
+
+func updateStudentHandler(w http.ResponseWriter, req *http.Request) {
+	id, res := idCheck(w, req)
+	if !res {
+		return
+	}
+	info, paths, res := updateInfoCheck(w, req)
+	if !res {
+		return
+	}
+	info.Id = id
+	if info.Version, res = ifMatchVersion(w, req); !res {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.UpdateStudent(ctx, &pb.UpdateStudentRequest{
+		Student:    info,
+		UpdateMask: &field_mask.FieldMask{Paths: paths},
+	})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("updateStudent: %v %v success", id, paths)
+	w.Header().Set("ETag", etag(r.Version))
+	responseStudentInfo(w, r)
+}
+
+var deletedFilters = map[string]pb.DeletedFilter{
+	"":        pb.DeletedFilter_EXCLUDE_DELETED,
+	"exclude": pb.DeletedFilter_EXCLUDE_DELETED,
+	"include": pb.DeletedFilter_INCLUDE_DELETED,
+	"only":    pb.DeletedFilter_ONLY_DELETED,
+}
+
+var sortKeys = map[string]pb.SortKey{
+	"":             pb.SortKey_CREATE_TIME,
+	"createTime":   pb.SortKey_CREATE_TIME,
+	"modifiedTime": pb.SortKey_MODIFIED_TIME,
+	"name":         pb.SortKey_NAME,
+	"age":          pb.SortKey_AGE,
+}
+
+// formInt parses an optional integer form value; a missing value is zero.
+func formInt(req *http.Request, key string) (int64, bool) {
+	v := req.FormValue(key)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	return n, err == nil
+}
+
+// formTime parses a point in time given as unix seconds, RFC 3339 or a plain
+// date; a date stands for the end of that day in local time.
+func formTime(errs *formErrors, req *http.Request, key string) int64 {
+	v := req.FormValue(key)
+	if v == "" {
+		return 0
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.Unix()
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Unix() - 1
+	}
+	errs.add(key, "must be unix seconds, an RFC 3339 time or a date like 2006-01-02")
+	return 0
+}
+
+func queryRequestFromForm(w http.ResponseWriter, req *http.Request) (*pb.QueryRequest, bool) {
+	var errs formErrors
+	pageSize := formInt32(&errs, req, "pageSize")
+	minAge := formInt32(&errs, req, "minAge")
+	maxAge := formInt32(&errs, req, "maxAge")
+	var times [4]int64
+	for i, key := range []string{"createdFrom", "createdTo", "modifiedFrom", "modifiedTo"} {
+		times[i] = formTime(&errs, req, key)
+	}
+	sortBy, ok := sortKeys[req.FormValue("sortBy")]
+	if !ok {
+		errs.add("sortBy", "must be createTime, modifiedTime, name or age")
+	}
+	deleted, ok := deletedFilters[req.FormValue("deleted")]
+	if !ok {
+		errs.add("deleted", "must be exclude, include or only")
+	}
+	asOf := formTime(&errs, req, "asOf")
+	if errs.write(w) {
+		return nil, false
+	}
+	return &pb.QueryRequest{
+		PageSize:  pageSize,
+		PageToken: req.FormValue("pageToken"),
+		Filter: &pb.StudentFilter{
+			Profession:   req.FormValue("profession"),
+			MinAge:       minAge,
+			MaxAge:       maxAge,
+			NamePrefix:   req.FormValue("namePrefix"),
+			CreatedFrom:  times[0],
+			CreatedTo:    times[1],
+			ModifiedFrom: times[2],
+			ModifiedTo:   times[3],
+			Deleted:      deleted,
+			Flagged:      req.FormValue("flagged") == "true",
+			Number:       req.FormValue("number"),
+		},
+		SortBy:    sortBy,
+		Ascending: req.FormValue("ascending") == "true",
+		AsOf:      asOf,
+	}, true
+}
+
+func restoreHandler(w http.ResponseWriter, req *http.Request) {
+	id, res := idCheck(w, req)
+	if !res {
+		return
+	}
+	version, res := ifMatchVersion(w, req)
+	if !res {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.RestoreStudent(ctx, &pb.StudentInfo{Id: id, Version: version})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("restore student: %v success", id)
+	w.Header().Set("ETag", etag(r.Version))
+	responseStudentInfo(w, r)
+}
+
+func studentInfoText(studentInfo *pb.StudentInfo) string {
+	if studentInfo == nil {
+		return "-"
+	}
+	return "{" + studentInfo.Name + " " + strconv.Itoa(int(studentInfo.Age)) + " " + professionName(studentInfo) +
+		" version " + strconv.FormatInt(studentInfo.Version, 10) + "}"
+}
+
+func historyHandler(w http.ResponseWriter, req *http.Request) {
+	id, res := idCheck(w, req)
+	if !res {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.GetStudentHistory(ctx, &pb.HistoryRequest{Id: id})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("history: %v success", id)
+	for _, e := range r.Entries {
+		io.WriteString(w,
+			time.Unix(e.Time, 0).Format(time.RFC3339)+
+				" "+e.Action+
+				" actor: "+e.Actor+
+				" requestId: "+e.RequestId+
+				" before: "+studentInfoText(e.Before)+
+				" after: "+studentInfoText(e.After))
+		if e.MergedWith != "" {
+			io.WriteString(w, " mergedWith: "+e.MergedWith)
+		}
+		io.WriteString(w, "\n")
+	}
+}
+
+func queryListHandler(w http.ResponseWriter, req *http.Request) {
+	queryRequest, ok := queryRequestFromForm(w, req)
+	if !ok {
+		return
+	}
 
-	conn, ctx, cancel := connectWithGrpc()
+	conn, ctx, cancel := connectWithGrpc(req)
 	c := pb.NewServiceClient(conn)
 	defer conn.Close()
 	defer cancel()
 
-	r, err := c.QueryList(ctx, &pb.QueryRequest{})
+	var header metadata.MD
+	r, err := c.QueryList(ctx, queryRequest, grpc.Header(&header))
 	if err != nil {
 		log.Printf("%v", err)
+		grpcError(w, err)
 		return
 	}
+	replicationLag(w, header)
 	log.Print("query list success")
 	for _, studentInfo := range r.StudentInfo {
 		responseStudentInfo(w, studentInfo)
 	}
+	io.WriteString(w, " total: "+strconv.Itoa(int(r.Total)))
+	if r.NextPageToken != "" {
+		io.WriteString(w, " nextPageToken: "+r.NextPageToken)
+	}
+}
+
+// fileFormats are the values of the format parameter of /export and /import,
+// which are also the file extensions.
+var fileFormats = map[string]pb.FileFormat{
+	"csv":    pb.FileFormat_CSV,
+	"json":   pb.FileFormat_JSON,
+	"ndjson": pb.FileFormat_NDJSON,
+}
+
+var contentTypes = map[pb.FileFormat]string{
+	pb.FileFormat_CSV:    "text/csv; charset=utf-8",
+	pb.FileFormat_JSON:   "application/json",
+	pb.FileFormat_NDJSON: "application/x-ndjson",
+}
+
+// formFormat returns the file format named by the format parameter, or else
+// by the extension of filename; CSV is the default.
+func formFormat(req *http.Request, filename string) (pb.FileFormat, string, bool) {
+	name := req.FormValue("format")
+	if name == "" {
+		name = strings.TrimPrefix(path.Ext(filename), ".")
+	}
+	if name == "" {
+		name = "csv"
+	}
+	format, ok := fileFormats[strings.ToLower(name)]
+	return format, strings.ToLower(name), ok
+}
+
+// exportHandler downloads the students matching the same filter parameters
+// as /queryList.
+func exportHandler(w http.ResponseWriter, req *http.Request) {
+	format, ext, ok := formFormat(req, "")
+	if !ok {
+		var errs formErrors
+		errs.add("format", "must be csv, json or ndjson")
+		errs.write(w)
+		return
+	}
+	queryRequest, ok := queryRequestFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpcTimeout(req, transferTimeout)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	stream, err := c.ExportStudents(ctx, &pb.ExportRequest{Format: format, Filter: queryRequest.Filter})
+	var chunk *pb.FileChunk
+	if err == nil {
+		chunk, err = stream.Recv()
+	}
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="students.`+ext+`"`)
+	for ; err == nil; chunk, err = stream.Recv() {
+		if _, err := w.Write(chunk.Data); err != nil {
+			log.Printf("%v", err)
+			return
+		}
+	}
+	if err != io.EOF {
+		// The download is cut short; the client sees an incomplete file.
+		log.Printf("%v", err)
+		return
+	}
+	log.Print("export success")
+}
+
+// importHandler uploads a file of students, either as the "file" field of a
+// multipart form or as the whole request body. With dryRun=true it only
+// reports what an import would do.
+func importHandler(w http.ResponseWriter, req *http.Request) {
+	body, filename := io.Reader(req.Body), ""
+	if file, header, err := req.FormFile("file"); err == nil {
+		defer file.Close()
+		body, filename = file, header.Filename
+	}
+	format, _, ok := formFormat(req, filename)
+	if !ok {
+		var errs formErrors
+		errs.add("format", "must be csv, json or ndjson")
+		errs.write(w)
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpcTimeout(req, transferTimeout)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	stream, err := c.ImportStudents(ctx)
+	if err == nil {
+		err = stream.Send(&pb.ImportRequest{
+			Format:       format,
+			DryRun:       req.FormValue("dryRun") == "true",
+			AllOrNothing: req.FormValue("allOrNothing") == "true",
+		})
+	}
+	buf := make([]byte, transferChunkSize)
+	for err == nil {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			err = stream.Send(&pb.ImportRequest{Data: append([]byte(nil), buf[:n]...)})
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			err = readErr
+		}
+	}
+	var r *pb.ImportReport
+	if err == nil || err == io.EOF {
+		// Send returns io.EOF once the server has ended the call; the
+		// reason comes with the reply.
+		r, err = stream.CloseAndRecv()
+	}
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("import: %d succeeded, %d failed", r.Succeeded, r.Failed)
+	for _, res := range r.Results {
+		if !res.Success {
+			io.WriteString(w, "row "+strconv.Itoa(int(res.Row))+": "+res.Error+"\n")
+		}
+	}
+	if r.DryRun {
+		io.WriteString(w, "dry run ")
+	}
+	io.WriteString(w, "succeeded: "+strconv.Itoa(int(r.Succeeded))+" failed: "+strconv.Itoa(int(r.Failed)))
+}
+
+func professionText(p *pb.Profession) string {
+	langs := make([]string, 0, len(p.Names))
+	for lang := range p.Names {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	text := "code: " + p.Code
+	for _, lang := range langs {
+		text += " names." + lang + ": " + p.Names[lang]
+	}
+	return text + " active: " + strconv.FormatBool(p.Active) +
+		" capacity: " + strconv.Itoa(int(p.Capacity)) +
+		" enrolled: " + strconv.Itoa(int(p.Enrolled))
+}
+
+// professionFromForm reads a profession from the form. Its names are given as
+// names.<language>, such as names.zh and names.en; a capacity of 0 means no
+// limit.
+func professionFromForm(w http.ResponseWriter, req *http.Request) (*pb.Profession, bool) {
+	req.ParseForm()
+	p := &pb.Profession{Code: req.PostFormValue("code"), Names: map[string]string{}, Active: req.PostFormValue("active") != "false"}
+	for key, values := range req.PostForm {
+		if strings.HasPrefix(key, "names.") && len(values) > 0 {
+			p.Names[strings.TrimPrefix(key, "names.")] = values[0]
+		}
+	}
+	var errs formErrors
+	p.Capacity = formInt32(&errs, req, "capacity")
+	return p, !errs.write(w)
+}
+
+func professionsHandler(w http.ResponseWriter, req *http.Request) {
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.ListProfessions(ctx, &pb.ListProfessionsRequest{ActiveOnly: req.FormValue("activeOnly") == "true"})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	for _, p := range r.Professions {
+		io.WriteString(w, professionText(p)+"\n")
+	}
+}
+
+func createProfessionHandler(w http.ResponseWriter, req *http.Request) {
+	p, ok := professionFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.CreateProfession(ctx, p)
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("create profession: %v success", r.Code)
+	io.WriteString(w, professionText(r))
+}
+
+func updateProfessionHandler(w http.ResponseWriter, req *http.Request) {
+	p, ok := professionFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.UpdateProfession(ctx, p)
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("update profession: %v success", r.Code)
+	io.WriteString(w, professionText(r))
+}
+
+func deleteProfessionHandler(w http.ResponseWriter, req *http.Request) {
+	var errs formErrors
+	code := req.PostFormValue("code")
+	if code == "" {
+		errs.add("code", "is required")
+	}
+	if errs.write(w) {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.DeleteProfession(ctx, &pb.ProfessionRequest{Code: code})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("delete profession: %v success", code)
+	io.WriteString(w, strconv.FormatBool(r.Res))
+}
+
+var mergeRules = map[string]pb.MergeRule{
+	"":         pb.MergeRule_KEEP_SURVIVOR,
+	"survivor": pb.MergeRule_KEEP_SURVIVOR,
+	"newest":   pb.MergeRule_NEWEST,
+}
+
+// mergeHandler merges the student retiredId into the student id. If-Match
+// applies to the survivor, retiredVersion to the retired student; fromRetired
+// lists, separated by commas, the fields to take from the retired student.
+func mergeHandler(w http.ResponseWriter, req *http.Request) {
+	id, res := idCheck(w, req)
+	if !res {
+		return
+	}
+	version, res := ifMatchVersion(w, req)
+	if !res {
+		return
+	}
+	var errs formErrors
+	retiredId := req.FormValue("retiredId")
+	if retiredId == "" {
+		errs.add("retiredId", "is required")
+	}
+	retiredVersion, ok := formInt(req, "retiredVersion")
+	if !ok {
+		errs.add("retiredVersion", "must be a whole number")
+	}
+	rule, ok := mergeRules[req.FormValue("rule")]
+	if !ok {
+		errs.add("rule", "must be survivor or newest")
+	}
+	var fromRetired []string
+	if v := req.FormValue("fromRetired"); v != "" {
+		fromRetired = strings.Split(v, ",")
+	}
+	if errs.write(w) {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.MergeStudents(ctx, &pb.MergeStudentsRequest{
+		SurvivorId:      id,
+		RetiredId:       retiredId,
+		SurvivorVersion: version,
+		RetiredVersion:  retiredVersion,
+		Rule:            rule,
+		FromRetired:     &field_mask.FieldMask{Paths: fromRetired},
+	})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("merge: %v into %v success", retiredId, id)
+	w.Header().Set("ETag", etag(r.Version))
+	responseStudentInfo(w, r)
+}
+
+// duplicatesHandler lists the groups of possible duplicates, one student per
+// line and an empty line after each group.
+func duplicatesHandler(w http.ResponseWriter, req *http.Request) {
+	var errs formErrors
+	maxDistance := formInt32(&errs, req, "maxDistance")
+	if errs.write(w) {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.FindDuplicates(ctx, &pb.FindDuplicatesRequest{
+		Profession:  req.FormValue("profession"),
+		MaxDistance: maxDistance,
+		ExactOnly:   req.FormValue("exactOnly") == "true",
+	})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("duplicates: %d groups", len(r.Groups))
+	for _, g := range r.Groups {
+		for _, studentInfo := range g.Students {
+			responseStudentInfo(w, studentInfo)
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, "groups: "+strconv.Itoa(len(r.Groups)))
+}
+
+func courseText(c *pb.Course) string {
+	return "code: " + c.Code +
+		" title: " + c.Title +
+		" credits: " + strconv.Itoa(int(c.Credits)) +
+		" professions: " + strings.Join(c.Professions, ",") +
+		" capacity: " + strconv.Itoa(int(c.Capacity)) +
+		" enrolled: " + strconv.Itoa(int(c.Enrolled))
+}
+
+// courseFromForm reads a course from the form. The professions it is open to
+// are separated by commas, none meaning every profession; a capacity of 0
+// means no limit.
+func courseFromForm(w http.ResponseWriter, req *http.Request) (*pb.Course, bool) {
+	c := &pb.Course{Code: req.PostFormValue("code"), Title: req.PostFormValue("title")}
+	if v := req.PostFormValue("professions"); v != "" {
+		c.Professions = strings.Split(v, ",")
+	}
+	var errs formErrors
+	c.Credits = formInt32(&errs, req, "credits")
+	c.Capacity = formInt32(&errs, req, "capacity")
+	return c, !errs.write(w)
+}
+
+func coursesHandler(w http.ResponseWriter, req *http.Request) {
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.ListCourses(ctx, &pb.ListCoursesRequest{Profession: req.FormValue("profession")})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	for _, course := range r.Courses {
+		io.WriteString(w, courseText(course)+"\n")
+	}
+}
+
+func createCourseHandler(w http.ResponseWriter, req *http.Request) {
+	course, ok := courseFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.CreateCourse(ctx, course)
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("create course: %v success", r.Code)
+	io.WriteString(w, courseText(r))
+}
+
+func updateCourseHandler(w http.ResponseWriter, req *http.Request) {
+	course, ok := courseFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.UpdateCourse(ctx, course)
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("update course: %v success", r.Code)
+	io.WriteString(w, courseText(r))
+}
+
+func deleteCourseHandler(w http.ResponseWriter, req *http.Request) {
+	var errs formErrors
+	code := req.PostFormValue("code")
+	if code == "" {
+		errs.add("code", "is required")
+	}
+	if errs.write(w) {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.DeleteCourse(ctx, &pb.CourseRequest{Code: code})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("delete course: %v success", code)
+	io.WriteString(w, strconv.FormatBool(r.Res))
+}
+
+func enrollmentText(e *pb.Enrollment) string {
+	return "courseCode: " + e.CourseCode + " studentId: " + e.StudentId + " time: " + strconv.FormatInt(e.Time, 10)
+}
+
+// enrollmentFromForm reads the course code and the id or student number of
+// the student to enroll or drop.
+func enrollmentFromForm(w http.ResponseWriter, req *http.Request) (*pb.EnrollmentRequest, bool) {
+	id, res := idCheck(w, req)
+	if !res {
+		return nil, false
+	}
+	var errs formErrors
+	courseCode := req.FormValue("courseCode")
+	if courseCode == "" {
+		errs.add("courseCode", "is required")
+	}
+	return &pb.EnrollmentRequest{CourseCode: courseCode, StudentId: id}, !errs.write(w)
+}
+
+func enrollHandler(w http.ResponseWriter, req *http.Request) {
+	enrollment, ok := enrollmentFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.Enroll(ctx, enrollment)
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("enroll: %v in %v success", r.StudentId, r.CourseCode)
+	io.WriteString(w, enrollmentText(r))
+}
+
+func dropHandler(w http.ResponseWriter, req *http.Request) {
+	enrollment, ok := enrollmentFromForm(w, req)
+	if !ok {
+		return
+	}
+
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.Drop(ctx, enrollment)
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	log.Printf("drop: %v from %v success", enrollment.StudentId, enrollment.CourseCode)
+	io.WriteString(w, strconv.FormatBool(r.Res))
+}
+
+// enrollmentsHandler lists the students enrolled in courseCode or the courses
+// the student id takes, one enrollment per line.
+func enrollmentsHandler(w http.ResponseWriter, req *http.Request) {
+	conn, ctx, cancel := connectWithGrpc(req)
+	c := pb.NewServiceClient(conn)
+	defer conn.Close()
+	defer cancel()
+
+	r, err := c.ListEnrollments(ctx, &pb.ListEnrollmentsRequest{
+		CourseCode: req.FormValue("courseCode"),
+		StudentId:  req.FormValue("id"),
+	})
+	if err != nil {
+		log.Printf("%v", err)
+		grpcError(w, err)
+		return
+	}
+	for _, e := range r.Enrollments {
+		io.WriteString(w, enrollmentText(e)+"\n")
+	}
 }
 
 func main() {
@@ -214,7 +1150,25 @@ func main() {
 	http.HandleFunc("/register", registerHandler)
 	http.HandleFunc("/query", queryHandler)
 	http.HandleFunc("/alterProfession", alterProfessionHandler)
+	http.HandleFunc("/updateStudent", updateStudentHandler)
 	http.HandleFunc("/delete", deleteHandler)
+	http.HandleFunc("/restore", restoreHandler)
+	http.HandleFunc("/history", historyHandler)
 	http.HandleFunc("/queryList", queryListHandler)
+	http.HandleFunc("/export", exportHandler)
+	http.HandleFunc("/import", importHandler)
+	http.HandleFunc("/professions", professionsHandler)
+	http.HandleFunc("/createProfession", createProfessionHandler)
+	http.HandleFunc("/updateProfession", updateProfessionHandler)
+	http.HandleFunc("/deleteProfession", deleteProfessionHandler)
+	http.HandleFunc("/duplicates", duplicatesHandler)
+	http.HandleFunc("/merge", mergeHandler)
+	http.HandleFunc("/courses", coursesHandler)
+	http.HandleFunc("/createCourse", createCourseHandler)
+	http.HandleFunc("/updateCourse", updateCourseHandler)
+	http.HandleFunc("/deleteCourse", deleteCourseHandler)
+	http.HandleFunc("/enroll", enrollHandler)
+	http.HandleFunc("/drop", dropHandler)
+	http.HandleFunc("/enrollments", enrollmentsHandler)
 	log.Fatal(http.ListenAndServe(":8089", nil))
 }
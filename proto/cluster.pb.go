@@ -0,0 +1,671 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: cluster.proto
+
+package proto
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type ApplyRequest struct {
+	Command              []byte   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ApplyRequest) Reset()         { *m = ApplyRequest{} }
+func (m *ApplyRequest) String() string { return proto.CompactTextString(m) }
+func (*ApplyRequest) ProtoMessage()    {}
+func (*ApplyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{0}
+}
+
+func (m *ApplyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ApplyRequest.Unmarshal(m, b)
+}
+func (m *ApplyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ApplyRequest.Marshal(b, m, deterministic)
+}
+func (m *ApplyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ApplyRequest.Merge(m, src)
+}
+func (m *ApplyRequest) XXX_Size() int {
+	return xxx_messageInfo_ApplyRequest.Size(m)
+}
+func (m *ApplyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ApplyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ApplyRequest proto.InternalMessageInfo
+
+func (m *ApplyRequest) GetCommand() []byte {
+	if m != nil {
+		return m.Command
+	}
+	return nil
+}
+
+type ApplyReply struct {
+	// 日志序号，调用方等待本地应用到该序号后返回
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// 命令执行失败时的错误信息
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ApplyReply) Reset()         { *m = ApplyReply{} }
+func (m *ApplyReply) String() string { return proto.CompactTextString(m) }
+func (*ApplyReply) ProtoMessage()    {}
+func (*ApplyReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{1}
+}
+
+func (m *ApplyReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ApplyReply.Unmarshal(m, b)
+}
+func (m *ApplyReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ApplyReply.Marshal(b, m, deterministic)
+}
+func (m *ApplyReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ApplyReply.Merge(m, src)
+}
+func (m *ApplyReply) XXX_Size() int {
+	return xxx_messageInfo_ApplyReply.Size(m)
+}
+func (m *ApplyReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ApplyReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ApplyReply proto.InternalMessageInfo
+
+func (m *ApplyReply) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *ApplyReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ReadIndexRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadIndexRequest) Reset()         { *m = ReadIndexRequest{} }
+func (m *ReadIndexRequest) String() string { return proto.CompactTextString(m) }
+func (*ReadIndexRequest) ProtoMessage()    {}
+func (*ReadIndexRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{2}
+}
+
+func (m *ReadIndexRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadIndexRequest.Unmarshal(m, b)
+}
+func (m *ReadIndexRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadIndexRequest.Marshal(b, m, deterministic)
+}
+func (m *ReadIndexRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadIndexRequest.Merge(m, src)
+}
+func (m *ReadIndexRequest) XXX_Size() int {
+	return xxx_messageInfo_ReadIndexRequest.Size(m)
+}
+func (m *ReadIndexRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadIndexRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadIndexRequest proto.InternalMessageInfo
+
+type ReadIndexReply struct {
+	Index                uint64   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadIndexReply) Reset()         { *m = ReadIndexReply{} }
+func (m *ReadIndexReply) String() string { return proto.CompactTextString(m) }
+func (*ReadIndexReply) ProtoMessage()    {}
+func (*ReadIndexReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{3}
+}
+
+func (m *ReadIndexReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadIndexReply.Unmarshal(m, b)
+}
+func (m *ReadIndexReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadIndexReply.Marshal(b, m, deterministic)
+}
+func (m *ReadIndexReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadIndexReply.Merge(m, src)
+}
+func (m *ReadIndexReply) XXX_Size() int {
+	return xxx_messageInfo_ReadIndexReply.Size(m)
+}
+func (m *ReadIndexReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadIndexReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadIndexReply proto.InternalMessageInfo
+
+func (m *ReadIndexReply) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+type Member struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RaftAddress          string   `protobuf:"bytes,2,opt,name=raftAddress,proto3" json:"raftAddress,omitempty"`
+	GrpcAddress          string   `protobuf:"bytes,3,opt,name=grpcAddress,proto3" json:"grpcAddress,omitempty"`
+	Leader               bool     `protobuf:"varint,4,opt,name=leader,proto3" json:"leader,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Member) Reset()         { *m = Member{} }
+func (m *Member) String() string { return proto.CompactTextString(m) }
+func (*Member) ProtoMessage()    {}
+func (*Member) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{4}
+}
+
+func (m *Member) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Member.Unmarshal(m, b)
+}
+func (m *Member) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Member.Marshal(b, m, deterministic)
+}
+func (m *Member) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Member.Merge(m, src)
+}
+func (m *Member) XXX_Size() int {
+	return xxx_messageInfo_Member.Size(m)
+}
+func (m *Member) XXX_DiscardUnknown() {
+	xxx_messageInfo_Member.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Member proto.InternalMessageInfo
+
+func (m *Member) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Member) GetRaftAddress() string {
+	if m != nil {
+		return m.RaftAddress
+	}
+	return ""
+}
+
+func (m *Member) GetGrpcAddress() string {
+	if m != nil {
+		return m.GrpcAddress
+	}
+	return ""
+}
+
+func (m *Member) GetLeader() bool {
+	if m != nil {
+		return m.Leader
+	}
+	return false
+}
+
+type JoinRequest struct {
+	Member               *Member  `protobuf:"bytes,1,opt,name=member,proto3" json:"member,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JoinRequest) Reset()         { *m = JoinRequest{} }
+func (m *JoinRequest) String() string { return proto.CompactTextString(m) }
+func (*JoinRequest) ProtoMessage()    {}
+func (*JoinRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{5}
+}
+
+func (m *JoinRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JoinRequest.Unmarshal(m, b)
+}
+func (m *JoinRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JoinRequest.Marshal(b, m, deterministic)
+}
+func (m *JoinRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JoinRequest.Merge(m, src)
+}
+func (m *JoinRequest) XXX_Size() int {
+	return xxx_messageInfo_JoinRequest.Size(m)
+}
+func (m *JoinRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JoinRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JoinRequest proto.InternalMessageInfo
+
+func (m *JoinRequest) GetMember() *Member {
+	if m != nil {
+		return m.Member
+	}
+	return nil
+}
+
+type LeaveRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaveRequest) Reset()         { *m = LeaveRequest{} }
+func (m *LeaveRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaveRequest) ProtoMessage()    {}
+func (*LeaveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{6}
+}
+
+func (m *LeaveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaveRequest.Unmarshal(m, b)
+}
+func (m *LeaveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaveRequest.Marshal(b, m, deterministic)
+}
+func (m *LeaveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaveRequest.Merge(m, src)
+}
+func (m *LeaveRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaveRequest.Size(m)
+}
+func (m *LeaveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaveRequest proto.InternalMessageInfo
+
+func (m *LeaveRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type MembersRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MembersRequest) Reset()         { *m = MembersRequest{} }
+func (m *MembersRequest) String() string { return proto.CompactTextString(m) }
+func (*MembersRequest) ProtoMessage()    {}
+func (*MembersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{7}
+}
+
+func (m *MembersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MembersRequest.Unmarshal(m, b)
+}
+func (m *MembersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MembersRequest.Marshal(b, m, deterministic)
+}
+func (m *MembersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MembersRequest.Merge(m, src)
+}
+func (m *MembersRequest) XXX_Size() int {
+	return xxx_messageInfo_MembersRequest.Size(m)
+}
+func (m *MembersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MembersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MembersRequest proto.InternalMessageInfo
+
+type Members struct {
+	Members              []*Member `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Members) Reset()         { *m = Members{} }
+func (m *Members) String() string { return proto.CompactTextString(m) }
+func (*Members) ProtoMessage()    {}
+func (*Members) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3cfb3b8ec240c376, []int{8}
+}
+
+func (m *Members) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Members.Unmarshal(m, b)
+}
+func (m *Members) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Members.Marshal(b, m, deterministic)
+}
+func (m *Members) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Members.Merge(m, src)
+}
+func (m *Members) XXX_Size() int {
+	return xxx_messageInfo_Members.Size(m)
+}
+func (m *Members) XXX_DiscardUnknown() {
+	xxx_messageInfo_Members.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Members proto.InternalMessageInfo
+
+func (m *Members) GetMembers() []*Member {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ApplyRequest)(nil), "proto.ApplyRequest")
+	proto.RegisterType((*ApplyReply)(nil), "proto.ApplyReply")
+	proto.RegisterType((*ReadIndexRequest)(nil), "proto.ReadIndexRequest")
+	proto.RegisterType((*ReadIndexReply)(nil), "proto.ReadIndexReply")
+	proto.RegisterType((*Member)(nil), "proto.Member")
+	proto.RegisterType((*JoinRequest)(nil), "proto.JoinRequest")
+	proto.RegisterType((*LeaveRequest)(nil), "proto.LeaveRequest")
+	proto.RegisterType((*MembersRequest)(nil), "proto.MembersRequest")
+	proto.RegisterType((*Members)(nil), "proto.Members")
+}
+
+func init() {
+	proto.RegisterFile("cluster.proto", fileDescriptor_3cfb3b8ec240c376)
+}
+
+var fileDescriptor_3cfb3b8ec240c376 = []byte{
+	// 358 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xc1, 0x4e, 0xfa, 0x40,
+	0x10, 0xc6, 0x69, 0xa1, 0xed, 0x9f, 0x01, 0x1a, 0xfe, 0xa3, 0x68, 0xc3, 0xc1, 0x34, 0x9b, 0xa8,
+	0x3d, 0x18, 0x12, 0x51, 0x13, 0x2f, 0x1e, 0x88, 0x07, 0xa3, 0xd1, 0xcb, 0xbe, 0x41, 0xa1, 0xa3,
+	0x69, 0xd2, 0xd2, 0xba, 0x2d, 0x46, 0xde, 0xc2, 0x47, 0x36, 0xed, 0xee, 0x92, 0x02, 0x72, 0xda,
+	0xcc, 0x37, 0xbf, 0xd9, 0xf9, 0xf2, 0x0d, 0x0c, 0x16, 0xc9, 0xaa, 0x28, 0x49, 0x4c, 0x72, 0x91,
+	0x95, 0x19, 0x5a, 0xf5, 0xc3, 0x02, 0xe8, 0xcf, 0xf2, 0x3c, 0x59, 0x73, 0xfa, 0x5c, 0x51, 0x51,
+	0xa2, 0x07, 0xce, 0x22, 0x4b, 0xd3, 0x70, 0x19, 0x79, 0x86, 0x6f, 0x04, 0x7d, 0xae, 0x4b, 0x76,
+	0x0f, 0xa0, 0xc8, 0x3c, 0x59, 0xe3, 0x31, 0x58, 0xf1, 0x32, 0xa2, 0xef, 0x9a, 0xea, 0x70, 0x59,
+	0x54, 0x2a, 0x09, 0x91, 0x09, 0xcf, 0xf4, 0x8d, 0xa0, 0xcb, 0x65, 0xc1, 0x10, 0x86, 0x9c, 0xc2,
+	0xe8, 0xb9, 0x42, 0xd4, 0x1e, 0x76, 0x01, 0x6e, 0x43, 0x3b, 0xf8, 0x23, 0x2b, 0xc1, 0x7e, 0xa3,
+	0x74, 0x4e, 0x02, 0x5d, 0x30, 0x63, 0x69, 0xaa, 0xcb, 0xcd, 0x38, 0x42, 0x1f, 0x7a, 0x22, 0x7c,
+	0x2f, 0x67, 0x51, 0x24, 0xa8, 0x28, 0xd4, 0xc6, 0xa6, 0x54, 0x11, 0x1f, 0x22, 0x5f, 0x68, 0xa2,
+	0x2d, 0x89, 0x86, 0x84, 0x27, 0x60, 0x27, 0x14, 0x46, 0x24, 0xbc, 0x8e, 0x6f, 0x04, 0xff, 0xb8,
+	0xaa, 0xd8, 0x2d, 0xf4, 0x5e, 0xb2, 0x78, 0xa9, 0x43, 0x39, 0x07, 0x3b, 0xad, 0x4d, 0xd4, 0xeb,
+	0x7b, 0xd3, 0x81, 0xcc, 0x70, 0x22, 0x9d, 0x71, 0xd5, 0x64, 0x67, 0xd0, 0x7f, 0xa5, 0xf0, 0x8b,
+	0xf4, 0xd8, 0x8e, 0x63, 0x36, 0x04, 0x57, 0x4e, 0x14, 0x3a, 0x85, 0x29, 0x38, 0x4a, 0xc1, 0x4b,
+	0x70, 0xe4, 0x37, 0x85, 0x67, 0xf8, 0xed, 0xfd, 0x25, 0xba, 0x3b, 0xfd, 0x31, 0xc1, 0x79, 0x94,
+	0xa7, 0xc4, 0x6b, 0xb0, 0xea, 0x9b, 0xe0, 0x91, 0x82, 0x9b, 0xb7, 0x1c, 0xff, 0xdf, 0x16, 0xf3,
+	0x64, 0xcd, 0x5a, 0xf8, 0x00, 0xdd, 0x4d, 0xf0, 0x78, 0xaa, 0x88, 0xdd, 0xf3, 0x8c, 0x47, 0xfb,
+	0x0d, 0x39, 0x7e, 0x05, 0x9d, 0x2a, 0x19, 0x44, 0x05, 0x34, 0x62, 0x1a, 0xbb, 0x5b, 0x8e, 0x0b,
+	0xd6, 0xc2, 0x09, 0x58, 0x75, 0x22, 0x1b, 0x7f, 0xcd, 0x7c, 0xfe, 0xe0, 0xef, 0x00, 0x9e, 0xa8,
+	0xd4, 0x91, 0x8c, 0xb6, 0xfb, 0x07, 0xc7, 0xe6, 0x76, 0x2d, 0xdc, 0xfc, 0x0e, 0x00, 0x7a, 0x5c,
+	0x75, 0xd8, 0xe3, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ClusterClient is the client API for Cluster service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ClusterClient interface {
+	// 在 leader 上提交一条日志，follower 将写操作转发至此
+	Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyReply, error)
+	// 返回 leader 确认领导权后已应用的日志序号，用于线性一致读
+	ReadIndex(ctx context.Context, in *ReadIndexRequest, opts ...grpc.CallOption) (*ReadIndexReply, error)
+	// 加入新节点
+	Join(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*Members, error)
+	// 移除节点
+	Leave(ctx context.Context, in *LeaveRequest, opts ...grpc.CallOption) (*Members, error)
+	// 查询集群成员
+	GetMembers(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (*Members, error)
+}
+
+type clusterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClusterClient(cc grpc.ClientConnInterface) ClusterClient {
+	return &clusterClient{cc}
+}
+
+func (c *clusterClient) Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyReply, error) {
+	out := new(ApplyReply)
+	err := c.cc.Invoke(ctx, "/proto.Cluster/Apply", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) ReadIndex(ctx context.Context, in *ReadIndexRequest, opts ...grpc.CallOption) (*ReadIndexReply, error) {
+	out := new(ReadIndexReply)
+	err := c.cc.Invoke(ctx, "/proto.Cluster/ReadIndex", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) Join(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*Members, error) {
+	out := new(Members)
+	err := c.cc.Invoke(ctx, "/proto.Cluster/Join", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) Leave(ctx context.Context, in *LeaveRequest, opts ...grpc.CallOption) (*Members, error) {
+	out := new(Members)
+	err := c.cc.Invoke(ctx, "/proto.Cluster/Leave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) GetMembers(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (*Members, error) {
+	out := new(Members)
+	err := c.cc.Invoke(ctx, "/proto.Cluster/GetMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClusterServer is the server API for Cluster service.
+type ClusterServer interface {
+	// 在 leader 上提交一条日志，follower 将写操作转发至此
+	Apply(context.Context, *ApplyRequest) (*ApplyReply, error)
+	// 返回 leader 确认领导权后已应用的日志序号，用于线性一致读
+	ReadIndex(context.Context, *ReadIndexRequest) (*ReadIndexReply, error)
+	// 加入新节点
+	Join(context.Context, *JoinRequest) (*Members, error)
+	// 移除节点
+	Leave(context.Context, *LeaveRequest) (*Members, error)
+	// 查询集群成员
+	GetMembers(context.Context, *MembersRequest) (*Members, error)
+}
+
+// UnimplementedClusterServer can be embedded to have forward compatible implementations.
+type UnimplementedClusterServer struct {
+}
+
+func (*UnimplementedClusterServer) Apply(ctx context.Context, req *ApplyRequest) (*ApplyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Apply not implemented")
+}
+func (*UnimplementedClusterServer) ReadIndex(ctx context.Context, req *ReadIndexRequest) (*ReadIndexReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadIndex not implemented")
+}
+func (*UnimplementedClusterServer) Join(ctx context.Context, req *JoinRequest) (*Members, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Join not implemented")
+}
+func (*UnimplementedClusterServer) Leave(ctx context.Context, req *LeaveRequest) (*Members, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Leave not implemented")
+}
+func (*UnimplementedClusterServer) GetMembers(ctx context.Context, req *MembersRequest) (*Members, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMembers not implemented")
+}
+
+func RegisterClusterServer(s *grpc.Server, srv ClusterServer) {
+	s.RegisterService(&_Cluster_serviceDesc, srv)
+}
+
+func _Cluster_Apply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Apply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Cluster/Apply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Apply(ctx, req.(*ApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_ReadIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).ReadIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Cluster/ReadIndex",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).ReadIndex(ctx, req.(*ReadIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_Join_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Join(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Cluster/Join",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Join(ctx, req.(*JoinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_Leave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Leave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Cluster/Leave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Leave(ctx, req.(*LeaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_GetMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).GetMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Cluster/GetMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).GetMembers(ctx, req.(*MembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Cluster_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Cluster",
+	HandlerType: (*ClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Apply",
+			Handler:    _Cluster_Apply_Handler,
+		},
+		{
+			MethodName: "ReadIndex",
+			Handler:    _Cluster_ReadIndex_Handler,
+		},
+		{
+			MethodName: "Join",
+			Handler:    _Cluster_Join_Handler,
+		},
+		{
+			MethodName: "Leave",
+			Handler:    _Cluster_Leave_Handler,
+		},
+		{
+			MethodName: "GetMembers",
+			Handler:    _Cluster_GetMembers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cluster.proto",
+}
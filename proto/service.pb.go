@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	field_mask "google.golang.org/genproto/protobuf/field_mask"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -24,6 +25,165 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type SortKey int32
+
+const (
+	SortKey_CREATE_TIME   SortKey = 0
+	SortKey_MODIFIED_TIME SortKey = 1
+	SortKey_NAME          SortKey = 2
+	SortKey_AGE           SortKey = 3
+)
+
+var SortKey_name = map[int32]string{
+	0: "CREATE_TIME",
+	1: "MODIFIED_TIME",
+	2: "NAME",
+	3: "AGE",
+}
+
+var SortKey_value = map[string]int32{
+	"CREATE_TIME":   0,
+	"MODIFIED_TIME": 1,
+	"NAME":          2,
+	"AGE":           3,
+}
+
+func (x SortKey) String() string {
+	return proto.EnumName(SortKey_name, int32(x))
+}
+
+func (SortKey) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{0}
+}
+
+type DeletedFilter int32
+
+const (
+	DeletedFilter_EXCLUDE_DELETED DeletedFilter = 0
+	DeletedFilter_INCLUDE_DELETED DeletedFilter = 1
+	DeletedFilter_ONLY_DELETED    DeletedFilter = 2
+)
+
+var DeletedFilter_name = map[int32]string{
+	0: "EXCLUDE_DELETED",
+	1: "INCLUDE_DELETED",
+	2: "ONLY_DELETED",
+}
+
+var DeletedFilter_value = map[string]int32{
+	"EXCLUDE_DELETED": 0,
+	"INCLUDE_DELETED": 1,
+	"ONLY_DELETED":    2,
+}
+
+func (x DeletedFilter) String() string {
+	return proto.EnumName(DeletedFilter_name, int32(x))
+}
+
+func (DeletedFilter) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{1}
+}
+
+// 导入导出的文件格式，CSV 带 UTF-8 BOM，NDJSON 每行一个学生
+type FileFormat int32
+
+const (
+	FileFormat_CSV    FileFormat = 0
+	FileFormat_JSON   FileFormat = 1
+	FileFormat_NDJSON FileFormat = 2
+)
+
+var FileFormat_name = map[int32]string{
+	0: "CSV",
+	1: "JSON",
+	2: "NDJSON",
+}
+
+var FileFormat_value = map[string]int32{
+	"CSV":    0,
+	"JSON":   1,
+	"NDJSON": 2,
+}
+
+func (x FileFormat) String() string {
+	return proto.EnumName(FileFormat_name, int32(x))
+}
+
+func (FileFormat) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{2}
+}
+
+// 合并时姓名、年龄、专业的取值规则
+type MergeRule int32
+
+const (
+	// 保留 survivor 的值
+	MergeRule_KEEP_SURVIVOR MergeRule = 0
+	// 取最后修改的一方的值
+	MergeRule_NEWEST MergeRule = 1
+)
+
+var MergeRule_name = map[int32]string{
+	0: "KEEP_SURVIVOR",
+	1: "NEWEST",
+}
+
+var MergeRule_value = map[string]int32{
+	"KEEP_SURVIVOR": 0,
+	"NEWEST":        1,
+}
+
+func (x MergeRule) String() string {
+	return proto.EnumName(MergeRule_name, int32(x))
+}
+
+func (MergeRule) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{3}
+}
+
+type StudentEvent_EventType int32
+
+const (
+	StudentEvent_UNKNOWN StudentEvent_EventType = 0
+	StudentEvent_CREATED StudentEvent_EventType = 1
+	StudentEvent_UPDATED StudentEvent_EventType = 2
+	// 软删除
+	StudentEvent_DELETED  StudentEvent_EventType = 3
+	StudentEvent_RESTORED StudentEvent_EventType = 4
+	// 彻底删除
+	StudentEvent_PURGED StudentEvent_EventType = 5
+	// 进度通知，不带学生，revision 为此前已推送的最新版本
+	StudentEvent_PROGRESS StudentEvent_EventType = 6
+)
+
+var StudentEvent_EventType_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "CREATED",
+	2: "UPDATED",
+	3: "DELETED",
+	4: "RESTORED",
+	5: "PURGED",
+	6: "PROGRESS",
+}
+
+var StudentEvent_EventType_value = map[string]int32{
+	"UNKNOWN":  0,
+	"CREATED":  1,
+	"UPDATED":  2,
+	"DELETED":  3,
+	"RESTORED": 4,
+	"PURGED":   5,
+	"PROGRESS": 6,
+}
+
+func (x StudentEvent_EventType) String() string {
+	return proto.EnumName(StudentEvent_EventType_name, int32(x))
+}
+
+func (StudentEvent_EventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{11, 0}
+}
+
 // The request message containing the user's name(addr).
 type HelloRequest struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -201,12 +361,27 @@ func (m *Result) GetRes() bool {
 
 // The register message containing the student info.
 type StudentInfo struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Age                  int32    `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
-	Profession           string   `protobuf:"bytes,4,opt,name=profession,proto3" json:"profession,omitempty"`
-	CreateTime           int64    `protobuf:"varint,5,opt,name=createTime,proto3" json:"createTime,omitempty"`
-	ModifiedTime         int64    `protobuf:"varint,6,opt,name=modifiedTime,proto3" json:"modifiedTime,omitempty"`
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Age  int32  `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
+	// 专业代码，如 SE；写入时也可以给专业名称
+	Profession string `protobuf:"bytes,4,opt,name=profession,proto3" json:"profession,omitempty"`
+	// 创建与修改时间，unix 秒
+	CreateTime   int64 `protobuf:"varint,5,opt,name=createTime,proto3" json:"createTime,omitempty"`
+	ModifiedTime int64 `protobuf:"varint,6,opt,name=modifiedTime,proto3" json:"modifiedTime,omitempty"`
+	// 记录版本，写操作携带非 0 版本时仅在版本一致时生效
+	Version int64 `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	// 软删除时间，0 表示未删除
+	DeletedTime int64 `protobuf:"varint,8,opt,name=deletedTime,proto3" json:"deletedTime,omitempty"`
+	// 纳秒精度的创建与修改时间，列表按其排序
+	CreateTimeNanos   int64 `protobuf:"varint,9,opt,name=createTimeNanos,proto3" json:"createTimeNanos,omitempty"`
+	ModifiedTimeNanos int64 `protobuf:"varint,10,opt,name=modifiedTimeNanos,proto3" json:"modifiedTimeNanos,omitempty"`
+	// 注册时被标记为疑似重复时，为已有学生的 id，待人工复核；复核后可通过 UpdateStudent 清除
+	DuplicateOf string `protobuf:"bytes,11,opt,name=duplicateOf,proto3" json:"duplicateOf,omitempty"`
+	// 学号，如 2026-SE-0042，按注册年份与专业顺序编号
+	Number string `protobuf:"bytes,12,opt,name=number,proto3" json:"number,omitempty"`
+	// 专业在目录中的中文名称，如 软件工程，只读
+	ProfessionName       string   `protobuf:"bytes,13,opt,name=professionName,proto3" json:"professionName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -279,9 +454,109 @@ func (m *StudentInfo) GetModifiedTime() int64 {
 	return 0
 }
 
+func (m *StudentInfo) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *StudentInfo) GetDeletedTime() int64 {
+	if m != nil {
+		return m.DeletedTime
+	}
+	return 0
+}
+
+func (m *StudentInfo) GetCreateTimeNanos() int64 {
+	if m != nil {
+		return m.CreateTimeNanos
+	}
+	return 0
+}
+
+func (m *StudentInfo) GetModifiedTimeNanos() int64 {
+	if m != nil {
+		return m.ModifiedTimeNanos
+	}
+	return 0
+}
+
+func (m *StudentInfo) GetDuplicateOf() string {
+	if m != nil {
+		return m.DuplicateOf
+	}
+	return ""
+}
+
+func (m *StudentInfo) GetNumber() string {
+	if m != nil {
+		return m.Number
+	}
+	return ""
+}
+
+func (m *StudentInfo) GetProfessionName() string {
+	if m != nil {
+		return m.ProfessionName
+	}
+	return ""
+}
+
+// 字段编号与 StudentInfo 保持一致，旧客户端发送的 StudentInfo 仍可解析
+type QueryStudentRequest struct {
+	// 学生 id，也可以是学号
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// 非 0 时按修改历史还原该时刻（unix 秒）的学生信息
+	AsOf                 int64    `protobuf:"varint,9,opt,name=asOf,proto3" json:"asOf,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryStudentRequest) Reset()         { *m = QueryStudentRequest{} }
+func (m *QueryStudentRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryStudentRequest) ProtoMessage()    {}
+func (*QueryStudentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{5}
+}
+
+func (m *QueryStudentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStudentRequest.Unmarshal(m, b)
+}
+func (m *QueryStudentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStudentRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryStudentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStudentRequest.Merge(m, src)
+}
+func (m *QueryStudentRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryStudentRequest.Size(m)
+}
+func (m *QueryStudentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStudentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStudentRequest proto.InternalMessageInfo
+
+func (m *QueryStudentRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *QueryStudentRequest) GetAsOf() int64 {
+	if m != nil {
+		return m.AsOf
+	}
+	return 0
+}
+
 // The response message
 type RegisterReply struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number               string   `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -291,7 +566,7 @@ func (m *RegisterReply) Reset()         { *m = RegisterReply{} }
 func (m *RegisterReply) String() string { return proto.CompactTextString(m) }
 func (*RegisterReply) ProtoMessage()    {}
 func (*RegisterReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a0b84a42fa06f626, []int{5}
+	return fileDescriptor_a0b84a42fa06f626, []int{6}
 }
 
 func (m *RegisterReply) XXX_Unmarshal(b []byte) error {
@@ -319,19 +594,30 @@ func (m *RegisterReply) GetId() string {
 	return ""
 }
 
+func (m *RegisterReply) GetNumber() string {
+	if m != nil {
+		return m.Number
+	}
+	return ""
+}
+
 // 所有学生的信息
 type StudentList struct {
-	StudentInfo          []*StudentInfo `protobuf:"bytes,1,rep,name=studentInfo,proto3" json:"studentInfo,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	StudentInfo []*StudentInfo `protobuf:"bytes,1,rep,name=studentInfo,proto3" json:"studentInfo,omitempty"`
+	// 符合过滤条件的学生总数
+	Total int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// 为空表示没有下一页
+	NextPageToken        string   `protobuf:"bytes,3,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *StudentList) Reset()         { *m = StudentList{} }
 func (m *StudentList) String() string { return proto.CompactTextString(m) }
 func (*StudentList) ProtoMessage()    {}
 func (*StudentList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a0b84a42fa06f626, []int{6}
+	return fileDescriptor_a0b84a42fa06f626, []int{7}
 }
 
 func (m *StudentList) XXX_Unmarshal(b []byte) error {
@@ -359,316 +645,3047 @@ func (m *StudentList) GetStudentInfo() []*StudentInfo {
 	return nil
 }
 
-type QueryRequest struct {
+func (m *StudentList) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *StudentList) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+// 过滤条件，零值表示不限制（默认不含已删除学生），范围均为闭区间
+type StudentFilter struct {
+	Profession   string        `protobuf:"bytes,1,opt,name=profession,proto3" json:"profession,omitempty"`
+	MinAge       int32         `protobuf:"varint,2,opt,name=minAge,proto3" json:"minAge,omitempty"`
+	MaxAge       int32         `protobuf:"varint,3,opt,name=maxAge,proto3" json:"maxAge,omitempty"`
+	NamePrefix   string        `protobuf:"bytes,4,opt,name=namePrefix,proto3" json:"namePrefix,omitempty"`
+	CreatedFrom  int64         `protobuf:"varint,5,opt,name=createdFrom,proto3" json:"createdFrom,omitempty"`
+	CreatedTo    int64         `protobuf:"varint,6,opt,name=createdTo,proto3" json:"createdTo,omitempty"`
+	ModifiedFrom int64         `protobuf:"varint,7,opt,name=modifiedFrom,proto3" json:"modifiedFrom,omitempty"`
+	ModifiedTo   int64         `protobuf:"varint,8,opt,name=modifiedTo,proto3" json:"modifiedTo,omitempty"`
+	Deleted      DeletedFilter `protobuf:"varint,9,opt,name=deleted,proto3,enum=proto.DeletedFilter" json:"deleted,omitempty"`
+	// 只返回被标记为疑似重复的学生
+	Flagged              bool     `protobuf:"varint,10,opt,name=flagged,proto3" json:"flagged,omitempty"`
+	Number               string   `protobuf:"bytes,11,opt,name=number,proto3" json:"number,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *QueryRequest) Reset()         { *m = QueryRequest{} }
-func (m *QueryRequest) String() string { return proto.CompactTextString(m) }
-func (*QueryRequest) ProtoMessage()    {}
-func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a0b84a42fa06f626, []int{7}
+func (m *StudentFilter) Reset()         { *m = StudentFilter{} }
+func (m *StudentFilter) String() string { return proto.CompactTextString(m) }
+func (*StudentFilter) ProtoMessage()    {}
+func (*StudentFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{8}
 }
 
-func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_QueryRequest.Unmarshal(m, b)
+func (m *StudentFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StudentFilter.Unmarshal(m, b)
 }
-func (m *QueryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_QueryRequest.Marshal(b, m, deterministic)
+func (m *StudentFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StudentFilter.Marshal(b, m, deterministic)
 }
-func (m *QueryRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_QueryRequest.Merge(m, src)
+func (m *StudentFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StudentFilter.Merge(m, src)
 }
-func (m *QueryRequest) XXX_Size() int {
-	return xxx_messageInfo_QueryRequest.Size(m)
+func (m *StudentFilter) XXX_Size() int {
+	return xxx_messageInfo_StudentFilter.Size(m)
 }
-func (m *QueryRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_QueryRequest.DiscardUnknown(m)
+func (m *StudentFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_StudentFilter.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_QueryRequest proto.InternalMessageInfo
+var xxx_messageInfo_StudentFilter proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*HelloRequest)(nil), "proto.HelloRequest")
-	proto.RegisterType((*HelloReply)(nil), "proto.HelloReply")
-	proto.RegisterType((*RegisterRequest)(nil), "proto.RegisterRequest")
-	proto.RegisterType((*Result)(nil), "proto.Result")
-	proto.RegisterType((*StudentInfo)(nil), "proto.StudentInfo")
-	proto.RegisterType((*RegisterReply)(nil), "proto.RegisterReply")
-	proto.RegisterType((*StudentList)(nil), "proto.StudentList")
-	proto.RegisterType((*QueryRequest)(nil), "proto.QueryRequest")
+func (m *StudentFilter) GetProfession() string {
+	if m != nil {
+		return m.Profession
+	}
+	return ""
 }
 
-func init() {
-	proto.RegisterFile("service.proto", fileDescriptor_a0b84a42fa06f626)
+func (m *StudentFilter) GetMinAge() int32 {
+	if m != nil {
+		return m.MinAge
+	}
+	return 0
 }
 
-var fileDescriptor_a0b84a42fa06f626 = []byte{
-	// 398 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xf5, 0x47, 0xed, 0xa6, 0x93, 0xa4, 0x85, 0x01, 0x21, 0xcb, 0x07, 0xb0, 0xf6, 0x80, 0x7c,
-	0xa1, 0x12, 0xa5, 0xea, 0x81, 0x1b, 0x82, 0x03, 0x48, 0x1c, 0x60, 0x83, 0xc4, 0xd9, 0xd4, 0x93,
-	0x68, 0x25, 0x7f, 0xb1, 0xbb, 0x46, 0xf2, 0x2f, 0xe2, 0xd7, 0xf0, 0x9f, 0xd0, 0x6e, 0xec, 0xc4,
-	0x71, 0x02, 0xea, 0xc9, 0xe3, 0xe7, 0x37, 0x33, 0x6f, 0xde, 0x33, 0x2c, 0x15, 0xc9, 0x5f, 0xe2,
-	0x9e, 0xae, 0x1b, 0x59, 0xeb, 0x1a, 0x03, 0xfb, 0x60, 0x0c, 0x16, 0x1f, 0xa9, 0x28, 0x6a, 0x4e,
-	0x3f, 0x5b, 0x52, 0x1a, 0x11, 0xce, 0xaa, 0xac, 0xa4, 0xc8, 0x4d, 0xdc, 0xf4, 0x82, 0xdb, 0x9a,
-	0xbd, 0x04, 0xe8, 0x39, 0x4d, 0xd1, 0x61, 0x04, 0xe7, 0x25, 0x29, 0x95, 0x6d, 0x06, 0xd2, 0xf0,
-	0xca, 0xbe, 0xc3, 0x15, 0xa7, 0x8d, 0x50, 0x9a, 0xe4, 0x7f, 0xc6, 0xe1, 0x23, 0xf0, 0x4d, 0xb3,
-	0x97, 0xb8, 0x69, 0xc0, 0x4d, 0x89, 0xcf, 0x01, 0x1a, 0x59, 0xaf, 0x49, 0x29, 0x51, 0x57, 0x91,
-	0x6f, 0xb9, 0x23, 0x84, 0xc5, 0x10, 0x72, 0x52, 0x6d, 0xa1, 0x4d, 0xaf, 0x24, 0x65, 0xc7, 0xcd,
-	0xb8, 0x29, 0xd9, 0x6f, 0x17, 0xe6, 0x2b, 0xdd, 0xe6, 0x54, 0xe9, 0x4f, 0xd5, 0xba, 0xc6, 0x4b,
-	0xf0, 0x44, 0xde, 0xef, 0xf3, 0x44, 0xbe, 0x53, 0xe0, 0x1d, 0x2b, 0xf0, 0xff, 0xa5, 0xe0, 0x6c,
-	0xaa, 0xc0, 0x7c, 0xbf, 0x97, 0x94, 0x69, 0xfa, 0x26, 0x4a, 0x8a, 0x82, 0xc4, 0x4d, 0x7d, 0x3e,
-	0x42, 0x90, 0xc1, 0xa2, 0xac, 0x73, 0xb1, 0x16, 0x94, 0x5b, 0x46, 0x68, 0x19, 0x07, 0x18, 0x7b,
-	0x01, 0xcb, 0xbd, 0x3d, 0xc6, 0xc9, 0x89, 0x54, 0xf6, 0x7e, 0x77, 0xc9, 0x67, 0xa1, 0x34, 0xde,
-	0xc2, 0x5c, 0xed, 0x0f, 0x8b, 0xdc, 0xc4, 0x4f, 0xe7, 0x37, 0xb8, 0x8d, 0xef, 0x7a, 0x74, 0x32,
-	0x1f, 0xd3, 0xd8, 0x25, 0x2c, 0xbe, 0xb6, 0x24, 0xbb, 0x3e, 0x81, 0x9b, 0x3f, 0x1e, 0x9c, 0xaf,
-	0xb6, 0xc9, 0xe3, 0x2d, 0xcc, 0x56, 0x59, 0x67, 0xb3, 0xc4, 0x27, 0xfd, 0xa0, 0x71, 0xfa, 0xf1,
-	0xe3, 0x43, 0xb0, 0x29, 0x3a, 0xe6, 0xe0, 0x5b, 0x98, 0x0d, 0xba, 0xf1, 0x59, 0x4f, 0x98, 0xe4,
-	0x1c, 0x3f, 0x3d, 0xc2, 0xb7, 0xbd, 0xaf, 0x21, 0xb0, 0x6a, 0xf0, 0x84, 0xee, 0xf8, 0x04, 0xc6,
-	0x1c, 0xbc, 0x83, 0xab, 0x77, 0x85, 0x26, 0xf9, 0x65, 0xef, 0xfe, 0xa9, 0xe6, 0xe5, 0x6e, 0xa3,
-	0xf9, 0x31, 0x98, 0x83, 0xaf, 0x20, 0xfc, 0x40, 0x05, 0x69, 0x7a, 0x18, 0xfd, 0x0e, 0x2e, 0xac,
-	0x32, 0x6b, 0xf5, 0x60, 0xc6, 0xd8, 0xb9, 0xa9, 0x3c, 0x43, 0x64, 0xce, 0x8f, 0xd0, 0x82, 0x6f,
-	0xfe, 0x06, 0x00, 0x00, 0xff, 0xff, 0xbf, 0x1d, 0x13, 0xb6, 0x4f, 0x03, 0x00, 0x00,
+func (m *StudentFilter) GetMaxAge() int32 {
+	if m != nil {
+		return m.MaxAge
+	}
+	return 0
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConnInterface
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion6
-
-// ServiceClient is the client API for Service service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ServiceClient interface {
-	// Sends a greeting
-	SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error)
-	// 创建学生信息
-	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterReply, error)
-	//查询学生信息
-	Query(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*StudentInfo, error)
-	//修改学生专业
-	AlterProfession(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error)
-	//删除学生
-	Delete(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error)
-	//查询所有学生信息
-	QueryList(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*StudentList, error)
+func (m *StudentFilter) GetNamePrefix() string {
+	if m != nil {
+		return m.NamePrefix
+	}
+	return ""
 }
 
-type serviceClient struct {
-	cc grpc.ClientConnInterface
+func (m *StudentFilter) GetCreatedFrom() int64 {
+	if m != nil {
+		return m.CreatedFrom
+	}
+	return 0
 }
 
-func NewServiceClient(cc grpc.ClientConnInterface) ServiceClient {
-	return &serviceClient{cc}
+func (m *StudentFilter) GetCreatedTo() int64 {
+	if m != nil {
+		return m.CreatedTo
+	}
+	return 0
 }
 
-func (c *serviceClient) SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error) {
-	out := new(HelloReply)
-	err := c.cc.Invoke(ctx, "/proto.Service/SayHello", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *StudentFilter) GetModifiedFrom() int64 {
+	if m != nil {
+		return m.ModifiedFrom
 	}
-	return out, nil
+	return 0
 }
 
-func (c *serviceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterReply, error) {
-	out := new(RegisterReply)
-	err := c.cc.Invoke(ctx, "/proto.Service/Register", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *StudentFilter) GetModifiedTo() int64 {
+	if m != nil {
+		return m.ModifiedTo
 	}
-	return out, nil
+	return 0
 }
 
-func (c *serviceClient) Query(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*StudentInfo, error) {
-	out := new(StudentInfo)
-	err := c.cc.Invoke(ctx, "/proto.Service/Query", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *StudentFilter) GetDeleted() DeletedFilter {
+	if m != nil {
+		return m.Deleted
 	}
-	return out, nil
+	return DeletedFilter_EXCLUDE_DELETED
 }
 
-func (c *serviceClient) AlterProfession(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error) {
-	out := new(Result)
-	err := c.cc.Invoke(ctx, "/proto.Service/AlterProfession", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *StudentFilter) GetFlagged() bool {
+	if m != nil {
+		return m.Flagged
 	}
-	return out, nil
+	return false
 }
 
-func (c *serviceClient) Delete(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error) {
-	out := new(Result)
-	err := c.cc.Invoke(ctx, "/proto.Service/Delete", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *StudentFilter) GetNumber() string {
+	if m != nil {
+		return m.Number
+	}
+	return ""
+}
+
+type QueryRequest struct {
+	// 每页数量，0 表示返回全部
+	PageSize int32 `protobuf:"varint,1,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	// 上一页返回的 nextPageToken，需与本次的过滤与排序条件一致
+	PageToken string         `protobuf:"bytes,2,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	Filter    *StudentFilter `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	SortBy    SortKey        `protobuf:"varint,4,opt,name=sortBy,proto3,enum=proto.SortKey" json:"sortBy,omitempty"`
+	// 默认降序
+	Ascending bool `protobuf:"varint,5,opt,name=ascending,proto3" json:"ascending,omitempty"`
+	// 非 0 时按修改历史还原该时刻（unix 秒）的学生列表
+	AsOf                 int64    `protobuf:"varint,6,opt,name=asOf,proto3" json:"asOf,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryRequest) Reset()         { *m = QueryRequest{} }
+func (m *QueryRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryRequest) ProtoMessage()    {}
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{9}
+}
+
+func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryRequest.Unmarshal(m, b)
+}
+func (m *QueryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryRequest.Merge(m, src)
+}
+func (m *QueryRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryRequest.Size(m)
+}
+func (m *QueryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryRequest proto.InternalMessageInfo
+
+func (m *QueryRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *QueryRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *QueryRequest) GetFilter() *StudentFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *QueryRequest) GetSortBy() SortKey {
+	if m != nil {
+		return m.SortBy
+	}
+	return SortKey_CREATE_TIME
+}
+
+func (m *QueryRequest) GetAscending() bool {
+	if m != nil {
+		return m.Ascending
+	}
+	return false
+}
+
+func (m *QueryRequest) GetAsOf() int64 {
+	if m != nil {
+		return m.AsOf
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	// 从该版本之后的事件开始推送（用于断线续传），0 表示只推送新事件
+	FromRevision int64 `protobuf:"varint,1,opt,name=fromRevision,proto3" json:"fromRevision,omitempty"`
+	// 空闲时定期推送 PROGRESS 事件，告知已推送到的版本
+	ProgressNotify       bool     `protobuf:"varint,2,opt,name=progressNotify,proto3" json:"progressNotify,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{10}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetFromRevision() int64 {
+	if m != nil {
+		return m.FromRevision
+	}
+	return 0
+}
+
+func (m *WatchRequest) GetProgressNotify() bool {
+	if m != nil {
+		return m.ProgressNotify
+	}
+	return false
+}
+
+// 学生变更事件
+type StudentEvent struct {
+	Type     StudentEvent_EventType `protobuf:"varint,1,opt,name=type,proto3,enum=proto.StudentEvent_EventType" json:"type,omitempty"`
+	Student  *StudentInfo           `protobuf:"bytes,2,opt,name=student,proto3" json:"student,omitempty"`
+	Revision int64                  `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	// 事件发生的时间，unix 纳秒
+	Time                 int64    `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StudentEvent) Reset()         { *m = StudentEvent{} }
+func (m *StudentEvent) String() string { return proto.CompactTextString(m) }
+func (*StudentEvent) ProtoMessage()    {}
+func (*StudentEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{11}
+}
+
+func (m *StudentEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StudentEvent.Unmarshal(m, b)
+}
+func (m *StudentEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StudentEvent.Marshal(b, m, deterministic)
+}
+func (m *StudentEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StudentEvent.Merge(m, src)
+}
+func (m *StudentEvent) XXX_Size() int {
+	return xxx_messageInfo_StudentEvent.Size(m)
+}
+func (m *StudentEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_StudentEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StudentEvent proto.InternalMessageInfo
+
+func (m *StudentEvent) GetType() StudentEvent_EventType {
+	if m != nil {
+		return m.Type
+	}
+	return StudentEvent_UNKNOWN
+}
+
+func (m *StudentEvent) GetStudent() *StudentInfo {
+	if m != nil {
+		return m.Student
+	}
+	return nil
+}
+
+func (m *StudentEvent) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *StudentEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type BulkRegisterRequest struct {
+	Student *RegisterRequest `protobuf:"bytes,1,opt,name=student,proto3" json:"student,omitempty"`
+	// 先校验全部行，再一次性原子写入：全部成功或全部不写入，以第一条消息为准。
+	// 此模式下超过行数上限的请求整体被拒绝；失败时已分配的学号不会收回
+	AllOrNothing         bool     `protobuf:"varint,2,opt,name=allOrNothing,proto3" json:"allOrNothing,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkRegisterRequest) Reset()         { *m = BulkRegisterRequest{} }
+func (m *BulkRegisterRequest) String() string { return proto.CompactTextString(m) }
+func (*BulkRegisterRequest) ProtoMessage()    {}
+func (*BulkRegisterRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{12}
+}
+
+func (m *BulkRegisterRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkRegisterRequest.Unmarshal(m, b)
+}
+func (m *BulkRegisterRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkRegisterRequest.Marshal(b, m, deterministic)
+}
+func (m *BulkRegisterRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkRegisterRequest.Merge(m, src)
+}
+func (m *BulkRegisterRequest) XXX_Size() int {
+	return xxx_messageInfo_BulkRegisterRequest.Size(m)
+}
+func (m *BulkRegisterRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkRegisterRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkRegisterRequest proto.InternalMessageInfo
+
+func (m *BulkRegisterRequest) GetStudent() *RegisterRequest {
+	if m != nil {
+		return m.Student
+	}
+	return nil
+}
+
+func (m *BulkRegisterRequest) GetAllOrNothing() bool {
+	if m != nil {
+		return m.AllOrNothing
+	}
+	return false
+}
+
+// 单行注册结果，row 为从 0 开始的行号
+type BulkRegisterResult struct {
+	Row                  int32    `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Success              bool     `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Id                   string   `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Error                string   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BulkRegisterResult) Reset()         { *m = BulkRegisterResult{} }
+func (m *BulkRegisterResult) String() string { return proto.CompactTextString(m) }
+func (*BulkRegisterResult) ProtoMessage()    {}
+func (*BulkRegisterResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{13}
+}
+
+func (m *BulkRegisterResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkRegisterResult.Unmarshal(m, b)
+}
+func (m *BulkRegisterResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkRegisterResult.Marshal(b, m, deterministic)
+}
+func (m *BulkRegisterResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkRegisterResult.Merge(m, src)
+}
+func (m *BulkRegisterResult) XXX_Size() int {
+	return xxx_messageInfo_BulkRegisterResult.Size(m)
+}
+func (m *BulkRegisterResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkRegisterResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkRegisterResult proto.InternalMessageInfo
+
+func (m *BulkRegisterResult) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *BulkRegisterResult) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *BulkRegisterResult) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *BulkRegisterResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type BulkRegisterReply struct {
+	Results              []*BulkRegisterResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Succeeded            int32                 `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed               int32                 `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *BulkRegisterReply) Reset()         { *m = BulkRegisterReply{} }
+func (m *BulkRegisterReply) String() string { return proto.CompactTextString(m) }
+func (*BulkRegisterReply) ProtoMessage()    {}
+func (*BulkRegisterReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{14}
+}
+
+func (m *BulkRegisterReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkRegisterReply.Unmarshal(m, b)
+}
+func (m *BulkRegisterReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkRegisterReply.Marshal(b, m, deterministic)
+}
+func (m *BulkRegisterReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkRegisterReply.Merge(m, src)
+}
+func (m *BulkRegisterReply) XXX_Size() int {
+	return xxx_messageInfo_BulkRegisterReply.Size(m)
+}
+func (m *BulkRegisterReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkRegisterReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkRegisterReply proto.InternalMessageInfo
+
+func (m *BulkRegisterReply) GetResults() []*BulkRegisterResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *BulkRegisterReply) GetSucceeded() int32 {
+	if m != nil {
+		return m.Succeeded
+	}
+	return 0
+}
+
+func (m *BulkRegisterReply) GetFailed() int32 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+type ExportRequest struct {
+	Format               FileFormat     `protobuf:"varint,1,opt,name=format,proto3,enum=proto.FileFormat" json:"format,omitempty"`
+	Filter               *StudentFilter `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{15}
+}
+
+func (m *ExportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportRequest.Unmarshal(m, b)
+}
+func (m *ExportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRequest.Merge(m, src)
+}
+func (m *ExportRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportRequest.Size(m)
+}
+func (m *ExportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRequest proto.InternalMessageInfo
+
+func (m *ExportRequest) GetFormat() FileFormat {
+	if m != nil {
+		return m.Format
+	}
+	return FileFormat_CSV
+}
+
+func (m *ExportRequest) GetFilter() *StudentFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type FileChunk struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FileChunk) Reset()         { *m = FileChunk{} }
+func (m *FileChunk) String() string { return proto.CompactTextString(m) }
+func (*FileChunk) ProtoMessage()    {}
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{16}
+}
+
+func (m *FileChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileChunk.Unmarshal(m, b)
+}
+func (m *FileChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileChunk.Marshal(b, m, deterministic)
+}
+func (m *FileChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileChunk.Merge(m, src)
+}
+func (m *FileChunk) XXX_Size() int {
+	return xxx_messageInfo_FileChunk.Size(m)
+}
+func (m *FileChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileChunk proto.InternalMessageInfo
+
+func (m *FileChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type ImportRequest struct {
+	// 以下三项以第一条消息为准
+	Format FileFormat `protobuf:"varint,1,opt,name=format,proto3,enum=proto.FileFormat" json:"format,omitempty"`
+	// 只校验并返回报告，不写入
+	DryRun bool `protobuf:"varint,2,opt,name=dryRun,proto3" json:"dryRun,omitempty"`
+	// 有任一行校验不通过则全部不导入，否则同 BulkRegisterRequest 一次性原子写入
+	AllOrNothing         bool     `protobuf:"varint,3,opt,name=allOrNothing,proto3" json:"allOrNothing,omitempty"`
+	Data                 []byte   `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportRequest) Reset()         { *m = ImportRequest{} }
+func (m *ImportRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportRequest) ProtoMessage()    {}
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{17}
+}
+
+func (m *ImportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportRequest.Unmarshal(m, b)
+}
+func (m *ImportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportRequest.Merge(m, src)
+}
+func (m *ImportRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportRequest.Size(m)
+}
+func (m *ImportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportRequest proto.InternalMessageInfo
+
+func (m *ImportRequest) GetFormat() FileFormat {
+	if m != nil {
+		return m.Format
+	}
+	return FileFormat_CSV
+}
+
+func (m *ImportRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *ImportRequest) GetAllOrNothing() bool {
+	if m != nil {
+		return m.AllOrNothing
+	}
+	return false
+}
+
+func (m *ImportRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// 导入报告，results 中的 row 为从 1 开始的记录序号（不含 CSV 表头）
+type ImportReport struct {
+	Results              []*BulkRegisterResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Succeeded            int32                 `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed               int32                 `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	DryRun               bool                  `protobuf:"varint,4,opt,name=dryRun,proto3" json:"dryRun,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ImportReport) Reset()         { *m = ImportReport{} }
+func (m *ImportReport) String() string { return proto.CompactTextString(m) }
+func (*ImportReport) ProtoMessage()    {}
+func (*ImportReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{18}
+}
+
+func (m *ImportReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportReport.Unmarshal(m, b)
+}
+func (m *ImportReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportReport.Marshal(b, m, deterministic)
+}
+func (m *ImportReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportReport.Merge(m, src)
+}
+func (m *ImportReport) XXX_Size() int {
+	return xxx_messageInfo_ImportReport.Size(m)
+}
+func (m *ImportReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportReport proto.InternalMessageInfo
+
+func (m *ImportReport) GetResults() []*BulkRegisterResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *ImportReport) GetSucceeded() int32 {
+	if m != nil {
+		return m.Succeeded
+	}
+	return 0
+}
+
+func (m *ImportReport) GetFailed() int32 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *ImportReport) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+// 专业，学生以 code 引用；注册与修改时也可用任一语言的名称指定专业
+type Profession struct {
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// 按语言标记的名称，如 zh、en
+	Names map[string]string `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// 停用后不能再选择，已有学生不受影响
+	Active bool `protobuf:"varint,3,opt,name=active,proto3" json:"active,omitempty"`
+	// 未删除学生的人数上限，0 表示不限
+	Capacity int32 `protobuf:"varint,4,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// 当前未删除的学生人数，只读
+	Enrolled             int32    `protobuf:"varint,5,opt,name=enrolled,proto3" json:"enrolled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Profession) Reset()         { *m = Profession{} }
+func (m *Profession) String() string { return proto.CompactTextString(m) }
+func (*Profession) ProtoMessage()    {}
+func (*Profession) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{19}
+}
+
+func (m *Profession) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Profession.Unmarshal(m, b)
+}
+func (m *Profession) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Profession.Marshal(b, m, deterministic)
+}
+func (m *Profession) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Profession.Merge(m, src)
+}
+func (m *Profession) XXX_Size() int {
+	return xxx_messageInfo_Profession.Size(m)
+}
+func (m *Profession) XXX_DiscardUnknown() {
+	xxx_messageInfo_Profession.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Profession proto.InternalMessageInfo
+
+func (m *Profession) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *Profession) GetNames() map[string]string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+func (m *Profession) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *Profession) GetCapacity() int32 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *Profession) GetEnrolled() int32 {
+	if m != nil {
+		return m.Enrolled
+	}
+	return 0
+}
+
+type ProfessionRequest struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProfessionRequest) Reset()         { *m = ProfessionRequest{} }
+func (m *ProfessionRequest) String() string { return proto.CompactTextString(m) }
+func (*ProfessionRequest) ProtoMessage()    {}
+func (*ProfessionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{20}
+}
+
+func (m *ProfessionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProfessionRequest.Unmarshal(m, b)
+}
+func (m *ProfessionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProfessionRequest.Marshal(b, m, deterministic)
+}
+func (m *ProfessionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProfessionRequest.Merge(m, src)
+}
+func (m *ProfessionRequest) XXX_Size() int {
+	return xxx_messageInfo_ProfessionRequest.Size(m)
+}
+func (m *ProfessionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProfessionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProfessionRequest proto.InternalMessageInfo
+
+func (m *ProfessionRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type ListProfessionsRequest struct {
+	ActiveOnly           bool     `protobuf:"varint,1,opt,name=activeOnly,proto3" json:"activeOnly,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListProfessionsRequest) Reset()         { *m = ListProfessionsRequest{} }
+func (m *ListProfessionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListProfessionsRequest) ProtoMessage()    {}
+func (*ListProfessionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{21}
+}
+
+func (m *ListProfessionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListProfessionsRequest.Unmarshal(m, b)
+}
+func (m *ListProfessionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListProfessionsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListProfessionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListProfessionsRequest.Merge(m, src)
+}
+func (m *ListProfessionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListProfessionsRequest.Size(m)
+}
+func (m *ListProfessionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListProfessionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListProfessionsRequest proto.InternalMessageInfo
+
+func (m *ListProfessionsRequest) GetActiveOnly() bool {
+	if m != nil {
+		return m.ActiveOnly
+	}
+	return false
+}
+
+type ProfessionList struct {
+	Professions          []*Profession `protobuf:"bytes,1,rep,name=professions,proto3" json:"professions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ProfessionList) Reset()         { *m = ProfessionList{} }
+func (m *ProfessionList) String() string { return proto.CompactTextString(m) }
+func (*ProfessionList) ProtoMessage()    {}
+func (*ProfessionList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{22}
+}
+
+func (m *ProfessionList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProfessionList.Unmarshal(m, b)
+}
+func (m *ProfessionList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProfessionList.Marshal(b, m, deterministic)
+}
+func (m *ProfessionList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProfessionList.Merge(m, src)
+}
+func (m *ProfessionList) XXX_Size() int {
+	return xxx_messageInfo_ProfessionList.Size(m)
+}
+func (m *ProfessionList) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProfessionList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProfessionList proto.InternalMessageInfo
+
+func (m *ProfessionList) GetProfessions() []*Profession {
+	if m != nil {
+		return m.Professions
+	}
+	return nil
+}
+
+type UpdateStudentRequest struct {
+	// id 必填，其余字段按 updateMask 取值
+	Student *StudentInfo `protobuf:"bytes,1,opt,name=student,proto3" json:"student,omitempty"`
+	// 可选 name、age、profession，为空表示修改这三项；
+	// duplicateOf 需显式指定且只能清空，表示已复核
+	UpdateMask           *field_mask.FieldMask `protobuf:"bytes,2,opt,name=updateMask,proto3" json:"updateMask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *UpdateStudentRequest) Reset()         { *m = UpdateStudentRequest{} }
+func (m *UpdateStudentRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateStudentRequest) ProtoMessage()    {}
+func (*UpdateStudentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{23}
+}
+
+func (m *UpdateStudentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateStudentRequest.Unmarshal(m, b)
+}
+func (m *UpdateStudentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateStudentRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateStudentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateStudentRequest.Merge(m, src)
+}
+func (m *UpdateStudentRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateStudentRequest.Size(m)
+}
+func (m *UpdateStudentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateStudentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateStudentRequest proto.InternalMessageInfo
+
+func (m *UpdateStudentRequest) GetStudent() *StudentInfo {
+	if m != nil {
+		return m.Student
+	}
+	return nil
+}
+
+func (m *UpdateStudentRequest) GetUpdateMask() *field_mask.FieldMask {
+	if m != nil {
+		return m.UpdateMask
+	}
+	return nil
+}
+
+type HistoryRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HistoryRequest) Reset()         { *m = HistoryRequest{} }
+func (m *HistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*HistoryRequest) ProtoMessage()    {}
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{24}
+}
+
+func (m *HistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryRequest.Unmarshal(m, b)
+}
+func (m *HistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *HistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryRequest.Merge(m, src)
+}
+func (m *HistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_HistoryRequest.Size(m)
+}
+func (m *HistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryRequest proto.InternalMessageInfo
+
+func (m *HistoryRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+// 一次变更的审计记录，注册时 before 为空，彻底删除时 after 为空
+type HistoryEntry struct {
+	Action    string       `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Actor     string       `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	RequestId string       `protobuf:"bytes,3,opt,name=requestId,proto3" json:"requestId,omitempty"`
+	Time      int64        `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	Before    *StudentInfo `protobuf:"bytes,5,opt,name=before,proto3" json:"before,omitempty"`
+	After     *StudentInfo `protobuf:"bytes,6,opt,name=after,proto3" json:"after,omitempty"`
+	// 合并时为另一方学生的 id
+	MergedWith           string   `protobuf:"bytes,7,opt,name=mergedWith,proto3" json:"mergedWith,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HistoryEntry) Reset()         { *m = HistoryEntry{} }
+func (m *HistoryEntry) String() string { return proto.CompactTextString(m) }
+func (*HistoryEntry) ProtoMessage()    {}
+func (*HistoryEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{25}
+}
+
+func (m *HistoryEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryEntry.Unmarshal(m, b)
+}
+func (m *HistoryEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryEntry.Marshal(b, m, deterministic)
+}
+func (m *HistoryEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryEntry.Merge(m, src)
+}
+func (m *HistoryEntry) XXX_Size() int {
+	return xxx_messageInfo_HistoryEntry.Size(m)
+}
+func (m *HistoryEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryEntry proto.InternalMessageInfo
+
+func (m *HistoryEntry) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *HistoryEntry) GetBefore() *StudentInfo {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+func (m *HistoryEntry) GetAfter() *StudentInfo {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *HistoryEntry) GetMergedWith() string {
+	if m != nil {
+		return m.MergedWith
+	}
+	return ""
+}
+
+// 按时间先后排列
+type StudentHistory struct {
+	Entries              []*HistoryEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *StudentHistory) Reset()         { *m = StudentHistory{} }
+func (m *StudentHistory) String() string { return proto.CompactTextString(m) }
+func (*StudentHistory) ProtoMessage()    {}
+func (*StudentHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{26}
+}
+
+func (m *StudentHistory) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StudentHistory.Unmarshal(m, b)
+}
+func (m *StudentHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StudentHistory.Marshal(b, m, deterministic)
+}
+func (m *StudentHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StudentHistory.Merge(m, src)
+}
+func (m *StudentHistory) XXX_Size() int {
+	return xxx_messageInfo_StudentHistory.Size(m)
+}
+func (m *StudentHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_StudentHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StudentHistory proto.InternalMessageInfo
+
+func (m *StudentHistory) GetEntries() []*HistoryEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type FindDuplicatesRequest struct {
+	// 只在该专业内查找，空表示所有专业
+	Profession string `protobuf:"bytes,1,opt,name=profession,proto3" json:"profession,omitempty"`
+	// 姓名最多相差的字母数，0 表示使用服务端配置
+	MaxDistance int32 `protobuf:"varint,2,opt,name=maxDistance,proto3" json:"maxDistance,omitempty"`
+	// 只查找姓名相同（不区分大小写）的学生，忽略 maxDistance
+	ExactOnly            bool     `protobuf:"varint,3,opt,name=exactOnly,proto3" json:"exactOnly,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FindDuplicatesRequest) Reset()         { *m = FindDuplicatesRequest{} }
+func (m *FindDuplicatesRequest) String() string { return proto.CompactTextString(m) }
+func (*FindDuplicatesRequest) ProtoMessage()    {}
+func (*FindDuplicatesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{27}
+}
+
+func (m *FindDuplicatesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindDuplicatesRequest.Unmarshal(m, b)
+}
+func (m *FindDuplicatesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindDuplicatesRequest.Marshal(b, m, deterministic)
+}
+func (m *FindDuplicatesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindDuplicatesRequest.Merge(m, src)
+}
+func (m *FindDuplicatesRequest) XXX_Size() int {
+	return xxx_messageInfo_FindDuplicatesRequest.Size(m)
+}
+func (m *FindDuplicatesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindDuplicatesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindDuplicatesRequest proto.InternalMessageInfo
+
+func (m *FindDuplicatesRequest) GetProfession() string {
+	if m != nil {
+		return m.Profession
+	}
+	return ""
+}
+
+func (m *FindDuplicatesRequest) GetMaxDistance() int32 {
+	if m != nil {
+		return m.MaxDistance
+	}
+	return 0
+}
+
+func (m *FindDuplicatesRequest) GetExactOnly() bool {
+	if m != nil {
+		return m.ExactOnly
+	}
+	return false
+}
+
+// 一组年龄、专业相同且姓名相同或相近的学生，按创建时间先后排列
+type DuplicateGroup struct {
+	Students             []*StudentInfo `protobuf:"bytes,1,rep,name=students,proto3" json:"students,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *DuplicateGroup) Reset()         { *m = DuplicateGroup{} }
+func (m *DuplicateGroup) String() string { return proto.CompactTextString(m) }
+func (*DuplicateGroup) ProtoMessage()    {}
+func (*DuplicateGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{28}
+}
+
+func (m *DuplicateGroup) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DuplicateGroup.Unmarshal(m, b)
+}
+func (m *DuplicateGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DuplicateGroup.Marshal(b, m, deterministic)
+}
+func (m *DuplicateGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DuplicateGroup.Merge(m, src)
+}
+func (m *DuplicateGroup) XXX_Size() int {
+	return xxx_messageInfo_DuplicateGroup.Size(m)
+}
+func (m *DuplicateGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_DuplicateGroup.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DuplicateGroup proto.InternalMessageInfo
+
+func (m *DuplicateGroup) GetStudents() []*StudentInfo {
+	if m != nil {
+		return m.Students
+	}
+	return nil
+}
+
+// 按组内最早创建的学生排序
+type DuplicateReport struct {
+	Groups               []*DuplicateGroup `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *DuplicateReport) Reset()         { *m = DuplicateReport{} }
+func (m *DuplicateReport) String() string { return proto.CompactTextString(m) }
+func (*DuplicateReport) ProtoMessage()    {}
+func (*DuplicateReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{29}
+}
+
+func (m *DuplicateReport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DuplicateReport.Unmarshal(m, b)
+}
+func (m *DuplicateReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DuplicateReport.Marshal(b, m, deterministic)
+}
+func (m *DuplicateReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DuplicateReport.Merge(m, src)
+}
+func (m *DuplicateReport) XXX_Size() int {
+	return xxx_messageInfo_DuplicateReport.Size(m)
+}
+func (m *DuplicateReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_DuplicateReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DuplicateReport proto.InternalMessageInfo
+
+func (m *DuplicateReport) GetGroups() []*DuplicateGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+// 创建时间取两者中较早的，疑似重复标记指向 retired 时清除
+type MergeStudentsRequest struct {
+	SurvivorId string `protobuf:"bytes,1,opt,name=survivorId,proto3" json:"survivorId,omitempty"`
+	RetiredId  string `protobuf:"bytes,2,opt,name=retiredId,proto3" json:"retiredId,omitempty"`
+	// 非 0 时仅在版本一致时合并
+	SurvivorVersion int64     `protobuf:"varint,3,opt,name=survivorVersion,proto3" json:"survivorVersion,omitempty"`
+	RetiredVersion  int64     `protobuf:"varint,4,opt,name=retiredVersion,proto3" json:"retiredVersion,omitempty"`
+	Rule            MergeRule `protobuf:"varint,5,opt,name=rule,proto3,enum=proto.MergeRule" json:"rule,omitempty"`
+	// 可选 name、age、profession，不论规则都取 retired 的值
+	FromRetired          *field_mask.FieldMask `protobuf:"bytes,6,opt,name=fromRetired,proto3" json:"fromRetired,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *MergeStudentsRequest) Reset()         { *m = MergeStudentsRequest{} }
+func (m *MergeStudentsRequest) String() string { return proto.CompactTextString(m) }
+func (*MergeStudentsRequest) ProtoMessage()    {}
+func (*MergeStudentsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{30}
+}
+
+func (m *MergeStudentsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MergeStudentsRequest.Unmarshal(m, b)
+}
+func (m *MergeStudentsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MergeStudentsRequest.Marshal(b, m, deterministic)
+}
+func (m *MergeStudentsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MergeStudentsRequest.Merge(m, src)
+}
+func (m *MergeStudentsRequest) XXX_Size() int {
+	return xxx_messageInfo_MergeStudentsRequest.Size(m)
+}
+func (m *MergeStudentsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MergeStudentsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MergeStudentsRequest proto.InternalMessageInfo
+
+func (m *MergeStudentsRequest) GetSurvivorId() string {
+	if m != nil {
+		return m.SurvivorId
+	}
+	return ""
+}
+
+func (m *MergeStudentsRequest) GetRetiredId() string {
+	if m != nil {
+		return m.RetiredId
+	}
+	return ""
+}
+
+func (m *MergeStudentsRequest) GetSurvivorVersion() int64 {
+	if m != nil {
+		return m.SurvivorVersion
+	}
+	return 0
+}
+
+func (m *MergeStudentsRequest) GetRetiredVersion() int64 {
+	if m != nil {
+		return m.RetiredVersion
+	}
+	return 0
+}
+
+func (m *MergeStudentsRequest) GetRule() MergeRule {
+	if m != nil {
+		return m.Rule
+	}
+	return MergeRule_KEEP_SURVIVOR
+}
+
+func (m *MergeStudentsRequest) GetFromRetired() *field_mask.FieldMask {
+	if m != nil {
+		return m.FromRetired
+	}
+	return nil
+}
+
+type Course struct {
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Title   string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Credits int32  `protobuf:"varint,3,opt,name=credits,proto3" json:"credits,omitempty"`
+	// 可选该课程的专业代码，空表示不限，只在选课时检查
+	Professions []string `protobuf:"bytes,4,rep,name=professions,proto3" json:"professions,omitempty"`
+	// 选课人数上限，0 表示不限
+	Capacity int32 `protobuf:"varint,5,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// 当前选课人数，只读
+	Enrolled             int32    `protobuf:"varint,6,opt,name=enrolled,proto3" json:"enrolled,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Course) Reset()         { *m = Course{} }
+func (m *Course) String() string { return proto.CompactTextString(m) }
+func (*Course) ProtoMessage()    {}
+func (*Course) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{31}
+}
+
+func (m *Course) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Course.Unmarshal(m, b)
+}
+func (m *Course) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Course.Marshal(b, m, deterministic)
+}
+func (m *Course) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Course.Merge(m, src)
+}
+func (m *Course) XXX_Size() int {
+	return xxx_messageInfo_Course.Size(m)
+}
+func (m *Course) XXX_DiscardUnknown() {
+	xxx_messageInfo_Course.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Course proto.InternalMessageInfo
+
+func (m *Course) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *Course) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *Course) GetCredits() int32 {
+	if m != nil {
+		return m.Credits
+	}
+	return 0
+}
+
+func (m *Course) GetProfessions() []string {
+	if m != nil {
+		return m.Professions
+	}
+	return nil
+}
+
+func (m *Course) GetCapacity() int32 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *Course) GetEnrolled() int32 {
+	if m != nil {
+		return m.Enrolled
+	}
+	return 0
+}
+
+type CourseRequest struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CourseRequest) Reset()         { *m = CourseRequest{} }
+func (m *CourseRequest) String() string { return proto.CompactTextString(m) }
+func (*CourseRequest) ProtoMessage()    {}
+func (*CourseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{32}
+}
+
+func (m *CourseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CourseRequest.Unmarshal(m, b)
+}
+func (m *CourseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CourseRequest.Marshal(b, m, deterministic)
+}
+func (m *CourseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CourseRequest.Merge(m, src)
+}
+func (m *CourseRequest) XXX_Size() int {
+	return xxx_messageInfo_CourseRequest.Size(m)
+}
+func (m *CourseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CourseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CourseRequest proto.InternalMessageInfo
+
+func (m *CourseRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type ListCoursesRequest struct {
+	// 只列出该专业（代码或名称）可选的课程，空表示全部
+	Profession           string   `protobuf:"bytes,1,opt,name=profession,proto3" json:"profession,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListCoursesRequest) Reset()         { *m = ListCoursesRequest{} }
+func (m *ListCoursesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListCoursesRequest) ProtoMessage()    {}
+func (*ListCoursesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{33}
+}
+
+func (m *ListCoursesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListCoursesRequest.Unmarshal(m, b)
+}
+func (m *ListCoursesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListCoursesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListCoursesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListCoursesRequest.Merge(m, src)
+}
+func (m *ListCoursesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListCoursesRequest.Size(m)
+}
+func (m *ListCoursesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListCoursesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListCoursesRequest proto.InternalMessageInfo
+
+func (m *ListCoursesRequest) GetProfession() string {
+	if m != nil {
+		return m.Profession
+	}
+	return ""
+}
+
+type CourseList struct {
+	Courses              []*Course `protobuf:"bytes,1,rep,name=courses,proto3" json:"courses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *CourseList) Reset()         { *m = CourseList{} }
+func (m *CourseList) String() string { return proto.CompactTextString(m) }
+func (*CourseList) ProtoMessage()    {}
+func (*CourseList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{34}
+}
+
+func (m *CourseList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CourseList.Unmarshal(m, b)
+}
+func (m *CourseList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CourseList.Marshal(b, m, deterministic)
+}
+func (m *CourseList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CourseList.Merge(m, src)
+}
+func (m *CourseList) XXX_Size() int {
+	return xxx_messageInfo_CourseList.Size(m)
+}
+func (m *CourseList) XXX_DiscardUnknown() {
+	xxx_messageInfo_CourseList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CourseList proto.InternalMessageInfo
+
+func (m *CourseList) GetCourses() []*Course {
+	if m != nil {
+		return m.Courses
+	}
+	return nil
+}
+
+type EnrollmentRequest struct {
+	CourseCode string `protobuf:"bytes,1,opt,name=courseCode,proto3" json:"courseCode,omitempty"`
+	// 学生 id，也可以是学号
+	StudentId            string   `protobuf:"bytes,2,opt,name=studentId,proto3" json:"studentId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrollmentRequest) Reset()         { *m = EnrollmentRequest{} }
+func (m *EnrollmentRequest) String() string { return proto.CompactTextString(m) }
+func (*EnrollmentRequest) ProtoMessage()    {}
+func (*EnrollmentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{35}
+}
+
+func (m *EnrollmentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrollmentRequest.Unmarshal(m, b)
+}
+func (m *EnrollmentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrollmentRequest.Marshal(b, m, deterministic)
+}
+func (m *EnrollmentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrollmentRequest.Merge(m, src)
+}
+func (m *EnrollmentRequest) XXX_Size() int {
+	return xxx_messageInfo_EnrollmentRequest.Size(m)
+}
+func (m *EnrollmentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrollmentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrollmentRequest proto.InternalMessageInfo
+
+func (m *EnrollmentRequest) GetCourseCode() string {
+	if m != nil {
+		return m.CourseCode
+	}
+	return ""
+}
+
+func (m *EnrollmentRequest) GetStudentId() string {
+	if m != nil {
+		return m.StudentId
+	}
+	return ""
+}
+
+type Enrollment struct {
+	CourseCode string `protobuf:"bytes,1,opt,name=courseCode,proto3" json:"courseCode,omitempty"`
+	StudentId  string `protobuf:"bytes,2,opt,name=studentId,proto3" json:"studentId,omitempty"`
+	// 选课时间（unix 秒）
+	Time                 int64    `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Enrollment) Reset()         { *m = Enrollment{} }
+func (m *Enrollment) String() string { return proto.CompactTextString(m) }
+func (*Enrollment) ProtoMessage()    {}
+func (*Enrollment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{36}
+}
+
+func (m *Enrollment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Enrollment.Unmarshal(m, b)
+}
+func (m *Enrollment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Enrollment.Marshal(b, m, deterministic)
+}
+func (m *Enrollment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Enrollment.Merge(m, src)
+}
+func (m *Enrollment) XXX_Size() int {
+	return xxx_messageInfo_Enrollment.Size(m)
+}
+func (m *Enrollment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Enrollment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Enrollment proto.InternalMessageInfo
+
+func (m *Enrollment) GetCourseCode() string {
+	if m != nil {
+		return m.CourseCode
+	}
+	return ""
+}
+
+func (m *Enrollment) GetStudentId() string {
+	if m != nil {
+		return m.StudentId
+	}
+	return ""
+}
+
+func (m *Enrollment) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+// courseCode 与 studentId 必须且只能给出一个
+type ListEnrollmentsRequest struct {
+	CourseCode string `protobuf:"bytes,1,opt,name=courseCode,proto3" json:"courseCode,omitempty"`
+	// 学生 id，也可以是学号
+	StudentId            string   `protobuf:"bytes,2,opt,name=studentId,proto3" json:"studentId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListEnrollmentsRequest) Reset()         { *m = ListEnrollmentsRequest{} }
+func (m *ListEnrollmentsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListEnrollmentsRequest) ProtoMessage()    {}
+func (*ListEnrollmentsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{37}
+}
+
+func (m *ListEnrollmentsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEnrollmentsRequest.Unmarshal(m, b)
+}
+func (m *ListEnrollmentsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEnrollmentsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListEnrollmentsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEnrollmentsRequest.Merge(m, src)
+}
+func (m *ListEnrollmentsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListEnrollmentsRequest.Size(m)
+}
+func (m *ListEnrollmentsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEnrollmentsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEnrollmentsRequest proto.InternalMessageInfo
+
+func (m *ListEnrollmentsRequest) GetCourseCode() string {
+	if m != nil {
+		return m.CourseCode
+	}
+	return ""
+}
+
+func (m *ListEnrollmentsRequest) GetStudentId() string {
+	if m != nil {
+		return m.StudentId
+	}
+	return ""
+}
+
+// 按课程查询时按选课时间先后排列，按学生查询时按课程代码排列
+type EnrollmentList struct {
+	Enrollments          []*Enrollment `protobuf:"bytes,1,rep,name=enrollments,proto3" json:"enrollments,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *EnrollmentList) Reset()         { *m = EnrollmentList{} }
+func (m *EnrollmentList) String() string { return proto.CompactTextString(m) }
+func (*EnrollmentList) ProtoMessage()    {}
+func (*EnrollmentList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a0b84a42fa06f626, []int{38}
+}
+
+func (m *EnrollmentList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrollmentList.Unmarshal(m, b)
+}
+func (m *EnrollmentList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrollmentList.Marshal(b, m, deterministic)
+}
+func (m *EnrollmentList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrollmentList.Merge(m, src)
+}
+func (m *EnrollmentList) XXX_Size() int {
+	return xxx_messageInfo_EnrollmentList.Size(m)
+}
+func (m *EnrollmentList) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrollmentList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrollmentList proto.InternalMessageInfo
+
+func (m *EnrollmentList) GetEnrollments() []*Enrollment {
+	if m != nil {
+		return m.Enrollments
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("proto.SortKey", SortKey_name, SortKey_value)
+	proto.RegisterEnum("proto.DeletedFilter", DeletedFilter_name, DeletedFilter_value)
+	proto.RegisterEnum("proto.FileFormat", FileFormat_name, FileFormat_value)
+	proto.RegisterEnum("proto.MergeRule", MergeRule_name, MergeRule_value)
+	proto.RegisterEnum("proto.StudentEvent_EventType", StudentEvent_EventType_name, StudentEvent_EventType_value)
+	proto.RegisterType((*HelloRequest)(nil), "proto.HelloRequest")
+	proto.RegisterType((*HelloReply)(nil), "proto.HelloReply")
+	proto.RegisterType((*RegisterRequest)(nil), "proto.RegisterRequest")
+	proto.RegisterType((*Result)(nil), "proto.Result")
+	proto.RegisterType((*StudentInfo)(nil), "proto.StudentInfo")
+	proto.RegisterType((*QueryStudentRequest)(nil), "proto.QueryStudentRequest")
+	proto.RegisterType((*RegisterReply)(nil), "proto.RegisterReply")
+	proto.RegisterType((*StudentList)(nil), "proto.StudentList")
+	proto.RegisterType((*StudentFilter)(nil), "proto.StudentFilter")
+	proto.RegisterType((*QueryRequest)(nil), "proto.QueryRequest")
+	proto.RegisterType((*WatchRequest)(nil), "proto.WatchRequest")
+	proto.RegisterType((*StudentEvent)(nil), "proto.StudentEvent")
+	proto.RegisterType((*BulkRegisterRequest)(nil), "proto.BulkRegisterRequest")
+	proto.RegisterType((*BulkRegisterResult)(nil), "proto.BulkRegisterResult")
+	proto.RegisterType((*BulkRegisterReply)(nil), "proto.BulkRegisterReply")
+	proto.RegisterType((*ExportRequest)(nil), "proto.ExportRequest")
+	proto.RegisterType((*FileChunk)(nil), "proto.FileChunk")
+	proto.RegisterType((*ImportRequest)(nil), "proto.ImportRequest")
+	proto.RegisterType((*ImportReport)(nil), "proto.ImportReport")
+	proto.RegisterType((*Profession)(nil), "proto.Profession")
+	proto.RegisterMapType((map[string]string)(nil), "proto.Profession.NamesEntry")
+	proto.RegisterType((*ProfessionRequest)(nil), "proto.ProfessionRequest")
+	proto.RegisterType((*ListProfessionsRequest)(nil), "proto.ListProfessionsRequest")
+	proto.RegisterType((*ProfessionList)(nil), "proto.ProfessionList")
+	proto.RegisterType((*UpdateStudentRequest)(nil), "proto.UpdateStudentRequest")
+	proto.RegisterType((*HistoryRequest)(nil), "proto.HistoryRequest")
+	proto.RegisterType((*HistoryEntry)(nil), "proto.HistoryEntry")
+	proto.RegisterType((*StudentHistory)(nil), "proto.StudentHistory")
+	proto.RegisterType((*FindDuplicatesRequest)(nil), "proto.FindDuplicatesRequest")
+	proto.RegisterType((*DuplicateGroup)(nil), "proto.DuplicateGroup")
+	proto.RegisterType((*DuplicateReport)(nil), "proto.DuplicateReport")
+	proto.RegisterType((*MergeStudentsRequest)(nil), "proto.MergeStudentsRequest")
+	proto.RegisterType((*Course)(nil), "proto.Course")
+	proto.RegisterType((*CourseRequest)(nil), "proto.CourseRequest")
+	proto.RegisterType((*ListCoursesRequest)(nil), "proto.ListCoursesRequest")
+	proto.RegisterType((*CourseList)(nil), "proto.CourseList")
+	proto.RegisterType((*EnrollmentRequest)(nil), "proto.EnrollmentRequest")
+	proto.RegisterType((*Enrollment)(nil), "proto.Enrollment")
+	proto.RegisterType((*ListEnrollmentsRequest)(nil), "proto.ListEnrollmentsRequest")
+	proto.RegisterType((*EnrollmentList)(nil), "proto.EnrollmentList")
+}
+
+func init() {
+	proto.RegisterFile("service.proto", fileDescriptor_a0b84a42fa06f626)
+}
+
+var fileDescriptor_a0b84a42fa06f626 = []byte{
+	// 2291 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xdd, 0x72, 0x1b, 0x49,
+	0x15, 0xd6, 0xe8, 0xdf, 0x47, 0x3f, 0x96, 0xdb, 0x8e, 0x4b, 0x2b, 0x96, 0xe0, 0x6a, 0xb6, 0xb2,
+	0xc6, 0x64, 0xbd, 0x8b, 0x93, 0x65, 0x43, 0x6a, 0x43, 0xe2, 0x58, 0x63, 0x47, 0x9b, 0x44, 0xf2,
+	0x8e, 0x9c, 0x04, 0xb8, 0x20, 0x35, 0xd1, 0xb4, 0x94, 0x29, 0x8f, 0x34, 0xa2, 0x67, 0xe4, 0xb5,
+	0xb8, 0x00, 0x2e, 0xa9, 0xbd, 0xe3, 0x01, 0xb8, 0xe5, 0x05, 0x78, 0x11, 0x9e, 0x80, 0x2b, 0x78,
+	0x0f, 0xaa, 0x7f, 0xa7, 0x47, 0x92, 0xbd, 0xae, 0x85, 0xe2, 0xc6, 0x9e, 0x73, 0xfa, 0x74, 0x9f,
+	0x73, 0xfa, 0x7c, 0x7d, 0xfa, 0x6b, 0x41, 0x2d, 0x22, 0xf4, 0xc2, 0x1f, 0x90, 0xfd, 0x29, 0x0d,
+	0xe3, 0x10, 0x15, 0xf8, 0xbf, 0xd6, 0xce, 0x28, 0x0c, 0x47, 0x01, 0xf9, 0x94, 0x4b, 0xef, 0x66,
+	0xc3, 0x4f, 0x87, 0x3e, 0x09, 0xbc, 0xb7, 0x63, 0x37, 0x3a, 0x17, 0x86, 0x18, 0x43, 0xf5, 0x19,
+	0x09, 0x82, 0xd0, 0x21, 0xbf, 0x9b, 0x91, 0x28, 0x46, 0x08, 0xf2, 0x13, 0x77, 0x4c, 0x9a, 0xd6,
+	0x8e, 0xb5, 0xbb, 0xe6, 0xf0, 0x6f, 0x7c, 0x07, 0x40, 0xda, 0x4c, 0x83, 0x39, 0x6a, 0x42, 0x69,
+	0x4c, 0xa2, 0xc8, 0x1d, 0x29, 0x23, 0x25, 0xe2, 0x37, 0xb0, 0xee, 0x90, 0x91, 0x1f, 0xc5, 0x84,
+	0x5e, 0xb3, 0x1c, 0x6a, 0x40, 0x8e, 0x4d, 0xce, 0xee, 0x58, 0xbb, 0x05, 0x87, 0x7d, 0xa2, 0xdb,
+	0x00, 0x53, 0x1a, 0x0e, 0x49, 0x14, 0xf9, 0xe1, 0xa4, 0x99, 0xe3, 0xb6, 0x86, 0x06, 0xb7, 0xa0,
+	0xe8, 0x90, 0x68, 0x16, 0xc4, 0x6c, 0x2e, 0x25, 0x11, 0x5f, 0xae, 0xec, 0xb0, 0x4f, 0xfc, 0xd7,
+	0x1c, 0x54, 0xfa, 0xf1, 0xcc, 0x23, 0x93, 0xb8, 0x33, 0x19, 0x86, 0xa8, 0x0e, 0x59, 0xdf, 0x93,
+	0xfe, 0xb2, 0xbe, 0xa7, 0x23, 0xc8, 0x2e, 0x47, 0x90, 0xbb, 0x2a, 0x82, 0xfc, 0x62, 0x04, 0x6c,
+	0x7c, 0x40, 0x89, 0x1b, 0x93, 0x33, 0x7f, 0x4c, 0x9a, 0x85, 0x1d, 0x6b, 0x37, 0xe7, 0x18, 0x1a,
+	0x84, 0xa1, 0x3a, 0x0e, 0x3d, 0x7f, 0xe8, 0x13, 0x8f, 0x5b, 0x14, 0xb9, 0x45, 0x4a, 0xc7, 0x36,
+	0xee, 0x82, 0x50, 0xee, 0xa0, 0xc4, 0x87, 0x95, 0x88, 0x76, 0xa0, 0xe2, 0x91, 0x80, 0xc4, 0x72,
+	0x72, 0x99, 0x8f, 0x9a, 0x2a, 0xb4, 0x0b, 0xeb, 0x89, 0xb7, 0xae, 0x3b, 0x09, 0xa3, 0xe6, 0x1a,
+	0xb7, 0x5a, 0x54, 0xa3, 0xbb, 0xb0, 0x61, 0x7a, 0x15, 0xb6, 0xc0, 0x6d, 0x97, 0x07, 0xb8, 0xe7,
+	0xd9, 0x34, 0xf0, 0x07, 0x6e, 0x4c, 0x7a, 0xc3, 0x66, 0x85, 0x27, 0x6e, 0xaa, 0xd0, 0x36, 0x14,
+	0x27, 0xb3, 0xf1, 0x3b, 0x42, 0x9b, 0x55, 0x3e, 0x28, 0x25, 0x74, 0x07, 0xea, 0xc9, 0xfe, 0x74,
+	0xd9, 0x0e, 0xd7, 0xf8, 0xf8, 0x82, 0x16, 0x3f, 0x86, 0xcd, 0xaf, 0x67, 0x84, 0xce, 0x65, 0x8d,
+	0x14, 0x30, 0x56, 0x94, 0xc9, 0x8d, 0x7a, 0x43, 0x99, 0x15, 0xff, 0xfe, 0x2a, 0x5f, 0xce, 0x36,
+	0xd6, 0xf0, 0x17, 0x50, 0x4b, 0x50, 0xc5, 0x00, 0xb8, 0x38, 0x35, 0x89, 0x30, 0x6b, 0x46, 0x88,
+	0xff, 0xa8, 0x81, 0xf1, 0xc2, 0x8f, 0x62, 0x74, 0x1f, 0x2a, 0x51, 0x82, 0x93, 0xa6, 0xb5, 0x93,
+	0xdb, 0xad, 0x1c, 0x20, 0x71, 0x0c, 0xf6, 0x0d, 0x04, 0x39, 0xa6, 0x19, 0xda, 0x82, 0x42, 0x1c,
+	0xc6, 0x6e, 0x20, 0xe1, 0x2a, 0x04, 0xf4, 0x11, 0xd4, 0x26, 0xe4, 0x32, 0x3e, 0x75, 0x47, 0xe4,
+	0x2c, 0x3c, 0x27, 0x0a, 0xb3, 0x69, 0x25, 0xfe, 0x77, 0x16, 0x6a, 0x72, 0xe1, 0x63, 0x3f, 0x88,
+	0x09, 0x5d, 0x80, 0x99, 0xb5, 0x04, 0xb3, 0x6d, 0x28, 0x8e, 0xfd, 0xc9, 0xa1, 0x3e, 0x1d, 0x52,
+	0xe2, 0x7a, 0xf7, 0xf2, 0x50, 0x63, 0x56, 0x4a, 0x6c, 0x3d, 0x06, 0xe8, 0x53, 0x4a, 0x86, 0xfe,
+	0xa5, 0x82, 0x6d, 0xa2, 0x61, 0xe5, 0x15, 0xf8, 0xf0, 0x8e, 0x69, 0x38, 0x96, 0xb8, 0x35, 0x55,
+	0xe8, 0x43, 0x58, 0x93, 0xe2, 0x59, 0x28, 0x51, 0x9b, 0x28, 0x4c, 0x58, 0xf3, 0x05, 0x4a, 0x69,
+	0x58, 0xf3, 0x15, 0x6e, 0x03, 0x68, 0x5c, 0x85, 0x12, 0xbb, 0x86, 0x06, 0xed, 0x43, 0x49, 0x22,
+	0x99, 0x17, 0xb7, 0x7e, 0xb0, 0x25, 0xf7, 0xbc, 0x2d, 0xb4, 0x62, 0x6b, 0x1c, 0x65, 0xc4, 0x8e,
+	0xc9, 0x30, 0x70, 0x47, 0x23, 0xe2, 0x71, 0xd8, 0x96, 0x1d, 0x25, 0x1a, 0x85, 0xae, 0xa4, 0x0a,
+	0xfd, 0x0f, 0x0b, 0xaa, 0x1c, 0x63, 0x0a, 0x5c, 0x2d, 0x28, 0x4f, 0xdd, 0x11, 0xe9, 0xfb, 0xbf,
+	0x17, 0x9d, 0xa7, 0xe0, 0x68, 0x99, 0x25, 0x3c, 0xd5, 0x65, 0x13, 0x80, 0x49, 0x14, 0xe8, 0x2e,
+	0x14, 0x87, 0x3c, 0x1e, 0xbe, 0xd1, 0x15, 0x1d, 0x6b, 0xaa, 0x8c, 0x8e, 0xb4, 0x41, 0x77, 0xa0,
+	0x18, 0x85, 0x34, 0x7e, 0x3a, 0xe7, 0x5b, 0x5f, 0x3f, 0xa8, 0x2b, 0xeb, 0x90, 0xc6, 0xcf, 0xc9,
+	0xdc, 0x91, 0xa3, 0xcc, 0xa7, 0x1b, 0x0d, 0xc8, 0xc4, 0xf3, 0x27, 0x23, 0x5e, 0x84, 0xb2, 0x93,
+	0x28, 0x34, 0xf4, 0x8b, 0x09, 0xf4, 0xf1, 0x6f, 0xa0, 0xfa, 0xc6, 0x8d, 0x07, 0xef, 0x55, 0x46,
+	0x18, 0xaa, 0x43, 0x1a, 0x8e, 0x1d, 0x72, 0xe1, 0x6b, 0xe8, 0xe4, 0x9c, 0x94, 0x4e, 0x9e, 0xc8,
+	0x11, 0x25, 0x51, 0xd4, 0x0d, 0x63, 0x7f, 0x38, 0xe7, 0xe9, 0x95, 0x9d, 0x05, 0x2d, 0xfe, 0x73,
+	0x16, 0xaa, 0x32, 0x1f, 0xfb, 0x82, 0x4c, 0x62, 0xf4, 0x33, 0xc8, 0xc7, 0xf3, 0xa9, 0xd8, 0xaa,
+	0xfa, 0xc1, 0x0f, 0xd3, 0x29, 0x73, 0x93, 0x7d, 0xfe, 0xf7, 0x6c, 0x3e, 0x25, 0x0e, 0x37, 0x45,
+	0x77, 0xa1, 0x24, 0x4f, 0x09, 0x77, 0xb2, 0xfa, 0x20, 0x29, 0x13, 0x56, 0x0f, 0xaa, 0x22, 0xcf,
+	0xf1, 0xc8, 0xb5, 0xcc, 0xb2, 0x8f, 0x59, 0xd3, 0xcb, 0x8b, 0xec, 0xd9, 0x37, 0x1e, 0xc1, 0x9a,
+	0x76, 0x88, 0x2a, 0x50, 0x7a, 0xd5, 0x7d, 0xde, 0xed, 0xbd, 0xe9, 0x36, 0x32, 0x4c, 0x38, 0x72,
+	0xec, 0xc3, 0x33, 0xbb, 0xdd, 0xb0, 0xf8, 0xc8, 0x69, 0x9b, 0x0b, 0x59, 0x26, 0xb4, 0xed, 0x17,
+	0x36, 0x13, 0x72, 0xa8, 0x0a, 0x65, 0xc7, 0xee, 0x9f, 0xf5, 0x1c, 0xbb, 0xdd, 0xc8, 0x23, 0x80,
+	0xe2, 0xe9, 0x2b, 0xe7, 0xc4, 0x6e, 0x37, 0x0a, 0x6c, 0xe4, 0xd4, 0xe9, 0x9d, 0x38, 0x76, 0xbf,
+	0xdf, 0x28, 0xe2, 0x73, 0xd8, 0x7c, 0x3a, 0x0b, 0xce, 0x17, 0x6f, 0xad, 0xcf, 0x92, 0xec, 0x2c,
+	0x9e, 0xdd, 0xb6, 0xcc, 0x6e, 0xc1, 0x30, 0xc9, 0x10, 0x43, 0xd5, 0x0d, 0x82, 0x1e, 0xed, 0x86,
+	0xf1, 0x7b, 0x56, 0x64, 0xb1, 0xf3, 0x29, 0x1d, 0x1e, 0x02, 0x4a, 0x3b, 0xd3, 0x37, 0x5a, 0xf8,
+	0x8d, 0x84, 0x29, 0xfb, 0x64, 0x07, 0x20, 0x9a, 0x0d, 0x06, 0x24, 0x8a, 0xe4, 0x32, 0x4a, 0x94,
+	0x9d, 0x2f, 0xa7, 0x3b, 0xdf, 0x16, 0x14, 0x08, 0xa5, 0x21, 0x95, 0x27, 0x5f, 0x08, 0xf8, 0x0f,
+	0xb0, 0x91, 0xf6, 0xc3, 0x9a, 0xe6, 0x3d, 0x28, 0x51, 0xee, 0x30, 0x92, 0x9d, 0xef, 0x03, 0x99,
+	0xd2, 0x72, 0x48, 0x8e, 0xb2, 0x64, 0xb8, 0xe5, 0xae, 0x89, 0x47, 0x3c, 0xd9, 0x91, 0x12, 0x05,
+	0x3b, 0x8e, 0x43, 0xd7, 0x0f, 0x88, 0xa7, 0x9a, 0x92, 0x90, 0xf0, 0x7b, 0xa8, 0xd9, 0x97, 0xd3,
+	0x90, 0xea, 0x5e, 0xff, 0x13, 0x28, 0x0e, 0x43, 0x3a, 0x76, 0x63, 0x89, 0xb0, 0x0d, 0xe9, 0xfa,
+	0xd8, 0x0f, 0xc8, 0x31, 0x1f, 0x70, 0xa4, 0x81, 0x71, 0xfe, 0xb2, 0xdf, 0x7d, 0xfe, 0xf0, 0x8f,
+	0x60, 0x8d, 0xad, 0x71, 0xf4, 0x7e, 0x36, 0x39, 0x67, 0x40, 0xf2, 0xdc, 0xd8, 0xe5, 0x3e, 0xaa,
+	0x0e, 0xff, 0xc6, 0xdf, 0x5a, 0x50, 0xeb, 0x8c, 0xbf, 0x67, 0x2c, 0xdb, 0x50, 0xf4, 0xe8, 0xdc,
+	0x99, 0x4d, 0x64, 0x19, 0xa4, 0xb4, 0x54, 0xeb, 0xdc, 0x72, 0xad, 0x75, 0x30, 0x79, 0x23, 0x98,
+	0xbf, 0x58, 0x50, 0x55, 0xc1, 0xb0, 0xbf, 0xff, 0xc7, 0x9a, 0x18, 0xb9, 0xe4, 0xcd, 0x5c, 0xf0,
+	0x3f, 0x2d, 0x80, 0xd3, 0xe4, 0xfe, 0x41, 0x90, 0x1f, 0x84, 0x9e, 0xa6, 0x6b, 0xec, 0x1b, 0x1d,
+	0x40, 0x81, 0xdd, 0x28, 0x0c, 0x8c, 0x2c, 0xc6, 0x0f, 0x65, 0x8c, 0xc9, 0xac, 0x7d, 0x76, 0xcf,
+	0x47, 0xf6, 0x24, 0xa6, 0x73, 0x47, 0x98, 0x32, 0x77, 0xee, 0x20, 0xf6, 0x2f, 0x88, 0xdc, 0x1c,
+	0x29, 0xb1, 0x46, 0x30, 0x70, 0xa7, 0xee, 0xc0, 0x8f, 0x45, 0xcb, 0x2c, 0x38, 0x5a, 0x66, 0x63,
+	0x64, 0x42, 0xc3, 0x80, 0x05, 0x5f, 0x10, 0x63, 0x4a, 0x6e, 0x3d, 0x00, 0x48, 0x9c, 0xb0, 0x23,
+	0x73, 0x4e, 0xe6, 0x32, 0x48, 0xf6, 0xc9, 0x0e, 0xc2, 0x85, 0x1b, 0xcc, 0x14, 0xcb, 0x13, 0xc2,
+	0xc3, 0xec, 0x03, 0x0b, 0x7f, 0x0c, 0x1b, 0x49, 0xa4, 0x06, 0x2b, 0x5d, 0x4c, 0x13, 0x3f, 0x80,
+	0x6d, 0x46, 0x13, 0x12, 0xe3, 0x48, 0x59, 0xdf, 0x06, 0x10, 0xe1, 0xf7, 0x26, 0xc1, 0x5c, 0x52,
+	0x4f, 0x43, 0x83, 0x6d, 0xa8, 0x27, 0xb3, 0xd8, 0x1a, 0xe8, 0x1e, 0x54, 0x92, 0x4b, 0x5d, 0x15,
+	0x77, 0x63, 0x69, 0xe3, 0x1c, 0xd3, 0x0a, 0xff, 0xc9, 0x82, 0xad, 0x57, 0x53, 0xcf, 0x8d, 0xc9,
+	0x02, 0x55, 0xba, 0xbb, 0xd8, 0x8d, 0xae, 0xed, 0xb5, 0x0f, 0x01, 0x66, 0x7c, 0x95, 0x97, 0x6e,
+	0x74, 0x2e, 0x4f, 0x51, 0x6b, 0x5f, 0xbc, 0x03, 0xf6, 0xd5, 0x3b, 0x60, 0xff, 0x98, 0xbd, 0x03,
+	0x98, 0x85, 0x63, 0x58, 0xe3, 0x1d, 0xa8, 0x3f, 0xf3, 0xa3, 0x38, 0x4c, 0x6e, 0xd2, 0x05, 0xae,
+	0x85, 0xff, 0x65, 0x41, 0x55, 0x9a, 0x88, 0x5a, 0xc8, 0x4a, 0x6b, 0x36, 0x23, 0x25, 0x56, 0x11,
+	0x77, 0x10, 0x87, 0x8a, 0x93, 0x09, 0x81, 0x81, 0x97, 0x8a, 0x95, 0x3b, 0xaa, 0x8f, 0x25, 0x8a,
+	0x55, 0x57, 0x01, 0xda, 0x83, 0xe2, 0x3b, 0x32, 0x0c, 0xa9, 0x20, 0xdd, 0xab, 0x73, 0x97, 0x16,
+	0x68, 0x17, 0x0a, 0xee, 0x90, 0xf5, 0x8e, 0xe2, 0x95, 0xa6, 0xc2, 0x80, 0x73, 0x16, 0x42, 0x47,
+	0xc4, 0x7b, 0xe3, 0xc7, 0xef, 0x39, 0xab, 0x59, 0x73, 0x0c, 0x0d, 0x7e, 0x0c, 0x75, 0x39, 0x4b,
+	0x26, 0x8b, 0x3e, 0x81, 0x12, 0x99, 0xc4, 0xd4, 0x27, 0xaa, 0x9c, 0x9b, 0x72, 0x75, 0x73, 0x37,
+	0x1c, 0x65, 0x83, 0xbf, 0x81, 0x5b, 0xc7, 0xfe, 0xc4, 0x6b, 0x2b, 0x22, 0x6d, 0x82, 0xe9, 0x5a,
+	0x06, 0xb8, 0x03, 0x95, 0xb1, 0x7b, 0xd9, 0xf6, 0xa3, 0xd8, 0x9d, 0x0c, 0x14, 0x0d, 0x34, 0x55,
+	0x6c, 0x0f, 0xc9, 0xa5, 0x3b, 0x88, 0x39, 0x1a, 0xc5, 0xf1, 0x4a, 0x14, 0xf8, 0x09, 0xd4, 0xb5,
+	0xd3, 0x13, 0x1a, 0xce, 0xa6, 0x68, 0x1f, 0xca, 0x12, 0x1b, 0xd1, 0x35, 0xa4, 0x57, 0xdb, 0xe0,
+	0x27, 0xb0, 0xae, 0x57, 0x90, 0x8d, 0xea, 0x13, 0x28, 0x8e, 0xd8, 0x5a, 0x6a, 0x81, 0x5b, 0x8a,
+	0xc1, 0xa5, 0x3c, 0x39, 0xd2, 0x08, 0x7f, 0x9b, 0x85, 0xad, 0x97, 0x6c, 0x33, 0xa5, 0x03, 0x33,
+	0xf9, 0x68, 0x46, 0x2f, 0xfc, 0x8b, 0x90, 0x76, 0x14, 0xaa, 0x0c, 0x8d, 0x80, 0x47, 0xec, 0x53,
+	0xe2, 0x75, 0x3c, 0xc5, 0xcd, 0xb4, 0x82, 0xbd, 0x81, 0x94, 0xed, 0x6b, 0xf9, 0x8e, 0x12, 0x64,
+	0x62, 0x51, 0xcd, 0x98, 0x90, 0x9c, 0xa6, 0x0c, 0x05, 0xa4, 0x16, 0xb4, 0xe8, 0x23, 0xc8, 0xd3,
+	0x59, 0x20, 0xa0, 0x55, 0x3f, 0x68, 0xc8, 0xac, 0x78, 0xe8, 0xce, 0x2c, 0x20, 0x0e, 0x1f, 0x45,
+	0x5f, 0x42, 0x45, 0xf0, 0x2c, 0x3e, 0xb7, 0x59, 0xfc, 0xce, 0x23, 0x65, 0x9a, 0xe3, 0xbf, 0x59,
+	0x50, 0x3c, 0x0a, 0x67, 0x34, 0x22, 0x2b, 0xbb, 0x2b, 0x7b, 0x5f, 0xf8, 0x71, 0xa0, 0x3b, 0x17,
+	0x17, 0x18, 0x05, 0x18, 0x50, 0xe2, 0xf9, 0x71, 0x24, 0xfb, 0xb8, 0x12, 0x19, 0x3e, 0xcc, 0xd6,
+	0x92, 0xdf, 0xc9, 0xb1, 0x07, 0x9b, 0xa1, 0x4a, 0xf5, 0xd8, 0xc2, 0x35, 0x3d, 0xb6, 0x98, 0xee,
+	0xb1, 0xf8, 0xc7, 0x50, 0x13, 0x71, 0x5e, 0xd7, 0x25, 0xef, 0x03, 0x62, 0x1d, 0x4e, 0x18, 0xde,
+	0x14, 0xd4, 0xf8, 0x73, 0x00, 0x31, 0x83, 0xcd, 0x45, 0x1f, 0x43, 0x69, 0x20, 0xe6, 0x4b, 0x38,
+	0xd5, 0xe4, 0xc6, 0x4b, 0xf7, 0x6a, 0x14, 0x7f, 0x0d, 0x1b, 0x36, 0x8f, 0x6e, 0x6c, 0x74, 0x43,
+	0xf6, 0x12, 0xe7, 0xe3, 0x47, 0x49, 0x6c, 0x86, 0x86, 0xdf, 0x8f, 0x12, 0xd7, 0x1a, 0x43, 0x5a,
+	0x81, 0x7f, 0x0b, 0x90, 0x2c, 0xf9, 0xdf, 0xad, 0xa5, 0xdb, 0x55, 0xce, 0x60, 0xae, 0xaf, 0xc5,
+	0x2d, 0x92, 0xf8, 0x88, 0xfe, 0x37, 0x71, 0xdb, 0x50, 0x4f, 0xd6, 0x54, 0x77, 0x0c, 0x49, 0xbc,
+	0x2c, 0xdc, 0x31, 0xc6, 0xb6, 0x99, 0x56, 0x7b, 0x4f, 0xa0, 0x24, 0xdf, 0x26, 0x68, 0x1d, 0x2a,
+	0x82, 0x49, 0xbf, 0x3d, 0xeb, 0xbc, 0xb4, 0x1b, 0x19, 0xb4, 0x01, 0xb5, 0x97, 0xbd, 0x76, 0xe7,
+	0xb8, 0x63, 0xb7, 0x85, 0xca, 0x42, 0x65, 0xc8, 0x77, 0x0f, 0x5f, 0xda, 0x8d, 0x2c, 0x2a, 0x41,
+	0xee, 0xf0, 0xc4, 0x6e, 0xe4, 0xf6, 0x9e, 0x43, 0x2d, 0xf5, 0x6e, 0x43, 0x9b, 0xb0, 0x6e, 0xff,
+	0xea, 0xe8, 0xc5, 0xab, 0xb6, 0xfd, 0x56, 0xf1, 0xef, 0x0c, 0x53, 0x76, 0xba, 0x69, 0xa5, 0x85,
+	0x1a, 0x50, 0xed, 0x75, 0x5f, 0xfc, 0x5a, 0x6b, 0xb2, 0x7b, 0x3f, 0x05, 0x48, 0x78, 0x17, 0xf3,
+	0x71, 0xd4, 0x7f, 0xdd, 0xc8, 0x30, 0xb7, 0x5f, 0xf5, 0x7b, 0xdd, 0x86, 0xc5, 0x98, 0x7b, 0xb7,
+	0xcd, 0xbf, 0xb3, 0x7b, 0x7b, 0xb0, 0xa6, 0x4f, 0x26, 0x0b, 0xf6, 0xb9, 0x6d, 0x9f, 0xbe, 0xed,
+	0xbf, 0x72, 0x5e, 0x77, 0x5e, 0xf7, 0x9c, 0x46, 0x86, 0xdb, 0xda, 0x6f, 0xec, 0xfe, 0x59, 0xc3,
+	0x3a, 0xf8, 0x7b, 0x0d, 0x4a, 0x7d, 0xf1, 0x83, 0x18, 0xba, 0x0f, 0xe5, 0xbe, 0x3b, 0xe7, 0x3f,
+	0x60, 0x21, 0xdd, 0xb4, 0x8d, 0x9f, 0xbc, 0x5a, 0x1b, 0x69, 0xe5, 0x34, 0x98, 0xe3, 0x0c, 0x7a,
+	0x08, 0x65, 0xc5, 0xc0, 0xd0, 0x15, 0xec, 0xbf, 0xb5, 0xb5, 0xa4, 0x17, 0x73, 0x7f, 0x01, 0x05,
+	0xfe, 0x1c, 0x45, 0x2d, 0x69, 0xb0, 0xe2, 0x07, 0x90, 0xd6, 0x8a, 0x26, 0x8c, 0x33, 0xe8, 0xe7,
+	0xb0, 0x7e, 0xc8, 0xb6, 0xd5, 0xe4, 0x64, 0xcb, 0x86, 0xad, 0x9a, 0xf6, 0xcc, 0x48, 0x21, 0xce,
+	0xb0, 0x0e, 0x2d, 0xca, 0x72, 0x33, 0xf3, 0x87, 0x50, 0x77, 0x48, 0x14, 0x87, 0x54, 0xb5, 0xe8,
+	0x95, 0xd3, 0xae, 0x0a, 0x71, 0x8d, 0xe7, 0xc3, 0x51, 0xb8, 0x69, 0x66, 0x78, 0x45, 0x6a, 0xcc,
+	0x10, 0x67, 0xd0, 0x23, 0xa8, 0xf1, 0x27, 0xad, 0xd4, 0x46, 0x7a, 0xae, 0xf9, 0xd0, 0x6d, 0x6d,
+	0xae, 0x78, 0x7d, 0xe2, 0xcc, 0x67, 0x16, 0x7a, 0x06, 0x55, 0x93, 0x16, 0xeb, 0xbd, 0x5d, 0xf1,
+	0x7e, 0x6b, 0x35, 0x57, 0x8e, 0xf1, 0xe2, 0xec, 0x5a, 0xe8, 0x29, 0xd4, 0x52, 0x3c, 0x0b, 0xfd,
+	0x40, 0x9a, 0xaf, 0x62, 0x5f, 0x57, 0x6c, 0xc2, 0x11, 0x6c, 0x9c, 0x90, 0x78, 0x81, 0x23, 0xdc,
+	0x4a, 0x53, 0x02, 0xb5, 0xc2, 0xad, 0xf4, 0x0a, 0x72, 0x14, 0x67, 0xd0, 0x97, 0x50, 0x17, 0x0f,
+	0x25, 0xbd, 0x25, 0x0a, 0x51, 0xa9, 0xf7, 0x53, 0xab, 0x61, 0xbc, 0x51, 0xf8, 0x5b, 0x87, 0x6f,
+	0xc8, 0x63, 0xa8, 0x77, 0xc6, 0x2b, 0x67, 0xa7, 0x5e, 0x3c, 0xad, 0xcd, 0x05, 0x2d, 0xfb, 0xcb,
+	0xf7, 0xe1, 0x21, 0x34, 0x8e, 0xf8, 0x2f, 0x3d, 0x06, 0xd8, 0x96, 0x49, 0x6a, 0x6b, 0x59, 0x85,
+	0x33, 0xe8, 0x97, 0x50, 0x3b, 0x21, 0x06, 0x59, 0x46, 0xcd, 0x25, 0xab, 0xc5, 0xe3, 0x95, 0x9a,
+	0xdf, 0x81, 0xf5, 0x05, 0xb6, 0x8d, 0xd4, 0xef, 0x0e, 0xab, 0x59, 0xb8, 0xde, 0xc5, 0x34, 0xd5,
+	0xe6, 0x58, 0x6e, 0x88, 0xc2, 0x7d, 0x8f, 0x34, 0x1e, 0x41, 0x43, 0x1c, 0x9b, 0x1b, 0x65, 0xb2,
+	0x74, 0x8c, 0x9e, 0x41, 0x3d, 0xcd, 0xf2, 0xd0, 0x87, 0xba, 0x54, 0x2b, 0xc8, 0x5f, 0x6b, 0x7b,
+	0x91, 0x37, 0xa9, 0x6a, 0x30, 0x4c, 0xa6, 0x18, 0x93, 0xc6, 0xe4, 0x2a, 0x1e, 0x75, 0x05, 0x26,
+	0xf7, 0xa1, 0x2a, 0xea, 0x29, 0xe9, 0x46, 0xfa, 0x5a, 0x6d, 0xa5, 0x45, 0x9c, 0x41, 0x07, 0xb0,
+	0x76, 0x42, 0xe4, 0x55, 0xae, 0xb1, 0x93, 0xa2, 0x00, 0xcb, 0x73, 0x1e, 0x41, 0xc5, 0xb8, 0xff,
+	0xd1, 0x07, 0x46, 0xcd, 0xd2, 0x9c, 0x40, 0xef, 0x77, 0x72, 0xf1, 0x8b, 0x10, 0x45, 0xad, 0x6e,
+	0x18, 0xe2, 0xe7, 0x50, 0x15, 0xf5, 0xb9, 0x51, 0x94, 0xba, 0x2e, 0x5f, 0x40, 0x51, 0xdc, 0x80,
+	0xba, 0x98, 0x4b, 0x3c, 0xa2, 0xb5, 0x7c, 0x55, 0xe2, 0x0c, 0xfb, 0x25, 0xac, 0x4d, 0xc3, 0xe9,
+	0x35, 0xd3, 0x96, 0x7c, 0x49, 0x24, 0x27, 0x96, 0x69, 0x24, 0x2f, 0x33, 0x01, 0x8d, 0xe4, 0xf4,
+	0x85, 0x8e, 0x33, 0xef, 0x8a, 0x5c, 0x7f, 0xef, 0x3f, 0x03, 0x00, 0xaa, 0x3e, 0x40, 0x6e, 0xcc,
+	0x19, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// ServiceClient is the client API for Service service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ServiceClient interface {
+	// Sends a greeting
+	SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error)
+	// 创建学生信息
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterReply, error)
+	//查询学生信息
+	Query(ctx context.Context, in *QueryStudentRequest, opts ...grpc.CallOption) (*StudentInfo, error)
+	//修改学生专业
+	AlterProfession(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error)
+	//删除学生（软删除，保留期内可恢复），同时退掉所选课程，恢复后不会重新选课
+	Delete(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error)
+	//恢复已删除的学生
+	RestoreStudent(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*StudentInfo, error)
+	//查询所有学生信息
+	QueryList(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*StudentList, error)
+	//订阅学生变更事件
+	WatchStudents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Service_WatchStudentsClient, error)
+	//批量注册学生
+	BulkRegister(ctx context.Context, opts ...grpc.CallOption) (Service_BulkRegisterClient, error)
+	//按 updateMask 修改学生信息
+	UpdateStudent(ctx context.Context, in *UpdateStudentRequest, opts ...grpc.CallOption) (*StudentInfo, error)
+	//查询学生的变更历史
+	GetStudentHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*StudentHistory, error)
+	//按格式导出学生，文件内容分块返回
+	ExportStudents(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Service_ExportStudentsClient, error)
+	//从文件导入学生，第一条消息给出格式与选项，之后的消息为文件内容
+	ImportStudents(ctx context.Context, opts ...grpc.CallOption) (Service_ImportStudentsClient, error)
+	//新增专业
+	CreateProfession(ctx context.Context, in *Profession, opts ...grpc.CallOption) (*Profession, error)
+	//查询专业
+	GetProfession(ctx context.Context, in *ProfessionRequest, opts ...grpc.CallOption) (*Profession, error)
+	//查询专业目录
+	ListProfessions(ctx context.Context, in *ListProfessionsRequest, opts ...grpc.CallOption) (*ProfessionList, error)
+	//修改专业的名称、启用状态与人数上限
+	UpdateProfession(ctx context.Context, in *Profession, opts ...grpc.CallOption) (*Profession, error)
+	//删除专业，仍有学生（含已删除未清除的）时不可删除
+	DeleteProfession(ctx context.Context, in *ProfessionRequest, opts ...grpc.CallOption) (*Result, error)
+	//查找疑似重复注册的学生
+	FindDuplicates(ctx context.Context, in *FindDuplicatesRequest, opts ...grpc.CallOption) (*DuplicateReport, error)
+	//合并重复的学生，retiredId 的学生并入 survivorId 后被彻底删除，此后按 retiredId 或其学号查询返回合并后的学生
+	MergeStudents(ctx context.Context, in *MergeStudentsRequest, opts ...grpc.CallOption) (*StudentInfo, error)
+	//新增课程
+	CreateCourse(ctx context.Context, in *Course, opts ...grpc.CallOption) (*Course, error)
+	//查询课程
+	GetCourse(ctx context.Context, in *CourseRequest, opts ...grpc.CallOption) (*Course, error)
+	//查询课程列表
+	ListCourses(ctx context.Context, in *ListCoursesRequest, opts ...grpc.CallOption) (*CourseList, error)
+	//修改课程的名称、学分、可选专业与人数上限
+	UpdateCourse(ctx context.Context, in *Course, opts ...grpc.CallOption) (*Course, error)
+	//删除课程，仍有学生选课时不可删除
+	DeleteCourse(ctx context.Context, in *CourseRequest, opts ...grpc.CallOption) (*Result, error)
+	//选课，课程已满或学生的专业不在可选专业中时失败
+	Enroll(ctx context.Context, in *EnrollmentRequest, opts ...grpc.CallOption) (*Enrollment, error)
+	//退课
+	Drop(ctx context.Context, in *EnrollmentRequest, opts ...grpc.CallOption) (*Result, error)
+	//按课程或按学生查询选课记录
+	ListEnrollments(ctx context.Context, in *ListEnrollmentsRequest, opts ...grpc.CallOption) (*EnrollmentList, error)
+}
+
+type serviceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServiceClient(cc grpc.ClientConnInterface) ServiceClient {
+	return &serviceClient{cc}
+}
+
+func (c *serviceClient) SayHello(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloReply, error) {
+	out := new(HelloReply)
+	err := c.cc.Invoke(ctx, "/proto.Service/SayHello", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterReply, error) {
+	out := new(RegisterReply)
+	err := c.cc.Invoke(ctx, "/proto.Service/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Query(ctx context.Context, in *QueryStudentRequest, opts ...grpc.CallOption) (*StudentInfo, error) {
+	out := new(StudentInfo)
+	err := c.cc.Invoke(ctx, "/proto.Service/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) AlterProfession(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/proto.Service/AlterProfession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Delete(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/proto.Service/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) RestoreStudent(ctx context.Context, in *StudentInfo, opts ...grpc.CallOption) (*StudentInfo, error) {
+	out := new(StudentInfo)
+	err := c.cc.Invoke(ctx, "/proto.Service/RestoreStudent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) QueryList(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*StudentList, error) {
+	out := new(StudentList)
+	err := c.cc.Invoke(ctx, "/proto.Service/QueryList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) WatchStudents(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Service_WatchStudentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Service_serviceDesc.Streams[0], "/proto.Service/WatchStudents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceWatchStudentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Service_WatchStudentsClient interface {
+	Recv() (*StudentEvent, error)
+	grpc.ClientStream
+}
+
+type serviceWatchStudentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceWatchStudentsClient) Recv() (*StudentEvent, error) {
+	m := new(StudentEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) BulkRegister(ctx context.Context, opts ...grpc.CallOption) (Service_BulkRegisterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Service_serviceDesc.Streams[1], "/proto.Service/BulkRegister", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceBulkRegisterClient{stream}
+	return x, nil
+}
+
+type Service_BulkRegisterClient interface {
+	Send(*BulkRegisterRequest) error
+	CloseAndRecv() (*BulkRegisterReply, error)
+	grpc.ClientStream
+}
+
+type serviceBulkRegisterClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceBulkRegisterClient) Send(m *BulkRegisterRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceBulkRegisterClient) CloseAndRecv() (*BulkRegisterReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkRegisterReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) UpdateStudent(ctx context.Context, in *UpdateStudentRequest, opts ...grpc.CallOption) (*StudentInfo, error) {
+	out := new(StudentInfo)
+	err := c.cc.Invoke(ctx, "/proto.Service/UpdateStudent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetStudentHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*StudentHistory, error) {
+	out := new(StudentHistory)
+	err := c.cc.Invoke(ctx, "/proto.Service/GetStudentHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ExportStudents(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Service_ExportStudentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Service_serviceDesc.Streams[2], "/proto.Service/ExportStudents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceExportStudentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Service_ExportStudentsClient interface {
+	Recv() (*FileChunk, error)
+	grpc.ClientStream
+}
+
+type serviceExportStudentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceExportStudentsClient) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) ImportStudents(ctx context.Context, opts ...grpc.CallOption) (Service_ImportStudentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Service_serviceDesc.Streams[3], "/proto.Service/ImportStudents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceImportStudentsClient{stream}
+	return x, nil
+}
+
+type Service_ImportStudentsClient interface {
+	Send(*ImportRequest) error
+	CloseAndRecv() (*ImportReport, error)
+	grpc.ClientStream
+}
+
+type serviceImportStudentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceImportStudentsClient) Send(m *ImportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceImportStudentsClient) CloseAndRecv() (*ImportReport, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportReport)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) CreateProfession(ctx context.Context, in *Profession, opts ...grpc.CallOption) (*Profession, error) {
+	out := new(Profession)
+	err := c.cc.Invoke(ctx, "/proto.Service/CreateProfession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetProfession(ctx context.Context, in *ProfessionRequest, opts ...grpc.CallOption) (*Profession, error) {
+	out := new(Profession)
+	err := c.cc.Invoke(ctx, "/proto.Service/GetProfession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ListProfessions(ctx context.Context, in *ListProfessionsRequest, opts ...grpc.CallOption) (*ProfessionList, error) {
+	out := new(ProfessionList)
+	err := c.cc.Invoke(ctx, "/proto.Service/ListProfessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) UpdateProfession(ctx context.Context, in *Profession, opts ...grpc.CallOption) (*Profession, error) {
+	out := new(Profession)
+	err := c.cc.Invoke(ctx, "/proto.Service/UpdateProfession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) DeleteProfession(ctx context.Context, in *ProfessionRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/proto.Service/DeleteProfession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) FindDuplicates(ctx context.Context, in *FindDuplicatesRequest, opts ...grpc.CallOption) (*DuplicateReport, error) {
+	out := new(DuplicateReport)
+	err := c.cc.Invoke(ctx, "/proto.Service/FindDuplicates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) MergeStudents(ctx context.Context, in *MergeStudentsRequest, opts ...grpc.CallOption) (*StudentInfo, error) {
+	out := new(StudentInfo)
+	err := c.cc.Invoke(ctx, "/proto.Service/MergeStudents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) CreateCourse(ctx context.Context, in *Course, opts ...grpc.CallOption) (*Course, error) {
+	out := new(Course)
+	err := c.cc.Invoke(ctx, "/proto.Service/CreateCourse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetCourse(ctx context.Context, in *CourseRequest, opts ...grpc.CallOption) (*Course, error) {
+	out := new(Course)
+	err := c.cc.Invoke(ctx, "/proto.Service/GetCourse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ListCourses(ctx context.Context, in *ListCoursesRequest, opts ...grpc.CallOption) (*CourseList, error) {
+	out := new(CourseList)
+	err := c.cc.Invoke(ctx, "/proto.Service/ListCourses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) UpdateCourse(ctx context.Context, in *Course, opts ...grpc.CallOption) (*Course, error) {
+	out := new(Course)
+	err := c.cc.Invoke(ctx, "/proto.Service/UpdateCourse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) DeleteCourse(ctx context.Context, in *CourseRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/proto.Service/DeleteCourse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Enroll(ctx context.Context, in *EnrollmentRequest, opts ...grpc.CallOption) (*Enrollment, error) {
+	out := new(Enrollment)
+	err := c.cc.Invoke(ctx, "/proto.Service/Enroll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Drop(ctx context.Context, in *EnrollmentRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/proto.Service/Drop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) ListEnrollments(ctx context.Context, in *ListEnrollmentsRequest, opts ...grpc.CallOption) (*EnrollmentList, error) {
+	out := new(EnrollmentList)
+	err := c.cc.Invoke(ctx, "/proto.Service/ListEnrollments", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
-func (c *serviceClient) QueryList(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*StudentList, error) {
-	out := new(StudentList)
-	err := c.cc.Invoke(ctx, "/proto.Service/QueryList", in, out, opts...)
-	if err != nil {
+// ServiceServer is the server API for Service service.
+type ServiceServer interface {
+	// Sends a greeting
+	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+	// 创建学生信息
+	Register(context.Context, *RegisterRequest) (*RegisterReply, error)
+	//查询学生信息
+	Query(context.Context, *QueryStudentRequest) (*StudentInfo, error)
+	//修改学生专业
+	AlterProfession(context.Context, *StudentInfo) (*Result, error)
+	//删除学生（软删除，保留期内可恢复），同时退掉所选课程，恢复后不会重新选课
+	Delete(context.Context, *StudentInfo) (*Result, error)
+	//恢复已删除的学生
+	RestoreStudent(context.Context, *StudentInfo) (*StudentInfo, error)
+	//查询所有学生信息
+	QueryList(context.Context, *QueryRequest) (*StudentList, error)
+	//订阅学生变更事件
+	WatchStudents(*WatchRequest, Service_WatchStudentsServer) error
+	//批量注册学生
+	BulkRegister(Service_BulkRegisterServer) error
+	//按 updateMask 修改学生信息
+	UpdateStudent(context.Context, *UpdateStudentRequest) (*StudentInfo, error)
+	//查询学生的变更历史
+	GetStudentHistory(context.Context, *HistoryRequest) (*StudentHistory, error)
+	//按格式导出学生，文件内容分块返回
+	ExportStudents(*ExportRequest, Service_ExportStudentsServer) error
+	//从文件导入学生，第一条消息给出格式与选项，之后的消息为文件内容
+	ImportStudents(Service_ImportStudentsServer) error
+	//新增专业
+	CreateProfession(context.Context, *Profession) (*Profession, error)
+	//查询专业
+	GetProfession(context.Context, *ProfessionRequest) (*Profession, error)
+	//查询专业目录
+	ListProfessions(context.Context, *ListProfessionsRequest) (*ProfessionList, error)
+	//修改专业的名称、启用状态与人数上限
+	UpdateProfession(context.Context, *Profession) (*Profession, error)
+	//删除专业，仍有学生（含已删除未清除的）时不可删除
+	DeleteProfession(context.Context, *ProfessionRequest) (*Result, error)
+	//查找疑似重复注册的学生
+	FindDuplicates(context.Context, *FindDuplicatesRequest) (*DuplicateReport, error)
+	//合并重复的学生，retiredId 的学生并入 survivorId 后被彻底删除，此后按 retiredId 或其学号查询返回合并后的学生
+	MergeStudents(context.Context, *MergeStudentsRequest) (*StudentInfo, error)
+	//新增课程
+	CreateCourse(context.Context, *Course) (*Course, error)
+	//查询课程
+	GetCourse(context.Context, *CourseRequest) (*Course, error)
+	//查询课程列表
+	ListCourses(context.Context, *ListCoursesRequest) (*CourseList, error)
+	//修改课程的名称、学分、可选专业与人数上限
+	UpdateCourse(context.Context, *Course) (*Course, error)
+	//删除课程，仍有学生选课时不可删除
+	DeleteCourse(context.Context, *CourseRequest) (*Result, error)
+	//选课，课程已满或学生的专业不在可选专业中时失败
+	Enroll(context.Context, *EnrollmentRequest) (*Enrollment, error)
+	//退课
+	Drop(context.Context, *EnrollmentRequest) (*Result, error)
+	//按课程或按学生查询选课记录
+	ListEnrollments(context.Context, *ListEnrollmentsRequest) (*EnrollmentList, error)
+}
+
+// UnimplementedServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedServiceServer struct {
+}
+
+func (*UnimplementedServiceServer) SayHello(ctx context.Context, req *HelloRequest) (*HelloReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SayHello not implemented")
+}
+func (*UnimplementedServiceServer) Register(ctx context.Context, req *RegisterRequest) (*RegisterReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (*UnimplementedServiceServer) Query(ctx context.Context, req *QueryStudentRequest) (*StudentInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (*UnimplementedServiceServer) AlterProfession(ctx context.Context, req *StudentInfo) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AlterProfession not implemented")
+}
+func (*UnimplementedServiceServer) Delete(ctx context.Context, req *StudentInfo) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedServiceServer) RestoreStudent(ctx context.Context, req *StudentInfo) (*StudentInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreStudent not implemented")
+}
+func (*UnimplementedServiceServer) QueryList(ctx context.Context, req *QueryRequest) (*StudentList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryList not implemented")
+}
+func (*UnimplementedServiceServer) WatchStudents(req *WatchRequest, srv Service_WatchStudentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStudents not implemented")
+}
+func (*UnimplementedServiceServer) BulkRegister(srv Service_BulkRegisterServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkRegister not implemented")
+}
+func (*UnimplementedServiceServer) UpdateStudent(ctx context.Context, req *UpdateStudentRequest) (*StudentInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateStudent not implemented")
+}
+func (*UnimplementedServiceServer) GetStudentHistory(ctx context.Context, req *HistoryRequest) (*StudentHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStudentHistory not implemented")
+}
+func (*UnimplementedServiceServer) ExportStudents(req *ExportRequest, srv Service_ExportStudentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportStudents not implemented")
+}
+func (*UnimplementedServiceServer) ImportStudents(srv Service_ImportStudentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportStudents not implemented")
+}
+func (*UnimplementedServiceServer) CreateProfession(ctx context.Context, req *Profession) (*Profession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProfession not implemented")
+}
+func (*UnimplementedServiceServer) GetProfession(ctx context.Context, req *ProfessionRequest) (*Profession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProfession not implemented")
+}
+func (*UnimplementedServiceServer) ListProfessions(ctx context.Context, req *ListProfessionsRequest) (*ProfessionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProfessions not implemented")
+}
+func (*UnimplementedServiceServer) UpdateProfession(ctx context.Context, req *Profession) (*Profession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProfession not implemented")
+}
+func (*UnimplementedServiceServer) DeleteProfession(ctx context.Context, req *ProfessionRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProfession not implemented")
+}
+func (*UnimplementedServiceServer) FindDuplicates(ctx context.Context, req *FindDuplicatesRequest) (*DuplicateReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindDuplicates not implemented")
+}
+func (*UnimplementedServiceServer) MergeStudents(ctx context.Context, req *MergeStudentsRequest) (*StudentInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeStudents not implemented")
+}
+func (*UnimplementedServiceServer) CreateCourse(ctx context.Context, req *Course) (*Course, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCourse not implemented")
+}
+func (*UnimplementedServiceServer) GetCourse(ctx context.Context, req *CourseRequest) (*Course, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCourse not implemented")
+}
+func (*UnimplementedServiceServer) ListCourses(ctx context.Context, req *ListCoursesRequest) (*CourseList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCourses not implemented")
+}
+func (*UnimplementedServiceServer) UpdateCourse(ctx context.Context, req *Course) (*Course, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCourse not implemented")
+}
+func (*UnimplementedServiceServer) DeleteCourse(ctx context.Context, req *CourseRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCourse not implemented")
+}
+func (*UnimplementedServiceServer) Enroll(ctx context.Context, req *EnrollmentRequest) (*Enrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Enroll not implemented")
+}
+func (*UnimplementedServiceServer) Drop(ctx context.Context, req *EnrollmentRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drop not implemented")
+}
+func (*UnimplementedServiceServer) ListEnrollments(ctx context.Context, req *ListEnrollmentsRequest) (*EnrollmentList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEnrollments not implemented")
+}
+
+func RegisterServiceServer(s *grpc.Server, srv ServiceServer) {
+	s.RegisterService(&_Service_serviceDesc, srv)
+}
+
+func _Service_SayHello_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HelloRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).SayHello(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/SayHello",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).SayHello(ctx, req.(*HelloRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryStudentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Query(ctx, req.(*QueryStudentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_AlterProfession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StudentInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).AlterProfession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/AlterProfession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).AlterProfession(ctx, req.(*StudentInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StudentInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Delete(ctx, req.(*StudentInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_RestoreStudent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StudentInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).RestoreStudent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/RestoreStudent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).RestoreStudent(ctx, req.(*StudentInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_QueryList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).QueryList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/QueryList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).QueryList(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_WatchStudents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceServer).WatchStudents(m, &serviceWatchStudentsServer{stream})
+}
+
+type Service_WatchStudentsServer interface {
+	Send(*StudentEvent) error
+	grpc.ServerStream
+}
+
+type serviceWatchStudentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceWatchStudentsServer) Send(m *StudentEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Service_BulkRegister_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceServer).BulkRegister(&serviceBulkRegisterServer{stream})
+}
+
+type Service_BulkRegisterServer interface {
+	SendAndClose(*BulkRegisterReply) error
+	Recv() (*BulkRegisterRequest, error)
+	grpc.ServerStream
+}
+
+type serviceBulkRegisterServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceBulkRegisterServer) SendAndClose(m *BulkRegisterReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceBulkRegisterServer) Recv() (*BulkRegisterRequest, error) {
+	m := new(BulkRegisterRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Service_UpdateStudent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateStudentRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ServiceServer).UpdateStudent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/UpdateStudent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).UpdateStudent(ctx, req.(*UpdateStudentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// ServiceServer is the server API for Service service.
-type ServiceServer interface {
-	// Sends a greeting
-	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
-	// 创建学生信息
-	Register(context.Context, *RegisterRequest) (*RegisterReply, error)
-	//查询学生信息
-	Query(context.Context, *StudentInfo) (*StudentInfo, error)
-	//修改学生专业
-	AlterProfession(context.Context, *StudentInfo) (*Result, error)
-	//删除学生
-	Delete(context.Context, *StudentInfo) (*Result, error)
-	//查询所有学生信息
-	QueryList(context.Context, *QueryRequest) (*StudentList, error)
+func _Service_GetStudentHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetStudentHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/GetStudentHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetStudentHistory(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedServiceServer struct {
+func _Service_ExportStudents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceServer).ExportStudents(m, &serviceExportStudentsServer{stream})
 }
 
-func (*UnimplementedServiceServer) SayHello(ctx context.Context, req *HelloRequest) (*HelloReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SayHello not implemented")
+type Service_ExportStudentsServer interface {
+	Send(*FileChunk) error
+	grpc.ServerStream
 }
-func (*UnimplementedServiceServer) Register(ctx context.Context, req *RegisterRequest) (*RegisterReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+
+type serviceExportStudentsServer struct {
+	grpc.ServerStream
 }
-func (*UnimplementedServiceServer) Query(ctx context.Context, req *StudentInfo) (*StudentInfo, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+
+func (x *serviceExportStudentsServer) Send(m *FileChunk) error {
+	return x.ServerStream.SendMsg(m)
 }
-func (*UnimplementedServiceServer) AlterProfession(ctx context.Context, req *StudentInfo) (*Result, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AlterProfession not implemented")
+
+func _Service_ImportStudents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceServer).ImportStudents(&serviceImportStudentsServer{stream})
 }
-func (*UnimplementedServiceServer) Delete(ctx context.Context, req *StudentInfo) (*Result, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+
+type Service_ImportStudentsServer interface {
+	SendAndClose(*ImportReport) error
+	Recv() (*ImportRequest, error)
+	grpc.ServerStream
 }
-func (*UnimplementedServiceServer) QueryList(ctx context.Context, req *QueryRequest) (*StudentList, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method QueryList not implemented")
+
+type serviceImportStudentsServer struct {
+	grpc.ServerStream
 }
 
-func RegisterServiceServer(s *grpc.Server, srv ServiceServer) {
-	s.RegisterService(&_Service_serviceDesc, srv)
+func (x *serviceImportStudentsServer) SendAndClose(m *ImportReport) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func _Service_SayHello_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HelloRequest)
+func (x *serviceImportStudentsServer) Recv() (*ImportRequest, error) {
+	m := new(ImportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Service_CreateProfession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Profession)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).SayHello(ctx, in)
+		return srv.(ServiceServer).CreateProfession(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Service/SayHello",
+		FullMethod: "/proto.Service/CreateProfession",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).SayHello(ctx, req.(*HelloRequest))
+		return srv.(ServiceServer).CreateProfession(ctx, req.(*Profession))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterRequest)
+func _Service_GetProfession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProfessionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).Register(ctx, in)
+		return srv.(ServiceServer).GetProfession(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Service/Register",
+		FullMethod: "/proto.Service/GetProfession",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).Register(ctx, req.(*RegisterRequest))
+		return srv.(ServiceServer).GetProfession(ctx, req.(*ProfessionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StudentInfo)
+func _Service_ListProfessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProfessionsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).Query(ctx, in)
+		return srv.(ServiceServer).ListProfessions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Service/Query",
+		FullMethod: "/proto.Service/ListProfessions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).Query(ctx, req.(*StudentInfo))
+		return srv.(ServiceServer).ListProfessions(ctx, req.(*ListProfessionsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_AlterProfession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StudentInfo)
+func _Service_UpdateProfession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Profession)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).AlterProfession(ctx, in)
+		return srv.(ServiceServer).UpdateProfession(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Service/AlterProfession",
+		FullMethod: "/proto.Service/UpdateProfession",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).AlterProfession(ctx, req.(*StudentInfo))
+		return srv.(ServiceServer).UpdateProfession(ctx, req.(*Profession))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StudentInfo)
+func _Service_DeleteProfession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProfessionRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).Delete(ctx, in)
+		return srv.(ServiceServer).DeleteProfession(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Service/Delete",
+		FullMethod: "/proto.Service/DeleteProfession",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).Delete(ctx, req.(*StudentInfo))
+		return srv.(ServiceServer).DeleteProfession(ctx, req.(*ProfessionRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Service_QueryList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(QueryRequest)
+func _Service_FindDuplicates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindDuplicatesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ServiceServer).QueryList(ctx, in)
+		return srv.(ServiceServer).FindDuplicates(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/proto.Service/QueryList",
+		FullMethod: "/proto.Service/FindDuplicates",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ServiceServer).QueryList(ctx, req.(*QueryRequest))
+		return srv.(ServiceServer).FindDuplicates(ctx, req.(*FindDuplicatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_MergeStudents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeStudentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).MergeStudents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/MergeStudents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).MergeStudents(ctx, req.(*MergeStudentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_CreateCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Course)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).CreateCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/CreateCourse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).CreateCourse(ctx, req.(*Course))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/GetCourse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetCourse(ctx, req.(*CourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ListCourses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCoursesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ListCourses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/ListCourses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ListCourses(ctx, req.(*ListCoursesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_UpdateCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Course)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).UpdateCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/UpdateCourse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).UpdateCourse(ctx, req.(*Course))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_DeleteCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).DeleteCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/DeleteCourse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).DeleteCourse(ctx, req.(*CourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Enroll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Enroll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/Enroll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Enroll(ctx, req.(*EnrollmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Drop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Drop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/Drop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Drop(ctx, req.(*EnrollmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_ListEnrollments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEnrollmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ListEnrollments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Service/ListEnrollments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ListEnrollments(ctx, req.(*ListEnrollmentsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -697,11 +3714,104 @@ var _Service_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _Service_Delete_Handler,
 		},
+		{
+			MethodName: "RestoreStudent",
+			Handler:    _Service_RestoreStudent_Handler,
+		},
 		{
 			MethodName: "QueryList",
 			Handler:    _Service_QueryList_Handler,
 		},
+		{
+			MethodName: "UpdateStudent",
+			Handler:    _Service_UpdateStudent_Handler,
+		},
+		{
+			MethodName: "GetStudentHistory",
+			Handler:    _Service_GetStudentHistory_Handler,
+		},
+		{
+			MethodName: "CreateProfession",
+			Handler:    _Service_CreateProfession_Handler,
+		},
+		{
+			MethodName: "GetProfession",
+			Handler:    _Service_GetProfession_Handler,
+		},
+		{
+			MethodName: "ListProfessions",
+			Handler:    _Service_ListProfessions_Handler,
+		},
+		{
+			MethodName: "UpdateProfession",
+			Handler:    _Service_UpdateProfession_Handler,
+		},
+		{
+			MethodName: "DeleteProfession",
+			Handler:    _Service_DeleteProfession_Handler,
+		},
+		{
+			MethodName: "FindDuplicates",
+			Handler:    _Service_FindDuplicates_Handler,
+		},
+		{
+			MethodName: "MergeStudents",
+			Handler:    _Service_MergeStudents_Handler,
+		},
+		{
+			MethodName: "CreateCourse",
+			Handler:    _Service_CreateCourse_Handler,
+		},
+		{
+			MethodName: "GetCourse",
+			Handler:    _Service_GetCourse_Handler,
+		},
+		{
+			MethodName: "ListCourses",
+			Handler:    _Service_ListCourses_Handler,
+		},
+		{
+			MethodName: "UpdateCourse",
+			Handler:    _Service_UpdateCourse_Handler,
+		},
+		{
+			MethodName: "DeleteCourse",
+			Handler:    _Service_DeleteCourse_Handler,
+		},
+		{
+			MethodName: "Enroll",
+			Handler:    _Service_Enroll_Handler,
+		},
+		{
+			MethodName: "Drop",
+			Handler:    _Service_Drop_Handler,
+		},
+		{
+			MethodName: "ListEnrollments",
+			Handler:    _Service_ListEnrollments_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStudents",
+			Handler:       _Service_WatchStudents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BulkRegister",
+			Handler:       _Service_BulkRegister_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportStudents",
+			Handler:       _Service_ExportStudents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportStudents",
+			Handler:       _Service_ImportStudents_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoAlias = errors.New("student id is not retired")
+
+// Alias records that a student was merged into another one, so that its id
+// keeps resolving to the student that survived.
+type Alias struct {
+	RetiredId  string
+	SurvivorId string
+	Time       int64 // unix seconds
+}
+
+// AliasStore keeps the aliases of retired student ids.
+type AliasStore interface {
+	// Put records a, replacing any alias recorded for the same retired id.
+	Put(a Alias) error
+	// Get returns the alias of a retired id or ErrNoAlias.
+	Get(retiredId string) (Alias, error)
+	// Delete removes the alias of a retired id, if there is one.
+	Delete(retiredId string) error
+}
+
+// MemoryAliases keeps the aliases in a map.
+type MemoryAliases struct {
+	mux     sync.RWMutex
+	aliases map[string]Alias
+}
+
+func NewMemoryAliases() *MemoryAliases {
+	return &MemoryAliases{aliases: make(map[string]Alias)}
+}
+
+func (m *MemoryAliases) Put(a Alias) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.aliases[a.RetiredId] = a
+	return nil
+}
+
+func (m *MemoryAliases) Get(retiredId string) (Alias, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	a, ok := m.aliases[retiredId]
+	if !ok {
+		return Alias{}, ErrNoAlias
+	}
+	return a, nil
+}
+
+func (m *MemoryAliases) Delete(retiredId string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.aliases, retiredId)
+	return nil
+}
+
+// All returns every alias, oldest first.
+func (m *MemoryAliases) All() []Alias {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	all := make([]Alias, 0, len(m.aliases))
+	for _, a := range m.aliases {
+		all = append(all, a)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Time != all[j].Time {
+			return all[i].Time < all[j].Time
+		}
+		return all[i].RetiredId < all[j].RetiredId
+	})
+	return all
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrCourseNotFound      = errors.New("course is not exist")
+	ErrCourseAlreadyExists = errors.New("course already exist")
+)
+
+// Course is a course students enroll in. Enrollments refer to it by Code.
+type Course struct {
+	Code        string
+	Title       string
+	Credits     int32
+	Professions []string // 可选该课程的专业代码，空表示不限
+	Capacity    int32    // 选课人数上限，0 表示不限
+}
+
+// CourseStore keeps the courses. Whether a course is still taken is up to
+// the caller to check, since enrollments live elsewhere.
+type CourseStore interface {
+	// Create fails with ErrCourseAlreadyExists if the code is taken.
+	Create(c Course) error
+	// Get returns the course with the given code or ErrCourseNotFound.
+	Get(code string) (Course, error)
+	// List returns every course ordered by code.
+	List() ([]Course, error)
+	// Update replaces an existing course or returns ErrCourseNotFound.
+	Update(c Course) error
+	// Delete removes a course or returns ErrCourseNotFound.
+	Delete(code string) error
+}
+
+type MemoryCourses struct {
+	mux     sync.RWMutex
+	courses map[string]Course
+}
+
+// NewMemoryCourses returns a store holding initial.
+func NewMemoryCourses(initial []Course) *MemoryCourses {
+	m := &MemoryCourses{courses: make(map[string]Course)}
+	for _, c := range initial {
+		m.courses[c.Code] = copyCourse(c)
+	}
+	return m
+}
+
+// copyCourse keeps callers from sharing the Professions slice with the
+// store.
+func copyCourse(c Course) Course {
+	c.Professions = append([]string{}, c.Professions...)
+	return c
+}
+
+func (m *MemoryCourses) Create(c Course) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.courses[c.Code]; ok {
+		return ErrCourseAlreadyExists
+	}
+	m.courses[c.Code] = copyCourse(c)
+	return nil
+}
+
+func (m *MemoryCourses) Get(code string) (Course, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	c, ok := m.courses[code]
+	if !ok {
+		return Course{}, ErrCourseNotFound
+	}
+	return copyCourse(c), nil
+}
+
+func (m *MemoryCourses) List() ([]Course, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	list := make([]Course, 0, len(m.courses))
+	for _, c := range m.courses {
+		list = append(list, copyCourse(c))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list, nil
+}
+
+func (m *MemoryCourses) Update(c Course) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.courses[c.Code]; !ok {
+		return ErrCourseNotFound
+	}
+	m.courses[c.Code] = copyCourse(c)
+	return nil
+}
+
+func (m *MemoryCourses) Delete(code string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.courses[code]; !ok {
+		return ErrCourseNotFound
+	}
+	delete(m.courses, code)
+	return nil
+}
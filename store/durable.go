@@ -0,0 +1,258 @@
+package store
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type DurableOptions struct {
+	// SnapshotEvery compacts the log after this many records. Zero means 1000.
+	SnapshotEvery int
+	// SnapshotInterval additionally compacts the log on a timer when non-zero.
+	SnapshotInterval time.Duration
+}
+
+// DurableStore is a MemoryStore whose writes are first appended to a
+// write-ahead log in dir. The log is compacted into a snapshot periodically
+// and both are replayed by OpenDurableStore on startup.
+type DurableStore struct {
+	mem  *MemoryStore
+	dir  string
+	opts DurableOptions
+
+	mux     sync.Mutex // serializes writers, guards everything below
+	wal     *os.File
+	offset  int64 // end of the last intact record in wal
+	seq     uint64
+	pending int
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func OpenDurableStore(dir string, opts DurableOptions) (*DurableStore, error) {
+	if opts.SnapshotEvery <= 0 {
+		opts.SnapshotEvery = 1000
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	snap, err := readSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	d := &DurableStore{mem: NewMemoryStore(), dir: dir, opts: opts, seq: snap.Seq}
+	for _, stu := range snap.Students {
+		Upgrade(&stu)
+		d.mem.Put(stu)
+	}
+
+	wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	end, err := replayWAL(wal, func(rec walRecord) {
+		if rec.Seq <= d.seq {
+			return
+		}
+		d.seq = rec.Seq
+		d.pending++
+		switch rec.Op {
+		case opPut:
+			Upgrade(&rec.Student)
+			d.mem.Put(rec.Student)
+		case opPutAll:
+			for _, stu := range rec.Students {
+				Upgrade(&stu)
+				d.mem.Put(stu)
+			}
+		case opDelete:
+			d.mem.remove(rec.Student.Id)
+		}
+	})
+	if err != nil {
+		wal.Close()
+		return nil, err
+	}
+	// Drop a torn tail left behind by a crash so new records follow valid ones.
+	if err := wal.Truncate(end); err != nil {
+		wal.Close()
+		return nil, err
+	}
+	if _, err := wal.Seek(end, 0); err != nil {
+		wal.Close()
+		return nil, err
+	}
+	d.wal = wal
+	d.offset = end
+	log.Printf("recovered %d students from %v (seq %d)", d.mem.len(), dir, d.seq)
+
+	if opts.SnapshotInterval > 0 {
+		d.stop = make(chan struct{})
+		d.done = make(chan struct{})
+		go d.snapshotLoop()
+	}
+	return d, nil
+}
+
+func (d *DurableStore) snapshotLoop() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.opts.SnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := d.Snapshot(); err != nil {
+				log.Printf("snapshot failed: %v", err)
+			}
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func (d *DurableStore) Create(s Student) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if _, err := d.mem.Get(s.Id); err == nil {
+		return ErrAlreadyExists
+	}
+	stored := s
+	stored.Version = 1
+	return d.commitLocked(walRecord{Op: opPut, Student: stored}, func() error { return d.mem.Create(s) })
+}
+
+// CreateAll writes the students as one record, so that a crash keeps all of
+// them or none.
+func (d *DurableStore) CreateAll(students []Student) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	stored := make([]Student, len(students))
+	seen := make(map[string]bool, len(students))
+	for i, s := range students {
+		if _, err := d.mem.Get(s.Id); err == nil || seen[s.Id] {
+			return ErrAlreadyExists
+		}
+		seen[s.Id] = true
+		stored[i] = s
+		stored[i].Version = 1
+	}
+	return d.commitLocked(walRecord{Op: opPutAll, Students: stored}, func() error { return d.mem.CreateAll(students) })
+}
+
+func (d *DurableStore) Get(id string) (Student, error) {
+	return d.mem.Get(id)
+}
+
+func (d *DurableStore) Update(s Student) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	old, err := d.mem.Get(s.Id)
+	if err != nil {
+		return err
+	}
+	if old.Version != s.Version {
+		return ErrVersionMismatch
+	}
+	stored := s
+	stored.Version++
+	return d.commitLocked(walRecord{Op: opPut, Student: stored}, func() error { return d.mem.Update(s) })
+}
+
+func (d *DurableStore) Delete(id string, version int64) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	old, err := d.mem.Get(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && old.Version != version {
+		return ErrVersionMismatch
+	}
+	return d.commitLocked(walRecord{Op: opDelete, Student: Student{Id: id}}, func() error { return d.mem.Delete(id, version) })
+}
+
+func (d *DurableStore) List(q ListQuery) (ListResult, error) {
+	return d.mem.List(q)
+}
+
+// commitLocked writes and syncs rec under the next sequence number and only
+// then applies the change in memory. The caller holds d.mux.
+func (d *DurableStore) commitLocked(rec walRecord, apply func() error) error {
+	if d.closed {
+		return os.ErrClosed
+	}
+	rec.Seq = d.seq + 1
+	n, err := writeRecord(d.wal, rec)
+	if err == nil {
+		err = d.wal.Sync()
+	}
+	if err != nil {
+		// Cut off whatever part of the record made it to disk so that later
+		// records are not hidden behind it on replay.
+		d.wal.Truncate(d.offset)
+		d.wal.Seek(d.offset, 0)
+		return err
+	}
+	d.offset += int64(n)
+	d.seq = rec.Seq
+	d.pending++
+	if err := apply(); err != nil {
+		return err
+	}
+	if d.pending >= d.opts.SnapshotEvery {
+		if err := d.snapshotLocked(); err != nil {
+			log.Printf("snapshot failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// Snapshot writes the current data set to disk and truncates the log.
+func (d *DurableStore) Snapshot() error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if d.closed {
+		return os.ErrClosed
+	}
+	return d.snapshotLocked()
+}
+
+func (d *DurableStore) snapshotLocked() error {
+	all, err := d.mem.List(ListQuery{Filter: Filter{Deleted: IncludeDeleted}})
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshot(d.dir, snapshot{Seq: d.seq, Students: all.Students}); err != nil {
+		return err
+	}
+	// Records up to d.seq are now covered by the snapshot. If we crash before
+	// the truncate below they are skipped on replay by their sequence number.
+	if err := d.wal.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := d.wal.Seek(0, 0); err != nil {
+		return err
+	}
+	d.offset = 0
+	d.pending = 0
+	return d.wal.Sync()
+}
+
+func (d *DurableStore) Close() error {
+	d.mux.Lock()
+	if d.closed {
+		d.mux.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.mux.Unlock()
+	if d.stop != nil {
+		close(d.stop)
+		<-d.done
+	}
+	return d.wal.Close()
+}
@@ -0,0 +1,323 @@
+package store_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mygolangproject/store"
+	"mygolangproject/store/storetest"
+)
+
+// tempDir returns a directory that is removed when the test ends.
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "storetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestDurableStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.StudentStore {
+		// Compact often so that the suite also runs across snapshots.
+		d, err := store.OpenDurableStore(tempDir(t), store.DurableOptions{SnapshotEvery: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { d.Close() })
+		return d
+	})
+}
+
+// openDurable opens the store in dir and closes it when the test ends.
+func openDurable(t *testing.T, dir string, opts store.DurableOptions) *store.DurableStore {
+	d, err := store.OpenDurableStore(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// TestDurableStoreSnapshotKeepsSoftDeleted checks that a compaction keeps
+// soft-deleted students so that they can still be restored after a restart.
+func TestDurableStoreSnapshotKeepsSoftDeleted(t *testing.T) {
+	dir := tempDir(t)
+	d := openDurable(t, dir, store.DurableOptions{})
+	if err := d.Create(store.Student{Id: "a", Name: "alice", Age: 20, CreateTime: 1}); err != nil {
+		t.Fatal(err)
+	}
+	deleted := store.Student{Id: "a", Name: "alice", Age: 20, CreateTime: 1, Version: 1, DeletedTime: 2}
+	if err := d.Update(deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	got, err := d.Get("a")
+	if err != nil {
+		t.Fatalf("Get after reopen: %v", err)
+	}
+	if got.DeletedTime != 2 || got.Version != 2 {
+		t.Fatalf("Get after reopen = %+v, want the soft-deleted student at version 2", got)
+	}
+	got.DeletedTime = 0
+	if err := d.Update(got); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	res, err := d.List(store.ListQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Students) != 1 || res.Students[0].Id != "a" {
+		t.Fatalf("List after restore = %+v, want [a]", res.Students)
+	}
+}
+
+// ids lists the ids of every student in s, oldest first.
+func ids(t *testing.T, s store.StudentStore) string {
+	res, err := s.List(store.ListQuery{Filter: store.Filter{Deleted: store.IncludeDeleted}, Ascending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, stu := range res.Students {
+		out = append(out, stu.Id)
+	}
+	return fmt.Sprint(out)
+}
+
+func mustCreate(t *testing.T, s store.StudentStore, ids ...string) {
+	for _, id := range ids {
+		if err := s.Create(store.Student{Id: id, Name: "student " + id, Age: 20, CreateTime: int64(id[0])}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestDurableStoreReopen checks that reopening replays the log on top of the
+// snapshot and that the replayed store keeps appending to the same log.
+func TestDurableStoreReopen(t *testing.T) {
+	dir := tempDir(t)
+	d := openDurable(t, dir, store.DurableOptions{})
+	mustCreate(t, d, "a", "b")
+	if err := d.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	batch := []store.Student{
+		{Id: "c", Name: "student c", Age: 20, CreateTime: 'c'},
+		{Id: "e", Name: "student e", Age: 20, CreateTime: 'e'},
+	}
+	if err := d.CreateAll(batch); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := d.Get("a")
+	a.Age = 30
+	if err := d.Update(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Delete("b", 0); err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	if got := ids(t, d); got != "[a c e]" {
+		t.Fatalf("after reopen students = %v, want [a c e]", got)
+	}
+	if a, _ := d.Get("a"); a.Age != 30 || a.Version != 2 {
+		t.Fatalf("after reopen a = %+v, want age 30 at version 2", a)
+	}
+	mustCreate(t, d, "d")
+	d.Close()
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	if got := ids(t, d); got != "[a c d e]" {
+		t.Fatalf("after second reopen students = %v, want [a c d e]", got)
+	}
+}
+
+// walRecords returns the contents of the log in dir and the offset at which
+// each record starts.
+func walRecords(t *testing.T, dir string) ([]byte, []int) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "students.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offsets []int
+	for off := 0; off < len(data); off += 8 + int(binary.BigEndian.Uint32(data[off:])) {
+		offsets = append(offsets, off)
+	}
+	return data, offsets
+}
+
+func writeWAL(t *testing.T, dir string, data []byte) {
+	if err := ioutil.WriteFile(filepath.Join(dir, "students.wal"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDurableStoreTornTail checks that a record cut short by a crash is
+// dropped and that later writes are not hidden behind it.
+func TestDurableStoreTornTail(t *testing.T) {
+	dir := tempDir(t)
+	d := openDurable(t, dir, store.DurableOptions{})
+	mustCreate(t, d, "a", "b")
+	d.Close()
+
+	data, _ := walRecords(t, dir)
+	writeWAL(t, dir, data[:len(data)-3])
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	if got := ids(t, d); got != "[a]" {
+		t.Fatalf("after torn write students = %v, want [a]", got)
+	}
+	mustCreate(t, d, "c")
+	d.Close()
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	if got := ids(t, d); got != "[a c]" {
+		t.Fatalf("after reopen students = %v, want [a c]", got)
+	}
+}
+
+// TestDurableStoreCorruptRecord checks that a record whose checksum does not
+// match ends the log: it and everything after it are dropped.
+func TestDurableStoreCorruptRecord(t *testing.T) {
+	dir := tempDir(t)
+	d := openDurable(t, dir, store.DurableOptions{})
+	mustCreate(t, d, "a", "b", "c")
+	d.Close()
+
+	data, offsets := walRecords(t, dir)
+	if len(offsets) != 3 {
+		t.Fatalf("log holds %d records, want 3", len(offsets))
+	}
+	data[offsets[1]+8+2] ^= 0xff
+	writeWAL(t, dir, data)
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	if got := ids(t, d); got != "[a]" {
+		t.Fatalf("after corrupt record students = %v, want [a]", got)
+	}
+	mustCreate(t, d, "d")
+	d.Close()
+
+	d = openDurable(t, dir, store.DurableOptions{})
+	if got := ids(t, d); got != "[a d]" {
+		t.Fatalf("after reopen students = %v, want [a d]", got)
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	storetest.RunHistory(t, func(t *testing.T) store.HistoryStore {
+		h, err := store.OpenFileHistory(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { h.Close() })
+		return h
+	})
+}
+
+func TestFileProfessions(t *testing.T) {
+	storetest.RunProfessions(t, func(t *testing.T) store.ProfessionStore {
+		p, err := store.OpenFileProfessions(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	})
+}
+
+func TestFileIdempotency(t *testing.T) {
+	storetest.RunIdempotency(t, func(t *testing.T) store.IdempotencyStore {
+		i, err := store.OpenFileIdempotency(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { i.Close() })
+		return i
+	})
+}
+
+func TestFileAliases(t *testing.T) {
+	storetest.RunAliases(t, func(t *testing.T) store.AliasStore {
+		a, err := store.OpenFileAliases(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { a.Close() })
+		return a
+	})
+}
+
+// TestFileAliasesReopen checks that replaying the log drops removed aliases.
+func TestFileAliasesReopen(t *testing.T) {
+	dir := tempDir(t)
+	a, err := store.OpenFileAliases(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := store.Alias{RetiredId: "b", SurvivorId: "c", Time: 100}
+	for _, alias := range []store.Alias{{RetiredId: "a", SurvivorId: "c", Time: 100}, kept} {
+		if err := a.Put(alias); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+
+	a, err = store.OpenFileAliases(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if _, err := a.Get("a"); err != store.ErrNoAlias {
+		t.Fatalf("Get(a) after reopen = %v, want ErrNoAlias", err)
+	}
+	if got, err := a.Get("b"); err != nil || got != kept {
+		t.Fatalf("Get(b) after reopen = %+v, %v, want %+v", got, err, kept)
+	}
+}
+
+func TestFileSequences(t *testing.T) {
+	storetest.RunSequences(t, func(t *testing.T) store.SequenceStore {
+		s, err := store.OpenFileSequences(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
+
+func TestFileCourses(t *testing.T) {
+	storetest.RunCourses(t, func(t *testing.T) store.CourseStore {
+		c, err := store.OpenFileCourses(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	})
+}
+
+func TestFileEnrollments(t *testing.T) {
+	storetest.RunEnrollments(t, func(t *testing.T) store.EnrollmentStore {
+		e, err := store.OpenFileEnrollments(tempDir(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { e.Close() })
+		return e
+	})
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrAlreadyEnrolled = errors.New("student already enrolled in the course")
+	ErrNotEnrolled     = errors.New("student is not enrolled in the course")
+)
+
+// Enrollment records that a student takes a course.
+type Enrollment struct {
+	CourseCode string
+	StudentId  string
+	Time       int64 // unix seconds
+}
+
+// EnrollmentStore keeps which students take which courses. Capacity and
+// eligibility are up to the caller to check, since courses and students live
+// elsewhere.
+type EnrollmentStore interface {
+	// Enroll fails with ErrAlreadyEnrolled if the student already takes the
+	// course.
+	Enroll(e Enrollment) error
+	// Drop removes an enrollment or returns ErrNotEnrolled.
+	Drop(courseCode, studentId string) error
+	// ByCourse returns the enrollments in a course, oldest first.
+	ByCourse(courseCode string) ([]Enrollment, error)
+	// ByStudent returns the enrollments of a student ordered by course code.
+	ByStudent(studentId string) ([]Enrollment, error)
+}
+
+// MemoryEnrollments keeps the enrollments by course and by student.
+type MemoryEnrollments struct {
+	mux       sync.RWMutex
+	byCourse  map[string]map[string]Enrollment
+	byStudent map[string]map[string]Enrollment
+}
+
+func NewMemoryEnrollments() *MemoryEnrollments {
+	return &MemoryEnrollments{
+		byCourse:  make(map[string]map[string]Enrollment),
+		byStudent: make(map[string]map[string]Enrollment),
+	}
+}
+
+func (m *MemoryEnrollments) Enroll(e Enrollment) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.byCourse[e.CourseCode][e.StudentId]; ok {
+		return ErrAlreadyEnrolled
+	}
+	if m.byCourse[e.CourseCode] == nil {
+		m.byCourse[e.CourseCode] = make(map[string]Enrollment)
+	}
+	if m.byStudent[e.StudentId] == nil {
+		m.byStudent[e.StudentId] = make(map[string]Enrollment)
+	}
+	m.byCourse[e.CourseCode][e.StudentId] = e
+	m.byStudent[e.StudentId][e.CourseCode] = e
+	return nil
+}
+
+func (m *MemoryEnrollments) Drop(courseCode, studentId string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.byCourse[courseCode][studentId]; !ok {
+		return ErrNotEnrolled
+	}
+	delete(m.byCourse[courseCode], studentId)
+	if len(m.byCourse[courseCode]) == 0 {
+		delete(m.byCourse, courseCode)
+	}
+	delete(m.byStudent[studentId], courseCode)
+	if len(m.byStudent[studentId]) == 0 {
+		delete(m.byStudent, studentId)
+	}
+	return nil
+}
+
+func (m *MemoryEnrollments) ByCourse(courseCode string) ([]Enrollment, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	list := make([]Enrollment, 0, len(m.byCourse[courseCode]))
+	for _, e := range m.byCourse[courseCode] {
+		list = append(list, e)
+	}
+	sortEnrollments(list)
+	return list, nil
+}
+
+func (m *MemoryEnrollments) ByStudent(studentId string) ([]Enrollment, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	list := make([]Enrollment, 0, len(m.byStudent[studentId]))
+	for _, e := range m.byStudent[studentId] {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CourseCode < list[j].CourseCode })
+	return list, nil
+}
+
+// All returns every enrollment, oldest first.
+func (m *MemoryEnrollments) All() []Enrollment {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	var all []Enrollment
+	for _, course := range m.byCourse {
+		for _, e := range course {
+			all = append(all, e)
+		}
+	}
+	sortEnrollments(all)
+	return all
+}
+
+// sortEnrollments orders enrollments oldest first; those of the same second
+// by course and student.
+func sortEnrollments(list []Enrollment) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Time != b.Time {
+			return a.Time < b.Time
+		}
+		if a.CourseCode != b.CourseCode {
+			return a.CourseCode < b.CourseCode
+		}
+		return a.StudentId < b.StudentId
+	})
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const aliasesLogName = "aliases.log"
+
+// FileAliases is a MemoryAliases backed by a log of puts in the record format
+// of the write-ahead log; a record without a survivor removes an alias.
+// Aliases are few and seldom removed, so the log is never compacted.
+type FileAliases struct {
+	mem *MemoryAliases
+
+	mux    sync.Mutex
+	file   *os.File
+	offset int64
+}
+
+func OpenFileAliases(dir string) (*FileAliases, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, aliasesLogName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	m := &FileAliases{mem: NewMemoryAliases()}
+	end, err := replayRecords(f, func() interface{} { return &Alias{} }, func(v interface{}) {
+		if a := *v.(*Alias); a.SurvivorId == "" {
+			m.mem.Delete(a.RetiredId)
+		} else {
+			m.mem.Put(a)
+		}
+	})
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err == nil {
+		_, err = f.Seek(end, 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m.file, m.offset = f, end
+	return m, nil
+}
+
+func (m *FileAliases) Put(a Alias) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if err := m.appendLocked(a); err != nil {
+		return err
+	}
+	return m.mem.Put(a)
+}
+
+func (m *FileAliases) Delete(retiredId string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, err := m.mem.Get(retiredId); err == ErrNoAlias {
+		return nil
+	}
+	if err := m.appendLocked(Alias{RetiredId: retiredId}); err != nil {
+		return err
+	}
+	return m.mem.Delete(retiredId)
+}
+
+// appendLocked writes and syncs one record. The caller holds m.mux.
+func (m *FileAliases) appendLocked(a Alias) error {
+	n, err := writeRecord(m.file, a)
+	if err == nil {
+		err = m.file.Sync()
+	}
+	if err != nil {
+		m.file.Truncate(m.offset)
+		m.file.Seek(m.offset, 0)
+		return err
+	}
+	m.offset += int64(n)
+	return nil
+}
+
+func (m *FileAliases) Get(retiredId string) (Alias, error) {
+	return m.mem.Get(retiredId)
+}
+
+func (m *FileAliases) Close() error {
+	return m.file.Close()
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"os"
+	"sync"
+)
+
+const coursesFileName = "courses.rec"
+
+// FileCourses is a MemoryCourses whose courses are all rewritten to one file
+// on every change, like FileProfessions.
+type FileCourses struct {
+	mem *MemoryCourses
+	dir string
+	mux sync.Mutex // serializes writers
+}
+
+func OpenFileCourses(dir string) (*FileCourses, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var list []Course
+	if _, err := readRecordFile(dir, coursesFileName, &list); err != nil {
+		return nil, err
+	}
+	return &FileCourses{mem: NewMemoryCourses(list), dir: dir}, nil
+}
+
+// commit makes a change in memory and saves the courses. If they cannot be
+// saved, the undo function returned by change is run.
+func (f *FileCourses) commit(change func() (undo func(), err error)) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	undo, err := change()
+	if err != nil {
+		return err
+	}
+	list, err := f.mem.List()
+	if err == nil {
+		err = writeRecordFile(f.dir, coursesFileName, list)
+	}
+	if err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+func (f *FileCourses) Create(c Course) error {
+	return f.commit(func() (func(), error) {
+		return func() { f.mem.Delete(c.Code) }, f.mem.Create(c)
+	})
+}
+
+func (f *FileCourses) Get(code string) (Course, error) {
+	return f.mem.Get(code)
+}
+
+func (f *FileCourses) List() ([]Course, error) {
+	return f.mem.List()
+}
+
+func (f *FileCourses) Update(c Course) error {
+	return f.commit(func() (func(), error) {
+		old, err := f.mem.Get(c.Code)
+		if err != nil {
+			return nil, err
+		}
+		return func() { f.mem.Update(old) }, f.mem.Update(c)
+	})
+}
+
+func (f *FileCourses) Delete(code string) error {
+	return f.commit(func() (func(), error) {
+		old, err := f.mem.Get(code)
+		if err != nil {
+			return nil, err
+		}
+		return func() { f.mem.Create(old) }, f.mem.Delete(code)
+	})
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	enrollmentsLogName      = "enrollments.log"
+	enrollmentsSnapshotName = "enrollments.rec"
+)
+
+type enrollmentRecord struct {
+	Op         string     `json:"op"`
+	Enrollment Enrollment `json:"enrollment"`
+}
+
+// FileEnrollments is a MemoryEnrollments backed by a log of enrolls and drops
+// in the record format of the write-ahead log. Opening it compacts the log
+// into a snapshot of the enrollments left.
+type FileEnrollments struct {
+	mem *MemoryEnrollments
+
+	mux    sync.Mutex
+	file   *os.File
+	offset int64
+}
+
+func OpenFileEnrollments(dir string) (*FileEnrollments, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var snap []Enrollment
+	if _, err := readRecordFile(dir, enrollmentsSnapshotName, &snap); err != nil {
+		return nil, err
+	}
+	m := &FileEnrollments{mem: NewMemoryEnrollments()}
+	for _, e := range snap {
+		m.mem.Enroll(e)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, enrollmentsLogName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// The last record of each enrollment decides whether it exists, so
+	// replaying records the snapshot already has after an interrupted
+	// compaction does no harm.
+	end, err := replayRecords(f, func() interface{} { return &enrollmentRecord{} }, func(v interface{}) {
+		r := v.(*enrollmentRecord)
+		if r.Op == opDelete {
+			m.mem.Drop(r.Enrollment.CourseCode, r.Enrollment.StudentId)
+		} else {
+			m.mem.Enroll(r.Enrollment)
+		}
+	})
+	if err == nil && end > 0 {
+		err = writeRecordFile(dir, enrollmentsSnapshotName, m.mem.All())
+		end = 0
+	}
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err == nil {
+		_, err = f.Seek(end, 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m.file, m.offset = f, end
+	return m, nil
+}
+
+// log appends r to the log; m.mux must be held.
+func (m *FileEnrollments) log(r enrollmentRecord) error {
+	n, err := writeRecord(m.file, r)
+	if err == nil {
+		err = m.file.Sync()
+	}
+	if err != nil {
+		m.file.Truncate(m.offset)
+		m.file.Seek(m.offset, 0)
+		return err
+	}
+	m.offset += int64(n)
+	return nil
+}
+
+func (m *FileEnrollments) Enroll(e Enrollment) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, err := m.check(e.CourseCode, e.StudentId); err != ErrNotEnrolled {
+		if err == nil {
+			err = ErrAlreadyEnrolled
+		}
+		return err
+	}
+	if err := m.log(enrollmentRecord{Op: opPut, Enrollment: e}); err != nil {
+		return err
+	}
+	return m.mem.Enroll(e)
+}
+
+func (m *FileEnrollments) Drop(courseCode, studentId string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	e, err := m.check(courseCode, studentId)
+	if err != nil {
+		return err
+	}
+	if err := m.log(enrollmentRecord{Op: opDelete, Enrollment: e}); err != nil {
+		return err
+	}
+	return m.mem.Drop(courseCode, studentId)
+}
+
+// check returns the enrollment of a student in a course or ErrNotEnrolled,
+// so that a change that would fail is not logged.
+func (m *FileEnrollments) check(courseCode, studentId string) (Enrollment, error) {
+	list, err := m.mem.ByStudent(studentId)
+	if err != nil {
+		return Enrollment{}, err
+	}
+	for _, e := range list {
+		if e.CourseCode == courseCode {
+			return e, nil
+		}
+	}
+	return Enrollment{}, ErrNotEnrolled
+}
+
+func (m *FileEnrollments) ByCourse(courseCode string) ([]Enrollment, error) {
+	return m.mem.ByCourse(courseCode)
+}
+
+func (m *FileEnrollments) ByStudent(studentId string) ([]Enrollment, error) {
+	return m.mem.ByStudent(studentId)
+}
+
+func (m *FileEnrollments) Close() error {
+	return m.file.Close()
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const historyFileName = "history.log"
+
+// FileHistory is a MemoryHistory backed by an append-only file in the record
+// format of the write-ahead log. The file is never compacted.
+type FileHistory struct {
+	mem *MemoryHistory
+
+	mux    sync.Mutex
+	file   *os.File
+	offset int64
+}
+
+func OpenFileHistory(dir string) (*FileHistory, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, historyFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	h := &FileHistory{mem: NewMemoryHistory(), file: f}
+	end, err := replayRecords(f, func() interface{} { return &HistoryEntry{} }, func(v interface{}) {
+		e := v.(*HistoryEntry)
+		if e.Before != nil {
+			Upgrade(e.Before)
+		}
+		if e.After != nil {
+			Upgrade(e.After)
+		}
+		h.mem.Append(*e)
+	})
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err == nil {
+		_, err = f.Seek(end, 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	h.offset = end
+	return h, nil
+}
+
+func (h *FileHistory) Append(e HistoryEntry) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	n, err := writeRecord(h.file, e)
+	if err == nil {
+		err = h.file.Sync()
+	}
+	if err != nil {
+		h.file.Truncate(h.offset)
+		h.file.Seek(h.offset, 0)
+		return err
+	}
+	h.offset += int64(n)
+	return h.mem.Append(e)
+}
+
+func (h *FileHistory) List(studentId string) ([]HistoryEntry, error) {
+	return h.mem.List(studentId)
+}
+
+func (h *FileHistory) Snapshot(asOf int64) ([]Student, error) {
+	return h.mem.Snapshot(asOf)
+}
+
+func (h *FileHistory) Close() error {
+	return h.file.Close()
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	idempotencyLogName      = "idempotency.log"
+	idempotencySnapshotName = "idempotency.rec"
+)
+
+// FileIdempotency is a MemoryIdempotency backed by a log of puts in the
+// record format of the write-ahead log. Expire compacts what is left into a
+// snapshot and empties the log.
+type FileIdempotency struct {
+	mem *MemoryIdempotency
+	dir string
+
+	mux    sync.Mutex
+	file   *os.File
+	offset int64
+}
+
+func OpenFileIdempotency(dir string) (*FileIdempotency, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var snap []IdempotentResult
+	if _, err := readRecordFile(dir, idempotencySnapshotName, &snap); err != nil {
+		return nil, err
+	}
+	m := &FileIdempotency{mem: NewMemoryIdempotency(), dir: dir}
+	for _, r := range snap {
+		m.mem.Put(r)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, idempotencyLogName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// Puts are replacements, so replaying ones the snapshot already has
+	// after an interrupted Expire does no harm.
+	end, err := replayRecords(f, func() interface{} { return &IdempotentResult{} }, func(v interface{}) {
+		m.mem.Put(*v.(*IdempotentResult))
+	})
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err == nil {
+		_, err = f.Seek(end, 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m.file, m.offset = f, end
+	return m, nil
+}
+
+func (m *FileIdempotency) Put(r IdempotentResult) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	n, err := writeRecord(m.file, r)
+	if err == nil {
+		err = m.file.Sync()
+	}
+	if err != nil {
+		m.file.Truncate(m.offset)
+		m.file.Seek(m.offset, 0)
+		return err
+	}
+	m.offset += int64(n)
+	return m.mem.Put(r)
+}
+
+func (m *FileIdempotency) Get(key string) (IdempotentResult, error) {
+	return m.mem.Get(key)
+}
+
+func (m *FileIdempotency) Expire(before int64) (int, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	n, _ := m.mem.Expire(before)
+	if n == 0 {
+		return 0, nil
+	}
+	if err := writeRecordFile(m.dir, idempotencySnapshotName, m.mem.All()); err != nil {
+		return n, err
+	}
+	if err := m.file.Truncate(0); err != nil {
+		return n, err
+	}
+	if _, err := m.file.Seek(0, 0); err != nil {
+		return n, err
+	}
+	m.offset = 0
+	return n, nil
+}
+
+func (m *FileIdempotency) Close() error {
+	return m.file.Close()
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"os"
+	"sync"
+)
+
+const professionsFileName = "professions.rec"
+
+// FileProfessions is a MemoryProfessions whose whole catalogue is rewritten
+// to one file on every change; it is small and rarely changed.
+type FileProfessions struct {
+	mem *MemoryProfessions
+	dir string
+	mux sync.Mutex // serializes writers
+}
+
+// OpenFileProfessions loads the catalogue from dir, starting a new one with
+// DefaultProfessions if there is none yet.
+func OpenFileProfessions(dir string) (*FileProfessions, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var list []Profession
+	exists, err := readRecordFile(dir, professionsFileName, &list)
+	if err != nil {
+		return nil, err
+	}
+	f := &FileProfessions{mem: NewMemoryProfessions(list), dir: dir}
+	if !exists {
+		f.mem = NewMemoryProfessions(DefaultProfessions)
+		if err := f.save(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *FileProfessions) save() error {
+	list, err := f.mem.List()
+	if err != nil {
+		return err
+	}
+	return writeRecordFile(f.dir, professionsFileName, list)
+}
+
+// commit makes a change in memory and saves the catalogue. If it cannot be
+// saved, the undo function returned by change is run.
+func (f *FileProfessions) commit(change func() (undo func(), err error)) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	undo, err := change()
+	if err != nil {
+		return err
+	}
+	if err := f.save(); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+func (f *FileProfessions) Create(p Profession) error {
+	return f.commit(func() (func(), error) {
+		return func() { f.mem.Delete(p.Code) }, f.mem.Create(p)
+	})
+}
+
+func (f *FileProfessions) Get(code string) (Profession, error) {
+	return f.mem.Get(code)
+}
+
+func (f *FileProfessions) List() ([]Profession, error) {
+	return f.mem.List()
+}
+
+func (f *FileProfessions) Update(p Profession) error {
+	return f.commit(func() (func(), error) {
+		old, err := f.mem.Get(p.Code)
+		if err != nil {
+			return nil, err
+		}
+		return func() { f.mem.Update(old) }, f.mem.Update(p)
+	})
+}
+
+func (f *FileProfessions) Delete(code string) error {
+	return f.commit(func() (func(), error) {
+		old, err := f.mem.Get(code)
+		if err != nil {
+			return nil, err
+		}
+		return func() { f.mem.Create(old) }, f.mem.Delete(code)
+	})
+}
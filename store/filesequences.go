@@ -0,0 +1,44 @@
+package store
+
+import (
+	"os"
+	"sync"
+)
+
+const sequencesFileName = "sequences.rec"
+
+// FileSequences is a MemorySequences whose counters are all rewritten to one
+// file every time one advances; there is a counter per year and profession
+// only.
+type FileSequences struct {
+	mem *MemorySequences
+	dir string
+	mux sync.Mutex // serializes writers
+}
+
+func OpenFileSequences(dir string) (*FileSequences, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var counters map[string]int64
+	if _, err := readRecordFile(dir, sequencesFileName, &counters); err != nil {
+		return nil, err
+	}
+	return &FileSequences{mem: NewMemorySequences(counters), dir: dir}, nil
+}
+
+// Next saves the advanced counter before handing out its value, so that a
+// number is not handed out again after a crash.
+func (f *FileSequences) Next(name string) (int64, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	current := f.mem.Current(name)
+	if err := f.mem.Advance(name, current); err != nil {
+		return 0, err
+	}
+	if err := writeRecordFile(f.dir, sequencesFileName, f.mem.All()); err != nil {
+		// The value is skipped rather than risk handing it out twice.
+		return 0, err
+	}
+	return current + 1, nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import "sync"
+
+// HistoryEntry is an immutable record of one change to a student.
+type HistoryEntry struct {
+	StudentId string
+	Action    string // register, alterProfession, updateStudent, delete, restore, purge, merge ...
+	Actor     string
+	RequestId string
+	Time      int64
+	Before    *Student // nil when the student was created
+	After     *Student // nil when the student was purged
+	// MergedWith is the other student of a merge: the retired one on the
+	// entry of the survivor and the survivor on the entry of the retired one.
+	MergedWith string
+}
+
+// HistoryStore keeps the audit trail. Entries are never changed or removed,
+// not even when the student itself is purged.
+type HistoryStore interface {
+	Append(e HistoryEntry) error
+	// List returns the entries of one student, oldest first.
+	List(studentId string) ([]HistoryEntry, error)
+	// Snapshot reconstructs every student as last recorded at or before
+	// asOf, soft-deleted ones included. Students purged by then are left out.
+	Snapshot(asOf int64) ([]Student, error)
+}
+
+// StateAsOf replays the entries of one student, oldest first, and returns its
+// state at asOf, or false if it did not exist then.
+func StateAsOf(entries []HistoryEntry, asOf int64) (Student, bool) {
+	var state *Student
+	for _, e := range entries {
+		if e.Time > asOf {
+			break
+		}
+		state = e.After
+	}
+	if state == nil {
+		return Student{}, false
+	}
+	return *state, true
+}
+
+type MemoryHistory struct {
+	entries map[string][]HistoryEntry
+	mux     sync.RWMutex
+}
+
+func NewMemoryHistory() *MemoryHistory {
+	return &MemoryHistory{entries: make(map[string][]HistoryEntry)}
+}
+
+func (h *MemoryHistory) Append(e HistoryEntry) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.entries[e.StudentId] = append(h.entries[e.StudentId], e)
+	return nil
+}
+
+func (h *MemoryHistory) List(studentId string) ([]HistoryEntry, error) {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	return append([]HistoryEntry(nil), h.entries[studentId]...), nil
+}
+
+func (h *MemoryHistory) Snapshot(asOf int64) ([]Student, error) {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	var list []Student
+	for _, entries := range h.entries {
+		if s, ok := StateAsOf(entries, asOf); ok {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
+
+// All returns every entry, grouped by student and oldest first within each.
+func (h *MemoryHistory) All() []HistoryEntry {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	var all []HistoryEntry
+	for _, entries := range h.entries {
+		all = append(all, entries...)
+	}
+	return all
+}
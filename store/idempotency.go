@@ -0,0 +1,96 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoIdempotentResult = errors.New("no result for idempotency key")
+
+// IdempotentResult is the reply a mutation was answered with, kept so that a
+// retry carrying the same idempotency key gets the same reply instead of
+// repeating the mutation.
+type IdempotentResult struct {
+	Key    string
+	Method string
+	// Request is a digest of the request, to recognise a key reused for a
+	// different one.
+	Request  []byte
+	Response []byte
+	Time     int64 // unix seconds
+}
+
+// IdempotencyStore keeps the results of mutations by idempotency key.
+type IdempotencyStore interface {
+	// Put records r, replacing any result recorded for the same key.
+	Put(r IdempotentResult) error
+	// Get returns the result recorded for key or ErrNoIdempotentResult.
+	Get(key string) (IdempotentResult, error)
+	// Expire removes the results recorded before the given time and
+	// returns how many there were.
+	Expire(before int64) (int, error)
+}
+
+// MemoryIdempotency keeps the results in a map.
+type MemoryIdempotency struct {
+	mux     sync.RWMutex
+	results map[string]IdempotentResult
+}
+
+func NewMemoryIdempotency() *MemoryIdempotency {
+	return &MemoryIdempotency{results: make(map[string]IdempotentResult)}
+}
+
+func (m *MemoryIdempotency) Put(r IdempotentResult) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.results[r.Key] = copyIdempotentResult(r)
+	return nil
+}
+
+func (m *MemoryIdempotency) Get(key string) (IdempotentResult, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	r, ok := m.results[key]
+	if !ok {
+		return IdempotentResult{}, ErrNoIdempotentResult
+	}
+	return copyIdempotentResult(r), nil
+}
+
+func (m *MemoryIdempotency) Expire(before int64) (int, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	n := 0
+	for key, r := range m.results {
+		if r.Time < before {
+			delete(m.results, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// All returns every result, oldest first.
+func (m *MemoryIdempotency) All() []IdempotentResult {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	all := make([]IdempotentResult, 0, len(m.results))
+	for _, r := range m.results {
+		all = append(all, copyIdempotentResult(r))
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Time != all[j].Time {
+			return all[i].Time < all[j].Time
+		}
+		return all[i].Key < all[j].Key
+	})
+	return all
+}
+
+func copyIdempotentResult(r IdempotentResult) IdempotentResult {
+	r.Request = append([]byte(nil), r.Request...)
+	r.Response = append([]byte(nil), r.Response...)
+	return r
+}
@@ -0,0 +1,459 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/google/btree"
+)
+
+const (
+	btreeDegree = 32
+	numSortKeys = int(SortByAge) + 1
+	// byNumber orders the number index by student number and then by Id.
+	byNumber = SortKey(numSortKeys)
+)
+
+// orderedIndex keeps every student of a MemoryStore in one B-tree per sort
+// key, each with Id as the tiebreak, plus a createTime-ordered tree per
+// profession, one by student number, and counts of the students per
+// profession and per age. List
+// walks the tree in the requested order when the filter keeps enough students
+// for a page to be found soon, and otherwise sorts the matches in the
+// narrowest range any tree offers for the filter.
+//
+// Readers never hold the lock while they walk: they share a lazy clone of the
+// trees, taken under the lock only when a write happened since the last one,
+// and writers copy the nodes they change instead of waiting for readers.
+type orderedIndex struct {
+	mux  sync.Mutex // guards live and snap
+	live *indexView
+	snap *indexView // shared by readers, nil once live has changed
+}
+
+// indexView is one state of the index. A view handed to readers is never
+// written again, except for the totals they count in it.
+type indexView struct {
+	sorted       [numSortKeys]*btree.BTree
+	byProfession map[string]*btree.BTree
+	numbers      *btree.BTree // the students that have a number
+	all          counts
+	professions  map[string]counts
+	ages         map[int32]counts
+
+	mux    sync.Mutex
+	totals map[Filter]int
+}
+
+// indexItem orders students by key and then by Id. Items point at the
+// stored student, which is replaced rather than changed, so copying a node
+// copies no student.
+type indexItem struct {
+	key SortKey
+	*Student
+}
+
+func (a indexItem) Less(b btree.Item) bool {
+	other := b.(indexItem).Student
+	if a.key == byNumber && a.Number != other.Number {
+		return a.Number < other.Number
+	}
+	return Compare(a.key, *a.Student, *other) < 0
+}
+
+type counts struct {
+	live, deleted int
+}
+
+func (c *counts) add(s Student, n int) {
+	if s.DeletedTime != 0 {
+		c.deleted += n
+	} else {
+		c.live += n
+	}
+}
+
+func (c counts) of(d DeletedFilter) int {
+	switch d {
+	case ExcludeDeleted:
+		return c.live
+	case OnlyDeleted:
+		return c.deleted
+	}
+	return c.live + c.deleted
+}
+
+func newOrderedIndex() *orderedIndex {
+	v := &indexView{
+		numbers:      btree.New(btreeDegree),
+		byProfession: make(map[string]*btree.BTree),
+		professions:  make(map[string]counts),
+		ages:         make(map[int32]counts),
+	}
+	for i := range v.sorted {
+		v.sorted[i] = btree.New(btreeDegree)
+	}
+	return &orderedIndex{live: v}
+}
+
+// clone returns a copy of v that shares its nodes until either is written.
+func (v *indexView) clone() *indexView {
+	c := &indexView{
+		numbers:      v.numbers.Clone(),
+		all:          v.all,
+		byProfession: make(map[string]*btree.BTree, len(v.byProfession)),
+		professions:  make(map[string]counts, len(v.professions)),
+		ages:         make(map[int32]counts, len(v.ages)),
+	}
+	for i, tree := range v.sorted {
+		c.sorted[i] = tree.Clone()
+	}
+	for p, tree := range v.byProfession {
+		c.byProfession[p] = tree.Clone()
+	}
+	for p, n := range v.professions {
+		c.professions[p] = n
+	}
+	for age, n := range v.ages {
+		c.ages[age] = n
+	}
+	return c
+}
+
+// view returns the current state of the index for reading without a lock.
+func (x *orderedIndex) view() *indexView {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	if x.snap == nil {
+		x.snap = x.live.clone()
+	}
+	return x.snap
+}
+
+// replace swaps old for s; either may be nil for an insert or a removal.
+func (x *orderedIndex) replace(old, s *Student) {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	x.snap = nil
+	x.live.replace(old, s)
+}
+
+// insertAll adds every student in one step, so that no List sees some of
+// them without the others.
+func (x *orderedIndex) insertAll(students []Student) {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	x.snap = nil
+	for i := range students {
+		x.live.replace(nil, &students[i])
+	}
+}
+
+func (v *indexView) replace(old, s *Student) {
+	if old != nil {
+		for key, tree := range v.sorted {
+			tree.Delete(indexItem{SortKey(key), old})
+		}
+		if old.Number != "" {
+			v.numbers.Delete(indexItem{byNumber, old})
+		}
+		if tree := v.byProfession[old.Profession]; tree != nil {
+			tree.Delete(indexItem{SortByCreateTime, old})
+			if tree.Len() == 0 {
+				delete(v.byProfession, old.Profession)
+				delete(v.professions, old.Profession)
+			}
+		}
+		v.all.add(*old, -1)
+		if c, ok := v.professions[old.Profession]; ok {
+			c.add(*old, -1)
+			v.professions[old.Profession] = c
+		}
+		if c := v.ages[old.Age]; c.of(IncludeDeleted) == 1 {
+			delete(v.ages, old.Age)
+		} else {
+			c.add(*old, -1)
+			v.ages[old.Age] = c
+		}
+	}
+	if s != nil {
+		for key, tree := range v.sorted {
+			tree.ReplaceOrInsert(indexItem{SortKey(key), s})
+		}
+		if s.Number != "" {
+			v.numbers.ReplaceOrInsert(indexItem{byNumber, s})
+		}
+		tree := v.byProfession[s.Profession]
+		if tree == nil {
+			tree = btree.New(btreeDegree)
+			v.byProfession[s.Profession] = tree
+		}
+		tree.ReplaceOrInsert(indexItem{SortByCreateTime, s})
+		v.all.add(*s, 1)
+		c := v.professions[s.Profession]
+		c.add(*s, 1)
+		v.professions[s.Profession] = c
+		c = v.ages[s.Age]
+		c.add(*s, 1)
+		v.ages[s.Age] = c
+	}
+}
+
+func (x *orderedIndex) len() int {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	return x.live.all.of(IncludeDeleted)
+}
+
+// list answers q from one consistent view of the index. It walks the index in
+// the requested order when that reaches a page soonest, and otherwise sorts
+// what the narrowest index range holds; see narrowest.
+func (x *orderedIndex) list(q ListQuery) ListResult {
+	v := x.view()
+	key := q.SortBy
+	if key < 0 || int(key) >= numSortKeys {
+		key = SortByCreateTime
+	}
+	ordered, best := v.scan(key, q.Filter), v.narrowest(q.Filter)
+	// Walking in order visits about n/size students per match found, where
+	// size bounds the matches; sorting visits the size students of best.
+	n := v.all.of(IncludeDeleted)
+	if !best.same(ordered) && best.size < n && (q.Limit == 0 || int64(q.Limit+1)*int64(n) >= int64(best.size)*int64(best.size)) {
+		matched := make([]Student, 0, best.size)
+		best.walk(true, nil, func(s Student) bool {
+			if q.Filter.Match(s) {
+				matched = append(matched, s)
+			}
+			return true
+		})
+		q.SortBy = key
+		res := ApplyQuery(matched, q)
+		v.setTotal(q.Filter, res.Total)
+		return res
+	}
+	var res ListResult
+	ordered.walk(q.Ascending, q.After, func(s Student) bool {
+		if !q.Filter.Match(s) {
+			return true
+		}
+		if q.Limit > 0 && len(res.Students) == q.Limit {
+			res.More = true
+			return false
+		}
+		res.Students = append(res.Students, s)
+		return true
+	})
+	res.Total = v.total(q.Filter, best)
+	return res
+}
+
+// total counts the students matching f. Filters on the deleted state along
+// with the profession or the age range are answered from the maintained
+// counts; the others are counted in best, the narrowest range for f, once
+// per view.
+func (v *indexView) total(f Filter, best candidate) int {
+	switch f {
+	case Filter{Deleted: f.Deleted}:
+		return v.all.of(f.Deleted)
+	case Filter{Deleted: f.Deleted, Profession: f.Profession}:
+		return v.professions[f.Profession].of(f.Deleted)
+	case Filter{Deleted: f.Deleted, MinAge: f.MinAge, MaxAge: f.MaxAge}:
+		return v.ageCounts(f.MinAge, f.MaxAge).of(f.Deleted)
+	}
+	v.mux.Lock()
+	n, ok := v.totals[f]
+	v.mux.Unlock()
+	if ok {
+		return n
+	}
+	best.walk(true, nil, func(s Student) bool {
+		if f.Match(s) {
+			n++
+		}
+		return true
+	})
+	v.setTotal(f, n)
+	return n
+}
+
+func (v *indexView) setTotal(f Filter, n int) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	if v.totals == nil {
+		v.totals = make(map[Filter]int)
+	}
+	v.totals[f] = n
+}
+
+// ageCounts adds up the students aged min to max; zero leaves a bound open.
+func (v *indexView) ageCounts(min, max int32) counts {
+	var sum counts
+	for age, c := range v.ages {
+		if (min == 0 || age >= min) && (max == 0 || age <= max) {
+			sum.live += c.live
+			sum.deleted += c.deleted
+		}
+	}
+	return sum
+}
+
+// candidate is an index range holding every student matching a filter, with
+// size bounding how many students it holds.
+type candidate struct {
+	indexScan
+	size int
+}
+
+// same reports whether c is a range of the same index as sc.
+func (c candidate) same(sc indexScan) bool {
+	return c.key == sc.key && c.tree == sc.tree
+}
+
+// narrowest returns the smallest range of any index that holds every student
+// matching f. A student number leaves the students that have it, hardly ever
+// more than one. The sizes of a profession and of an age range are known from
+// the maintained counts; the time and name ranges are counted, but only up to
+// the smallest size found so far.
+func (v *indexView) narrowest(f Filter) candidate {
+	best := candidate{v.scan(SortByCreateTime, Filter{}), v.all.of(IncludeDeleted)}
+	if f.Number != "" {
+		sc := v.scan(byNumber, f)
+		size, _ := sc.count(best.size)
+		return candidate{sc, size}
+	}
+	if f.Profession != "" {
+		best = candidate{v.scan(SortByCreateTime, Filter{Profession: f.Profession}), v.professions[f.Profession].of(IncludeDeleted)}
+	}
+	if f.MinAge != 0 || f.MaxAge != 0 {
+		if size := v.ageCounts(f.MinAge, f.MaxAge).of(IncludeDeleted); size < best.size {
+			best = candidate{v.scan(SortByAge, f), size}
+		}
+	}
+	for _, key := range []SortKey{SortByCreateTime, SortByModifiedTime, SortByName} {
+		sc := v.scan(key, f)
+		if sc.lo == nil && sc.hi == nil {
+			continue
+		}
+		if size, ok := sc.count(best.size); ok {
+			best = candidate{sc, size}
+		}
+	}
+	return best
+}
+
+// scan returns the range of the key-ordered index that can hold students
+// matching f.
+func (v *indexView) scan(key SortKey, f Filter) indexScan {
+	if key == byNumber {
+		sc := indexScan{key: key, tree: v.numbers}
+		sc.bound(Student{Number: f.Number}, true, Student{Number: f.Number + "\x00"}, true)
+		return sc
+	}
+	sc := indexScan{key: key, tree: v.sorted[key]}
+	switch key {
+	case SortByCreateTime:
+		if f.Profession != "" {
+			sc.tree = v.byProfession[f.Profession]
+		}
+		sc.bound(Student{CreateTime: f.CreatedFrom}, f.CreatedFrom != 0, Student{CreateTime: f.CreatedTo + 1}, f.CreatedTo != 0)
+	case SortByModifiedTime:
+		sc.bound(Student{ModifiedTime: f.ModifiedFrom}, f.ModifiedFrom != 0, Student{ModifiedTime: f.ModifiedTo + 1}, f.ModifiedTo != 0)
+	case SortByName:
+		end, ok := prefixEnd(f.NamePrefix)
+		sc.bound(Student{Name: f.NamePrefix}, f.NamePrefix != "", Student{Name: end}, ok)
+	case SortByAge:
+		sc.bound(Student{Age: f.MinAge}, f.MinAge != 0, Student{Age: f.MaxAge + 1}, f.MaxAge != 0)
+	}
+	return sc
+}
+
+// prefixEnd returns the smallest string greater than every string starting
+// with p, or false if there is none.
+func prefixEnd(p string) (string, bool) {
+	b := []byte(p)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
+
+// indexScan is a range [lo, hi) of one index. The bounds carry an empty Id,
+// which sorts before every stored student with the same key.
+type indexScan struct {
+	key    SortKey
+	tree   *btree.BTree
+	lo, hi btree.Item
+}
+
+func (sc *indexScan) bound(lo Student, hasLo bool, hi Student, hasHi bool) {
+	if hasLo {
+		sc.lo = indexItem{sc.key, &lo}
+	}
+	if hasHi {
+		sc.hi = indexItem{sc.key, &hi}
+	}
+}
+
+// count returns how many students the range holds, or false once there are
+// limit of them.
+func (sc indexScan) count(limit int) (int, bool) {
+	n := 0
+	sc.walk(true, nil, func(Student) bool {
+		n++
+		return n < limit
+	})
+	return n, n < limit
+}
+
+// walk visits the students of the range in order, starting behind after if
+// it is set, until visit returns false.
+func (sc indexScan) walk(ascending bool, after *Student, visit func(Student) bool) {
+	if sc.tree == nil {
+		return
+	}
+	var cursor btree.Item
+	if after != nil {
+		cursor = indexItem{sc.key, after}
+	}
+	if ascending {
+		from := sc.lo
+		if cursor != nil && (from == nil || from.Less(cursor)) {
+			from = cursor
+		}
+		iter := func(i btree.Item) bool {
+			if sc.hi != nil && !i.Less(sc.hi) {
+				return false
+			}
+			if cursor != nil && !cursor.Less(i) {
+				return true
+			}
+			return visit(*i.(indexItem).Student)
+		}
+		if from == nil {
+			sc.tree.Ascend(iter)
+		} else {
+			sc.tree.AscendGreaterOrEqual(from, iter)
+		}
+		return
+	}
+	from := sc.hi
+	if cursor != nil && (from == nil || cursor.Less(from)) {
+		from = cursor
+	}
+	iter := func(i btree.Item) bool {
+		if sc.lo != nil && i.Less(sc.lo) {
+			return false
+		}
+		if cursor != nil && !i.Less(cursor) {
+			return true
+		}
+		return visit(*i.(indexItem).Student)
+	}
+	if from == nil {
+		sc.tree.Descend(iter)
+	} else {
+		sc.tree.DescendLessOrEqual(from, iter)
+	}
+}
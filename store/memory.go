@@ -0,0 +1,184 @@
+package store
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// DefaultShards is the number of lock stripes NewMemoryStore uses.
+const DefaultShards = 32
+
+// MemoryStore keeps students in a fixed set of shards, each a map guarded by
+// its own RWMutex and chosen by hashing the student id. Reads only take read
+// locks, so they never block each other, and a write only blocks the reads and
+// writes that land on the same shard. List is served from an ordered index
+// that writers update while holding their shard lock; writers take the index
+// lock only for the update itself and List only to pick up a snapshot, so
+// neither waits for the other to walk the index.
+type MemoryStore struct {
+	shards []shard
+	index  *orderedIndex
+}
+
+type shard struct {
+	studentInfo map[string]Student
+	mux         sync.RWMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return NewShardedMemoryStore(DefaultShards)
+}
+
+// NewShardedMemoryStore returns a MemoryStore striped over n locks. One shard
+// behaves like a single map behind a single lock.
+func NewShardedMemoryStore(n int) *MemoryStore {
+	if n < 1 {
+		n = 1
+	}
+	m := &MemoryStore{shards: make([]shard, n), index: newOrderedIndex()}
+	for i := range m.shards {
+		m.shards[i].studentInfo = make(map[string]Student)
+	}
+	return m
+}
+
+func (m *MemoryStore) shard(id string) *shard {
+	return &m.shards[stripe(id, len(m.shards))]
+}
+
+// stripe hashes id onto one of n locks.
+func stripe(id string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(n))
+}
+
+func (m *MemoryStore) Create(s Student) error {
+	sh := m.shard(s.Id)
+	sh.mux.Lock()
+	defer sh.mux.Unlock()
+	if _, ok := sh.studentInfo[s.Id]; ok {
+		return ErrAlreadyExists
+	}
+	s.Version = 1
+	sh.studentInfo[s.Id] = s
+	m.index.replace(nil, &s)
+	return nil
+}
+
+func (m *MemoryStore) CreateAll(students []Student) error {
+	unlock := m.lockShards(students)
+	defer unlock()
+	seen := make(map[string]bool, len(students))
+	for _, s := range students {
+		if _, ok := m.shard(s.Id).studentInfo[s.Id]; ok || seen[s.Id] {
+			return ErrAlreadyExists
+		}
+		seen[s.Id] = true
+	}
+	stored := make([]Student, len(students))
+	for i, s := range students {
+		s.Version = 1
+		stored[i] = s
+		m.shard(s.Id).studentInfo[s.Id] = s
+	}
+	m.index.insertAll(stored)
+	return nil
+}
+
+// lockShards write locks the shards that hold students, in shard order so
+// that two batches cannot wait for each other, and returns their unlock.
+func (m *MemoryStore) lockShards(students []Student) (unlock func()) {
+	locked := make([]bool, len(m.shards))
+	for _, s := range students {
+		locked[stripe(s.Id, len(m.shards))] = true
+	}
+	for i := range m.shards {
+		if locked[i] {
+			m.shards[i].mux.Lock()
+		}
+	}
+	return func() {
+		for i := range m.shards {
+			if locked[i] {
+				m.shards[i].mux.Unlock()
+			}
+		}
+	}
+}
+
+func (m *MemoryStore) Get(id string) (Student, error) {
+	sh := m.shard(id)
+	sh.mux.RLock()
+	defer sh.mux.RUnlock()
+	s, ok := sh.studentInfo[id]
+	if !ok {
+		return Student{}, ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *MemoryStore) Update(s Student) error {
+	sh := m.shard(s.Id)
+	sh.mux.Lock()
+	defer sh.mux.Unlock()
+	old, ok := sh.studentInfo[s.Id]
+	if !ok {
+		return ErrNotFound
+	}
+	if old.Version != s.Version {
+		return ErrVersionMismatch
+	}
+	s.Version++
+	sh.studentInfo[s.Id] = s
+	m.index.replace(&old, &s)
+	return nil
+}
+
+func (m *MemoryStore) Delete(id string, version int64) error {
+	sh := m.shard(id)
+	sh.mux.Lock()
+	defer sh.mux.Unlock()
+	old, ok := sh.studentInfo[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if version != 0 && old.Version != version {
+		return ErrVersionMismatch
+	}
+	delete(sh.studentInfo, id)
+	m.index.replace(&old, nil)
+	return nil
+}
+
+// Put stores s as is, bypassing the version checks. It is meant for loading
+// recovered or replicated state.
+func (m *MemoryStore) Put(s Student) {
+	sh := m.shard(s.Id)
+	sh.mux.Lock()
+	defer sh.mux.Unlock()
+	if old, ok := sh.studentInfo[s.Id]; ok {
+		m.index.replace(&old, &s)
+	} else {
+		m.index.replace(nil, &s)
+	}
+	sh.studentInfo[s.Id] = s
+}
+
+func (m *MemoryStore) remove(id string) {
+	sh := m.shard(id)
+	sh.mux.Lock()
+	defer sh.mux.Unlock()
+	if old, ok := sh.studentInfo[id]; ok {
+		m.index.replace(&old, nil)
+		delete(sh.studentInfo, id)
+	}
+}
+
+func (m *MemoryStore) len() int {
+	return m.index.len()
+}
+
+func (m *MemoryStore) List(q ListQuery) (ListResult, error) {
+	return m.index.list(q), nil
+}
@@ -0,0 +1,64 @@
+package store_test
+
+import (
+	"testing"
+
+	"mygolangproject/store"
+	"mygolangproject/store/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.StudentStore {
+		return store.NewMemoryStore()
+	})
+}
+
+// TestWatchableStore runs the suite through the write locks of the store the
+// server uses.
+func TestWatchableStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.StudentStore {
+		return store.NewWatchableStore(store.NewMemoryStore(), 16)
+	})
+}
+
+func TestMemoryHistory(t *testing.T) {
+	storetest.RunHistory(t, func(t *testing.T) store.HistoryStore {
+		return store.NewMemoryHistory()
+	})
+}
+
+func TestMemoryProfessions(t *testing.T) {
+	storetest.RunProfessions(t, func(t *testing.T) store.ProfessionStore {
+		return store.NewMemoryProfessions(store.DefaultProfessions)
+	})
+}
+
+func TestMemoryIdempotency(t *testing.T) {
+	storetest.RunIdempotency(t, func(t *testing.T) store.IdempotencyStore {
+		return store.NewMemoryIdempotency()
+	})
+}
+
+func TestMemoryAliases(t *testing.T) {
+	storetest.RunAliases(t, func(t *testing.T) store.AliasStore {
+		return store.NewMemoryAliases()
+	})
+}
+
+func TestMemorySequences(t *testing.T) {
+	storetest.RunSequences(t, func(t *testing.T) store.SequenceStore {
+		return store.NewMemorySequences(nil)
+	})
+}
+
+func TestMemoryCourses(t *testing.T) {
+	storetest.RunCourses(t, func(t *testing.T) store.CourseStore {
+		return store.NewMemoryCourses(nil)
+	})
+}
+
+func TestMemoryEnrollments(t *testing.T) {
+	storetest.RunEnrollments(t, func(t *testing.T) store.EnrollmentStore {
+		return store.NewMemoryEnrollments()
+	})
+}
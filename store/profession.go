@@ -0,0 +1,139 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrProfessionNotFound      = errors.New("profession is not exist")
+	ErrProfessionAlreadyExists = errors.New("profession already exist")
+)
+
+// Profession is an entry of the catalogue students choose their profession
+// from. Students refer to it by Code.
+type Profession struct {
+	Code     string
+	Names    map[string]string // 按语言标记的名称，如 zh、en
+	Active   bool              // 只能选择启用的专业，已有学生不受影响
+	Capacity int32             // 未删除学生的人数上限，0 表示不限
+}
+
+// DefaultProfessions seed an empty catalogue. They are the two professions
+// that existed before the catalogue did.
+var DefaultProfessions = []Profession{
+	{Code: "CST", Names: map[string]string{"zh": "计算机科学与技术", "en": "Computer Science and Technology"}, Active: true},
+	{Code: "SE", Names: map[string]string{"zh": "软件工程", "en": "Software Engineering"}, Active: true},
+}
+
+// legacyProfessions maps the profession names students stored before the
+// catalogue existed to the codes of the default professions.
+var legacyProfessions = map[string]string{
+	"计算机科学与技术": "CST",
+	"软件工程":     "SE",
+}
+
+// UpgradeProfession replaces a profession name stored by an earlier version
+// with its code.
+func UpgradeProfession(s *Student) {
+	if code, ok := legacyProfessions[s.Profession]; ok {
+		s.Profession = code
+	}
+}
+
+// Upgrade brings a student stored by any earlier version up to date.
+func Upgrade(s *Student) {
+	UpgradeTimes(s)
+	UpgradeProfession(s)
+}
+
+// ProfessionStore keeps the profession catalogue. Whether a profession is
+// still in use is up to the caller to check, since students live elsewhere.
+type ProfessionStore interface {
+	// Create fails with ErrProfessionAlreadyExists if the code is taken.
+	Create(p Profession) error
+	// Get returns the profession with the given code or ErrProfessionNotFound.
+	Get(code string) (Profession, error)
+	// List returns every profession ordered by code.
+	List() ([]Profession, error)
+	// Update replaces an existing profession or returns ErrProfessionNotFound.
+	Update(p Profession) error
+	// Delete removes a profession or returns ErrProfessionNotFound.
+	Delete(code string) error
+}
+
+type MemoryProfessions struct {
+	mux         sync.RWMutex
+	professions map[string]Profession
+}
+
+// NewMemoryProfessions returns a catalogue holding initial.
+func NewMemoryProfessions(initial []Profession) *MemoryProfessions {
+	m := &MemoryProfessions{professions: make(map[string]Profession)}
+	for _, p := range initial {
+		m.professions[p.Code] = copyProfession(p)
+	}
+	return m
+}
+
+// copyProfession keeps callers from sharing the Names map with the store.
+func copyProfession(p Profession) Profession {
+	names := make(map[string]string, len(p.Names))
+	for lang, name := range p.Names {
+		names[lang] = name
+	}
+	p.Names = names
+	return p
+}
+
+func (m *MemoryProfessions) Create(p Profession) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.professions[p.Code]; ok {
+		return ErrProfessionAlreadyExists
+	}
+	m.professions[p.Code] = copyProfession(p)
+	return nil
+}
+
+func (m *MemoryProfessions) Get(code string) (Profession, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	p, ok := m.professions[code]
+	if !ok {
+		return Profession{}, ErrProfessionNotFound
+	}
+	return copyProfession(p), nil
+}
+
+func (m *MemoryProfessions) List() ([]Profession, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	list := make([]Profession, 0, len(m.professions))
+	for _, p := range m.professions {
+		list = append(list, copyProfession(p))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list, nil
+}
+
+func (m *MemoryProfessions) Update(p Profession) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.professions[p.Code]; !ok {
+		return ErrProfessionNotFound
+	}
+	m.professions[p.Code] = copyProfession(p)
+	return nil
+}
+
+func (m *MemoryProfessions) Delete(code string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.professions[code]; !ok {
+		return ErrProfessionNotFound
+	}
+	delete(m.professions, code)
+	return nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"sort"
+	"strings"
+)
+
+type SortKey int
+
+const (
+	SortByCreateTime SortKey = iota
+	SortByModifiedTime
+	SortByName
+	SortByAge
+)
+
+// DeletedFilter selects between live and soft-deleted students.
+type DeletedFilter int
+
+const (
+	ExcludeDeleted DeletedFilter = iota
+	IncludeDeleted
+	OnlyDeleted
+)
+
+// Filter selects students for List. Zero values leave a field unconstrained,
+// except that soft-deleted students are excluded by default; all ranges are
+// inclusive.
+type Filter struct {
+	Profession   string
+	MinAge       int32
+	MaxAge       int32
+	NamePrefix   string
+	CreatedFrom  int64
+	CreatedTo    int64
+	ModifiedFrom int64
+	ModifiedTo   int64
+	Deleted      DeletedFilter
+	DeletedTo    int64
+	// Flagged selects only the students flagged as possible duplicates.
+	Flagged bool
+	Number  string
+}
+
+// ListQuery describes one page of a List call. Students are ordered by SortBy
+// and then by Id in the same direction, so the order is total and a page can
+// be resumed after any student regardless of later inserts.
+type ListQuery struct {
+	Filter    Filter
+	SortBy    SortKey
+	Ascending bool
+	// After resumes the listing behind this student; only its Id and the
+	// field named by SortBy are consulted.
+	After *Student
+	// Limit caps the page size; zero returns every remaining student.
+	Limit int
+}
+
+type ListResult struct {
+	Students []Student
+	// Total counts every student matching the filter, across all pages.
+	Total int
+	// More reports whether students remain after this page.
+	More bool
+}
+
+func (f Filter) Match(s Student) bool {
+	switch {
+	case f.Deleted == ExcludeDeleted && s.DeletedTime != 0:
+		return false
+	case f.Deleted == OnlyDeleted && s.DeletedTime == 0:
+		return false
+	case f.DeletedTo != 0 && (s.DeletedTime == 0 || s.DeletedTime > f.DeletedTo):
+		return false
+	case f.Profession != "" && s.Profession != f.Profession:
+		return false
+	case f.MinAge != 0 && s.Age < f.MinAge:
+		return false
+	case f.MaxAge != 0 && s.Age > f.MaxAge:
+		return false
+	case f.NamePrefix != "" && !strings.HasPrefix(s.Name, f.NamePrefix):
+		return false
+	case f.CreatedFrom != 0 && s.CreateTime < f.CreatedFrom:
+		return false
+	case f.CreatedTo != 0 && s.CreateTime > f.CreatedTo:
+		return false
+	case f.ModifiedFrom != 0 && s.ModifiedTime < f.ModifiedFrom:
+		return false
+	case f.ModifiedTo != 0 && s.ModifiedTime > f.ModifiedTo:
+		return false
+	case f.Flagged && s.DuplicateOf == "":
+		return false
+	case f.Number != "" && s.Number != f.Number:
+		return false
+	}
+	return true
+}
+
+// Compare orders a and b ascending by key with Id as the tiebreak.
+func Compare(key SortKey, a, b Student) int {
+	var c int
+	switch key {
+	case SortByModifiedTime:
+		c = compareInt(a.ModifiedTime, b.ModifiedTime)
+	case SortByName:
+		c = strings.Compare(a.Name, b.Name)
+	case SortByAge:
+		c = compareInt(int64(a.Age), int64(b.Age))
+	default:
+		c = compareInt(a.CreateTime, b.CreateTime)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.Id, b.Id)
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// before reports whether a is listed before b.
+func (q ListQuery) before(a, b Student) bool {
+	c := Compare(q.SortBy, a, b)
+	if q.Ascending {
+		return c < 0
+	}
+	return c > 0
+}
+
+// ApplyQuery filters, sorts and pages an unordered slice of students. It is
+// used by backends without a query engine of their own; list is reordered.
+func ApplyQuery(list []Student, q ListQuery) ListResult {
+	matched := list[:0]
+	for _, s := range list {
+		if q.Filter.Match(s) {
+			matched = append(matched, s)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return q.before(matched[i], matched[j]) })
+
+	res := ListResult{Total: len(matched)}
+	start := 0
+	if q.After != nil {
+		start = sort.Search(len(matched), func(i int) bool { return q.before(*q.After, matched[i]) })
+	}
+	end := len(matched)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+		res.More = true
+	}
+	res.Students = append([]Student(nil), matched[start:end]...)
+	return res
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSequenceConflict is returned by MemorySequences.Advance when the
+// counter has moved on since the caller read it.
+var ErrSequenceConflict = errors.New("sequence advanced concurrently")
+
+// SequenceStore hands out numbers from named counters that start at 1. No
+// number is handed out twice, not even by different members of a cluster;
+// numbers lost to failures leave gaps.
+type SequenceStore interface {
+	// Next advances the named counter and returns its new value.
+	Next(name string) (int64, error)
+}
+
+// MemorySequences keeps the counters in a map.
+type MemorySequences struct {
+	mux      sync.Mutex
+	counters map[string]int64
+}
+
+func NewMemorySequences(counters map[string]int64) *MemorySequences {
+	m := &MemorySequences{counters: make(map[string]int64, len(counters))}
+	for name, v := range counters {
+		m.counters[name] = v
+	}
+	return m
+}
+
+func (m *MemorySequences) Next(name string) (int64, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.counters[name]++
+	return m.counters[name], nil
+}
+
+// Current returns the last value handed out by the named counter, 0 if none.
+func (m *MemorySequences) Current(name string) int64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.counters[name]
+}
+
+// Advance moves the named counter from current to current+1, or fails with
+// ErrSequenceConflict if it is no longer at current.
+func (m *MemorySequences) Advance(name string, current int64) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.counters[name] != current {
+		return ErrSequenceConflict
+	}
+	m.counters[name] = current + 1
+	return nil
+}
+
+// All returns a copy of every counter.
+func (m *MemorySequences) All() map[string]int64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	all := make(map[string]int64, len(m.counters))
+	for name, v := range m.counters {
+		all[name] = v
+	}
+	return all
+}
@@ -0,0 +1,39 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"mygolangproject/store"
+)
+
+// Aliases implements store.AliasStore in the student_aliases table of the
+// same database.
+type Aliases struct {
+	db *sql.DB
+}
+
+func (s *Store) Aliases() *Aliases {
+	return &Aliases{db: s.db}
+}
+
+func (a *Aliases) Put(alias store.Alias) error {
+	_, err := a.db.Exec(`INSERT INTO student_aliases (retiredId, survivorId, time) VALUES (?, ?, ?)
+		ON CONFLICT (retiredId) DO UPDATE SET survivorId = excluded.survivorId, time = excluded.time`,
+		alias.RetiredId, alias.SurvivorId, alias.Time)
+	return err
+}
+
+func (a *Aliases) Delete(retiredId string) error {
+	_, err := a.db.Exec(`DELETE FROM student_aliases WHERE retiredId = ?`, retiredId)
+	return err
+}
+
+func (a *Aliases) Get(retiredId string) (store.Alias, error) {
+	alias := store.Alias{RetiredId: retiredId}
+	err := a.db.QueryRow(`SELECT survivorId, time FROM student_aliases WHERE retiredId = ?`, retiredId).
+		Scan(&alias.SurvivorId, &alias.Time)
+	if err == sql.ErrNoRows {
+		return alias, store.ErrNoAlias
+	}
+	return alias, err
+}
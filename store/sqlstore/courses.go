@@ -0,0 +1,99 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"mygolangproject/store"
+)
+
+// Courses implements store.CourseStore in the courses table of the same
+// database. The professions a course is open to are stored as JSON.
+type Courses struct {
+	db *sql.DB
+}
+
+func (s *Store) Courses() *Courses {
+	return &Courses{db: s.db}
+}
+
+const courseColumns = `code, title, credits, professions, capacity`
+
+func (c *Courses) Create(course store.Course) error {
+	professions, err := marshalProfessions(course.Professions)
+	if err != nil {
+		return err
+	}
+	res, err := c.db.Exec(`INSERT INTO courses (`+courseColumns+`) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (code) DO NOTHING`,
+		course.Code, course.Title, course.Credits, professions, course.Capacity)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrCourseAlreadyExists)
+}
+
+func (c *Courses) Get(code string) (store.Course, error) {
+	course, err := scanCourse(c.db.QueryRow(`SELECT `+courseColumns+` FROM courses WHERE code = ?`, code))
+	if err == sql.ErrNoRows {
+		return course, store.ErrCourseNotFound
+	}
+	return course, err
+}
+
+func (c *Courses) List() ([]store.Course, error) {
+	rows, err := c.db.Query(`SELECT ` + courseColumns + ` FROM courses ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Course
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, course)
+	}
+	return list, rows.Err()
+}
+
+func (c *Courses) Update(course store.Course) error {
+	professions, err := marshalProfessions(course.Professions)
+	if err != nil {
+		return err
+	}
+	res, err := c.db.Exec(`UPDATE courses SET title = ?, credits = ?, professions = ?, capacity = ? WHERE code = ?`,
+		course.Title, course.Credits, professions, course.Capacity, course.Code)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrCourseNotFound)
+}
+
+func (c *Courses) Delete(code string) error {
+	res, err := c.db.Exec(`DELETE FROM courses WHERE code = ?`, code)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrCourseNotFound)
+}
+
+// marshalProfessions stores no professions as an empty list rather than
+// null.
+func marshalProfessions(professions []string) (string, error) {
+	if professions == nil {
+		professions = []string{}
+	}
+	b, err := json.Marshal(professions)
+	return string(b), err
+}
+
+func scanCourse(row scanner) (store.Course, error) {
+	var course store.Course
+	var professions string
+	if err := row.Scan(&course.Code, &course.Title, &course.Credits, &professions, &course.Capacity); err != nil {
+		return course, err
+	}
+	return course, json.Unmarshal([]byte(professions), &course.Professions)
+}
@@ -0,0 +1,62 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"mygolangproject/store"
+)
+
+// Enrollments implements store.EnrollmentStore in the enrollments table of
+// the same database.
+type Enrollments struct {
+	db *sql.DB
+}
+
+func (s *Store) Enrollments() *Enrollments {
+	return &Enrollments{db: s.db}
+}
+
+func (e *Enrollments) Enroll(enrollment store.Enrollment) error {
+	res, err := e.db.Exec(`INSERT INTO enrollments (courseCode, studentId, time) VALUES (?, ?, ?)
+		ON CONFLICT (courseCode, studentId) DO NOTHING`,
+		enrollment.CourseCode, enrollment.StudentId, enrollment.Time)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrAlreadyEnrolled)
+}
+
+func (e *Enrollments) Drop(courseCode, studentId string) error {
+	res, err := e.db.Exec(`DELETE FROM enrollments WHERE courseCode = ? AND studentId = ?`, courseCode, studentId)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrNotEnrolled)
+}
+
+func (e *Enrollments) ByCourse(courseCode string) ([]store.Enrollment, error) {
+	return e.list(`SELECT courseCode, studentId, time FROM enrollments WHERE courseCode = ?
+		ORDER BY time, studentId`, courseCode)
+}
+
+func (e *Enrollments) ByStudent(studentId string) ([]store.Enrollment, error) {
+	return e.list(`SELECT courseCode, studentId, time FROM enrollments WHERE studentId = ?
+		ORDER BY courseCode`, studentId)
+}
+
+func (e *Enrollments) list(query string, arg string) ([]store.Enrollment, error) {
+	rows, err := e.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []store.Enrollment{}
+	for rows.Next() {
+		var enrollment store.Enrollment
+		if err := rows.Scan(&enrollment.CourseCode, &enrollment.StudentId, &enrollment.Time); err != nil {
+			return nil, err
+		}
+		list = append(list, enrollment)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"mygolangproject/store"
+)
+
+// History implements store.HistoryStore in the student_history table of the
+// same database. Before and after images are stored as JSON.
+type History struct {
+	db *sql.DB
+}
+
+func (s *Store) History() *History {
+	return &History{db: s.db}
+}
+
+func (h *History) Append(e store.HistoryEntry) error {
+	before, err := marshalImage(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalImage(e.After)
+	if err != nil {
+		return err
+	}
+	_, err = h.db.Exec(`INSERT INTO student_history (studentId, action, actor, requestId, time, before, after, mergedWith)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.StudentId, e.Action, e.Actor, e.RequestId, e.Time, before, after, e.MergedWith)
+	return err
+}
+
+func (h *History) List(studentId string) ([]store.HistoryEntry, error) {
+	rows, err := h.db.Query(`SELECT studentId, action, actor, requestId, time, before, after, mergedWith
+		FROM student_history WHERE studentId = ? ORDER BY seq`, studentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []store.HistoryEntry
+	for rows.Next() {
+		var e store.HistoryEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.StudentId, &e.Action, &e.Actor, &e.RequestId, &e.Time, &before, &after, &e.MergedWith); err != nil {
+			return nil, err
+		}
+		if e.Before, err = unmarshalImage(before); err != nil {
+			return nil, err
+		}
+		if e.After, err = unmarshalImage(after); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Snapshot picks the latest entry at or before asOf of every student.
+func (h *History) Snapshot(asOf int64) ([]store.Student, error) {
+	rows, err := h.db.Query(`SELECT after FROM student_history
+		WHERE seq IN (SELECT MAX(seq) FROM student_history WHERE time <= ? GROUP BY studentId)
+			AND after IS NOT NULL`, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Student
+	for rows.Next() {
+		var after sql.NullString
+		if err := rows.Scan(&after); err != nil {
+			return nil, err
+		}
+		s, err := unmarshalImage(after)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+	return list, rows.Err()
+}
+
+func marshalImage(s *store.Student) (sql.NullString, error) {
+	if s == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(s)
+	return sql.NullString{String: string(b), Valid: true}, err
+}
+
+func unmarshalImage(v sql.NullString) (*store.Student, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	s := &store.Student{}
+	return s, json.Unmarshal([]byte(v.String), s)
+}
@@ -0,0 +1,45 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	"mygolangproject/store"
+)
+
+// Idempotency implements store.IdempotencyStore in the idempotency_results
+// table of the same database.
+type Idempotency struct {
+	db *sql.DB
+}
+
+func (s *Store) Idempotency() *Idempotency {
+	return &Idempotency{db: s.db}
+}
+
+func (i *Idempotency) Put(r store.IdempotentResult) error {
+	_, err := i.db.Exec(`INSERT INTO idempotency_results (idempotencyKey, method, request, response, time)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (idempotencyKey) DO UPDATE SET
+			method = excluded.method, request = excluded.request, response = excluded.response, time = excluded.time`,
+		r.Key, r.Method, r.Request, r.Response, r.Time)
+	return err
+}
+
+func (i *Idempotency) Get(key string) (store.IdempotentResult, error) {
+	r := store.IdempotentResult{Key: key}
+	err := i.db.QueryRow(`SELECT method, request, response, time FROM idempotency_results WHERE idempotencyKey = ?`, key).
+		Scan(&r.Method, &r.Request, &r.Response, &r.Time)
+	if err == sql.ErrNoRows {
+		return r, store.ErrNoIdempotentResult
+	}
+	return r, err
+}
+
+func (i *Idempotency) Expire(before int64) (int, error) {
+	res, err := i.db.Exec(`DELETE FROM idempotency_results WHERE time < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,292 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"mygolangproject/store"
+)
+
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+	// apply, if set, runs after stmts for changes SQL alone cannot express.
+	apply func(tx *sql.Tx) error
+}
+
+// migrations are applied in order and never edited once released; schema
+// changes are made by appending a new version.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create students",
+		stmts: []string{`CREATE TABLE students (
+			id           TEXT PRIMARY KEY,
+			name         TEXT    NOT NULL,
+			age          INTEGER NOT NULL,
+			profession   TEXT    NOT NULL,
+			createTime   INTEGER NOT NULL,
+			modifiedTime INTEGER NOT NULL
+		)`},
+	},
+	{
+		version: 2,
+		name:    "index students by createTime",
+		stmts:   []string{`CREATE INDEX students_createTime ON students (createTime DESC, id)`},
+	},
+	{
+		version: 3,
+		name:    "index every QueryList sort key",
+		stmts: []string{
+			// Keyset pages order by (key, id) in one direction, which the
+			// mixed-direction index from version 2 cannot serve.
+			`DROP INDEX students_createTime`,
+			`CREATE INDEX students_createTime ON students (createTime, id)`,
+			`CREATE INDEX students_modifiedTime ON students (modifiedTime, id)`,
+			`CREATE INDEX students_name ON students (name, id)`,
+			`CREATE INDEX students_age ON students (age, id)`,
+			`CREATE INDEX students_profession ON students (profession)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add students.version",
+		stmts:   []string{`ALTER TABLE students ADD COLUMN version INTEGER NOT NULL DEFAULT 1`},
+	},
+	{
+		version: 5,
+		name:    "add students.deletedTime",
+		stmts: []string{
+			`ALTER TABLE students ADD COLUMN deletedTime INTEGER NOT NULL DEFAULT 0`,
+			`CREATE INDEX students_deletedTime ON students (deletedTime)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "create student_history",
+		stmts: []string{
+			`CREATE TABLE student_history (
+				seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+				studentId TEXT    NOT NULL,
+				action    TEXT    NOT NULL,
+				actor     TEXT    NOT NULL,
+				requestId TEXT    NOT NULL,
+				time      INTEGER NOT NULL,
+				before    TEXT,
+				after     TEXT
+			)`,
+			`CREATE INDEX student_history_studentId ON student_history (studentId, seq)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "index student_history by time",
+		stmts:   []string{`CREATE INDEX student_history_time ON student_history (time, studentId)`},
+	},
+	{
+		version: 8,
+		name:    "record createTime and modifiedTime in nanoseconds",
+		stmts: []string{
+			`UPDATE students SET createTime = createTime * 1000000000 WHERE createTime > 0 AND createTime < 100000000000`,
+			`UPDATE students SET modifiedTime = modifiedTime * 1000000000 WHERE modifiedTime > 0 AND modifiedTime < 100000000000`,
+		},
+		apply: upgradeHistory(store.UpgradeTimes),
+	},
+	{
+		version: 9,
+		name:    "create professions and refer to them by code",
+		stmts: []string{
+			`CREATE TABLE professions (
+				code     TEXT    PRIMARY KEY,
+				names    TEXT    NOT NULL,
+				active   INTEGER NOT NULL,
+				capacity INTEGER NOT NULL
+			)`,
+			`UPDATE students SET profession = 'CST' WHERE profession = '计算机科学与技术'`,
+			`UPDATE students SET profession = 'SE' WHERE profession = '软件工程'`,
+		},
+		apply: func(tx *sql.Tx) error {
+			for _, p := range store.DefaultProfessions {
+				if err := insertProfession(tx, p); err != nil {
+					return err
+				}
+			}
+			return upgradeHistory(store.UpgradeProfession)(tx)
+		},
+	},
+	{
+		version: 10,
+		name:    "create idempotency_results",
+		stmts: []string{
+			`CREATE TABLE idempotency_results (
+				idempotencyKey TEXT    PRIMARY KEY,
+				method         TEXT    NOT NULL,
+				request        BLOB    NOT NULL,
+				response       BLOB    NOT NULL,
+				time           INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idempotency_results_time ON idempotency_results (time)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "add students.duplicateOf",
+		stmts: []string{
+			`ALTER TABLE students ADD COLUMN duplicateOf TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX students_duplicateOf ON students (duplicateOf) WHERE duplicateOf != ''`,
+		},
+	},
+	{
+		version: 12,
+		name:    "create student_aliases and add student_history.mergedWith",
+		stmts: []string{
+			`CREATE TABLE student_aliases (
+				retiredId  TEXT    PRIMARY KEY,
+				survivorId TEXT    NOT NULL,
+				time       INTEGER NOT NULL
+			)`,
+			`ALTER TABLE student_history ADD COLUMN mergedWith TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 13,
+		name:    "add students.number and create sequences",
+		stmts: []string{
+			`ALTER TABLE students ADD COLUMN number TEXT NOT NULL DEFAULT ''`,
+			`CREATE UNIQUE INDEX students_number ON students (number) WHERE number != ''`,
+			`CREATE TABLE sequences (
+				name  TEXT    PRIMARY KEY,
+				value INTEGER NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 14,
+		name:    "create courses and enrollments",
+		stmts: []string{
+			`CREATE TABLE courses (
+				code        TEXT    PRIMARY KEY,
+				title       TEXT    NOT NULL,
+				credits     INTEGER NOT NULL,
+				professions TEXT    NOT NULL,
+				capacity    INTEGER NOT NULL
+			)`,
+			`CREATE TABLE enrollments (
+				courseCode TEXT    NOT NULL,
+				studentId  TEXT    NOT NULL,
+				time       INTEGER NOT NULL,
+				PRIMARY KEY (courseCode, studentId)
+			)`,
+			`CREATE INDEX enrollments_studentId ON enrollments (studentId, courseCode)`,
+		},
+	},
+}
+
+// migrate brings db up to the latest schema version. Each migration runs in
+// its own transaction together with the bookkeeping row that records it.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INTEGER PRIMARY KEY,
+		appliedTime INTEGER NOT NULL
+	)`); err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if latest := migrations[len(migrations)-1].version; current > latest {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, latest)
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%v): %v", m.version, m.name, err)
+		}
+		log.Printf("applied migration %d: %v", m.version, m.name)
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range m.stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if m.apply != nil {
+		if err := m.apply(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, appliedTime) VALUES (?, ?)`,
+		m.version, time.Now().Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// upgradeHistory returns a migration step that applies upgrade to the
+// student images in student_history, which are stored as JSON, the way the
+// statements of the migration change the students table.
+func upgradeHistory(upgrade func(*store.Student)) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		return upgradeImages(tx, upgrade)
+	}
+}
+
+func upgradeImages(tx *sql.Tx, upgrade func(*store.Student)) error {
+	rows, err := tx.Query(`SELECT seq, before, after FROM student_history`)
+	if err != nil {
+		return err
+	}
+	type images struct {
+		seq           int64
+		before, after sql.NullString
+	}
+	var all []images
+	for rows.Next() {
+		var im images
+		if err := rows.Scan(&im.seq, &im.before, &im.after); err != nil {
+			rows.Close()
+			return err
+		}
+		all = append(all, im)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, im := range all {
+		var upgraded [2]sql.NullString
+		for i, v := range []sql.NullString{im.before, im.after} {
+			s, err := unmarshalImage(v)
+			if err != nil {
+				return err
+			}
+			if s != nil {
+				upgrade(s)
+			}
+			if upgraded[i], err = marshalImage(s); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`UPDATE student_history SET before = ?, after = ? WHERE seq = ?`,
+			upgraded[0], upgraded[1], im.seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
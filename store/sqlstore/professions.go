@@ -0,0 +1,97 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"mygolangproject/store"
+)
+
+// Professions implements store.ProfessionStore in the professions table of
+// the same database. Names are stored as JSON.
+type Professions struct {
+	db *sql.DB
+}
+
+func (s *Store) Professions() *Professions {
+	return &Professions{db: s.db}
+}
+
+// execer is what *sql.DB and *sql.Tx have in common for writes.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertProfession(db execer, p store.Profession) error {
+	names, err := json.Marshal(p.Names)
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(`INSERT INTO professions (code, names, active, capacity) VALUES (?, ?, ?, ?)
+		ON CONFLICT (code) DO NOTHING`,
+		p.Code, string(names), p.Active, p.Capacity)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrProfessionAlreadyExists)
+}
+
+func (p *Professions) Create(prof store.Profession) error {
+	return insertProfession(p.db, prof)
+}
+
+func (p *Professions) Get(code string) (store.Profession, error) {
+	row := p.db.QueryRow(`SELECT code, names, active, capacity FROM professions WHERE code = ?`, code)
+	prof, err := scanProfession(row)
+	if err == sql.ErrNoRows {
+		return prof, store.ErrProfessionNotFound
+	}
+	return prof, err
+}
+
+func (p *Professions) List() ([]store.Profession, error) {
+	rows, err := p.db.Query(`SELECT code, names, active, capacity FROM professions ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Profession
+	for rows.Next() {
+		prof, err := scanProfession(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, prof)
+	}
+	return list, rows.Err()
+}
+
+func (p *Professions) Update(prof store.Profession) error {
+	names, err := json.Marshal(prof.Names)
+	if err != nil {
+		return err
+	}
+	res, err := p.db.Exec(`UPDATE professions SET names = ?, active = ?, capacity = ? WHERE code = ?`,
+		string(names), prof.Active, prof.Capacity, prof.Code)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrProfessionNotFound)
+}
+
+func (p *Professions) Delete(code string) error {
+	res, err := p.db.Exec(`DELETE FROM professions WHERE code = ?`, code)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrProfessionNotFound)
+}
+
+func scanProfession(row scanner) (store.Profession, error) {
+	var prof store.Profession
+	var names string
+	if err := row.Scan(&prof.Code, &names, &prof.Active, &prof.Capacity); err != nil {
+		return prof, err
+	}
+	return prof, json.Unmarshal([]byte(names), &prof.Names)
+}
@@ -0,0 +1,31 @@
+package sqlstore
+
+import "database/sql"
+
+// Sequences implements store.SequenceStore in the sequences table of the
+// same database.
+type Sequences struct {
+	db *sql.DB
+}
+
+func (s *Store) Sequences() *Sequences {
+	return &Sequences{db: s.db}
+}
+
+func (q *Sequences) Next(name string) (int64, error) {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	var value int64
+	_, err = tx.Exec(`INSERT INTO sequences (name, value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET value = value + 1`, name)
+	if err == nil {
+		err = tx.QueryRow(`SELECT value FROM sequences WHERE name = ?`, name).Scan(&value)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return value, tx.Commit()
+}
@@ -0,0 +1,242 @@
+// Package sqlstore implements store.StudentStore on top of an embedded
+// SQLite database file.
+package sqlstore
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+	"mygolangproject/store"
+)
+
+const studentColumns = `id, name, age, profession, createTime, modifiedTime, version, deletedTime, duplicateOf, number`
+
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the database file at path and migrates
+// it to the latest schema.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStudent(row scanner) (store.Student, error) {
+	var stu store.Student
+	err := row.Scan(&stu.Id, &stu.Name, &stu.Age, &stu.Profession, &stu.CreateTime, &stu.ModifiedTime, &stu.Version, &stu.DeletedTime, &stu.DuplicateOf, &stu.Number)
+	return stu, err
+}
+
+func (s *Store) Create(stu store.Student) error {
+	return insertStudent(s.db, stu)
+}
+
+// CreateAll inserts the students in one transaction.
+func (s *Store) CreateAll(students []store.Student) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stu := range students {
+		if err := insertStudent(tx, stu); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertStudent(db execer, stu store.Student) error {
+	res, err := db.Exec(`INSERT INTO students (`+studentColumns+`) VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		stu.Id, stu.Name, stu.Age, stu.Profession, stu.CreateTime, stu.ModifiedTime, stu.DeletedTime, stu.DuplicateOf, stu.Number)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res, store.ErrAlreadyExists)
+}
+
+func (s *Store) Get(id string) (store.Student, error) {
+	stu, err := scanStudent(s.db.QueryRow(`SELECT `+studentColumns+` FROM students WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return store.Student{}, store.ErrNotFound
+	}
+	return stu, err
+}
+
+func (s *Store) Update(stu store.Student) error {
+	res, err := s.db.Exec(`UPDATE students
+		SET name = ?, age = ?, profession = ?, createTime = ?, modifiedTime = ?, deletedTime = ?,
+			duplicateOf = ?, number = ?, version = version + 1
+		WHERE id = ? AND version = ?`,
+		stu.Name, stu.Age, stu.Profession, stu.CreateTime, stu.ModifiedTime, stu.DeletedTime, stu.DuplicateOf, stu.Number,
+		stu.Id, stu.Version)
+	if err != nil {
+		return err
+	}
+	return s.expectOneRow(res, stu.Id)
+}
+
+func (s *Store) Delete(id string, version int64) error {
+	res, err := s.db.Exec(`DELETE FROM students WHERE id = ? AND (? = 0 OR version = ?)`, id, version, version)
+	if err != nil {
+		return err
+	}
+	return s.expectOneRow(res, id)
+}
+
+// expectOneRow tells apart a missing student from a version conflict when a
+// conditional write matched no row.
+func (s *Store) expectOneRow(res sql.Result, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil || n != 0 {
+		return err
+	}
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	return store.ErrVersionMismatch
+}
+
+func (s *Store) List(q store.ListQuery) (store.ListResult, error) {
+	var res store.ListResult
+	where, args := filterClause(q.Filter)
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM students`+where, args...).Scan(&res.Total); err != nil {
+		return res, err
+	}
+
+	col, dir, cmp := sortColumns[q.SortBy], "DESC", "<"
+	if q.Ascending {
+		dir, cmp = "ASC", ">"
+	}
+	if q.After != nil {
+		key := sortValue(q.SortBy, *q.After)
+		where = andClause(where, `(`+col+` `+cmp+` ? OR (`+col+` = ? AND id `+cmp+` ?))`)
+		args = append(args, key, key, q.After.Id)
+	}
+	stmt := `SELECT ` + studentColumns + ` FROM students` + where + ` ORDER BY ` + col + ` ` + dir + `, id ` + dir
+	if q.Limit > 0 {
+		// Fetch one extra row to learn whether another page follows.
+		stmt += ` LIMIT ?`
+		args = append(args, q.Limit+1)
+	}
+	rows, err := s.db.Query(stmt, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		stu, err := scanStudent(rows)
+		if err != nil {
+			return res, err
+		}
+		res.Students = append(res.Students, stu)
+	}
+	if q.Limit > 0 && len(res.Students) > q.Limit {
+		res.Students = res.Students[:q.Limit]
+		res.More = true
+	}
+	return res, rows.Err()
+}
+
+var sortColumns = map[store.SortKey]string{
+	store.SortByCreateTime:   "createTime",
+	store.SortByModifiedTime: "modifiedTime",
+	store.SortByName:         "name",
+	store.SortByAge:          "age",
+}
+
+func sortValue(key store.SortKey, stu store.Student) interface{} {
+	switch key {
+	case store.SortByModifiedTime:
+		return stu.ModifiedTime
+	case store.SortByName:
+		return stu.Name
+	case store.SortByAge:
+		return stu.Age
+	}
+	return stu.CreateTime
+}
+
+func filterClause(f store.Filter) (string, []interface{}) {
+	var where string
+	var args []interface{}
+	add := func(cond string, arg ...interface{}) {
+		where = andClause(where, cond)
+		args = append(args, arg...)
+	}
+	switch f.Deleted {
+	case store.ExcludeDeleted:
+		add(`deletedTime = 0`)
+	case store.OnlyDeleted:
+		add(`deletedTime != 0`)
+	}
+	if f.DeletedTo != 0 {
+		add(`deletedTime != 0 AND deletedTime <= ?`, f.DeletedTo)
+	}
+	if f.Profession != "" {
+		add(`profession = ?`, f.Profession)
+	}
+	if f.MinAge != 0 {
+		add(`age >= ?`, f.MinAge)
+	}
+	if f.MaxAge != 0 {
+		add(`age <= ?`, f.MaxAge)
+	}
+	if f.NamePrefix != "" {
+		add(`substr(name, 1, length(?)) = ?`, f.NamePrefix, f.NamePrefix)
+	}
+	if f.CreatedFrom != 0 {
+		add(`createTime >= ?`, f.CreatedFrom)
+	}
+	if f.CreatedTo != 0 {
+		add(`createTime <= ?`, f.CreatedTo)
+	}
+	if f.ModifiedFrom != 0 {
+		add(`modifiedTime >= ?`, f.ModifiedFrom)
+	}
+	if f.ModifiedTo != 0 {
+		add(`modifiedTime <= ?`, f.ModifiedTo)
+	}
+	if f.Flagged {
+		add(`duplicateOf != ''`)
+	}
+	if f.Number != "" {
+		add(`number = ?`, f.Number)
+	}
+	return where, args
+}
+
+func andClause(where, cond string) string {
+	if where == "" {
+		return ` WHERE ` + cond
+	}
+	return where + ` AND ` + cond
+}
+
+func expectOneRow(res sql.Result, otherwise error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return otherwise
+	}
+	return nil
+}
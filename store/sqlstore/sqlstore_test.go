@@ -0,0 +1,58 @@
+package sqlstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mygolangproject/store"
+	"mygolangproject/store/storetest"
+)
+
+// open opens a new database that is closed and removed when the test ends.
+func open(t *testing.T) *Store {
+	dir, err := ioutil.TempDir("", "sqlstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	s, err := Open(filepath.Join(dir, "students.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.StudentStore { return open(t) })
+}
+
+func TestHistory(t *testing.T) {
+	storetest.RunHistory(t, func(t *testing.T) store.HistoryStore { return open(t).History() })
+}
+
+func TestProfessions(t *testing.T) {
+	storetest.RunProfessions(t, func(t *testing.T) store.ProfessionStore { return open(t).Professions() })
+}
+
+func TestIdempotency(t *testing.T) {
+	storetest.RunIdempotency(t, func(t *testing.T) store.IdempotencyStore { return open(t).Idempotency() })
+}
+
+func TestAliases(t *testing.T) {
+	storetest.RunAliases(t, func(t *testing.T) store.AliasStore { return open(t).Aliases() })
+}
+
+func TestSequences(t *testing.T) {
+	storetest.RunSequences(t, func(t *testing.T) store.SequenceStore { return open(t).Sequences() })
+}
+
+func TestCourses(t *testing.T) {
+	storetest.RunCourses(t, func(t *testing.T) store.CourseStore { return open(t).Courses() })
+}
+
+func TestEnrollments(t *testing.T) {
+	storetest.RunEnrollments(t, func(t *testing.T) store.EnrollmentStore { return open(t).Enrollments() })
+}
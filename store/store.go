@@ -0,0 +1,67 @@
+// Package store defines the storage abstraction behind the student gRPC
+// service and its in-memory implementation.
+package store
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("student is not exist")
+	ErrAlreadyExists = errors.New("student already exist")
+	// ErrVersionMismatch is returned by a conditional write when the record
+	// was changed since the caller read it.
+	ErrVersionMismatch = errors.New("student version mismatch")
+)
+
+type Student struct {
+	Id           string //唯一
+	Name         string //仅支持英文，非空
+	Age          int32  //非空，范围【10，100】
+	Profession   string //专业代码，见专业目录
+	CreateTime   int64  //创建时间，unix 纳秒
+	ModifiedTime int64  //修改时间，unix 纳秒
+	Version      int64  //每次写入加一，用于乐观并发控制
+	DeletedTime  int64  //软删除时间，unix 秒，0 表示未删除
+	DuplicateOf  string //疑似重复的已有学生 id，待复核，空表示未标记
+	Number       string //学号，如 2026-SE-0042，按注册年份与专业顺序编号，全局唯一
+}
+
+// legacyTimeLimit tells the unix seconds that createTime and modifiedTime
+// held before they were recorded in nanoseconds: as seconds it lies thousands
+// of years ahead, as nanoseconds in 1970.
+const legacyTimeLimit = 1e11
+
+// UpgradeTimes converts the createTime and modifiedTime of a student stored
+// by an earlier version from seconds to nanoseconds.
+func UpgradeTimes(s *Student) {
+	if s.CreateTime > 0 && s.CreateTime < legacyTimeLimit {
+		s.CreateTime *= 1e9
+	}
+	if s.ModifiedTime > 0 && s.ModifiedTime < legacyTimeLimit {
+		s.ModifiedTime *= 1e9
+	}
+}
+
+// StudentStore is implemented by every student storage backend.
+// Implementations must be safe for concurrent use.
+type StudentStore interface {
+	// Create stores a new student at Version 1, failing with ErrAlreadyExists
+	// if the id is taken.
+	Create(s Student) error
+	// CreateAll stores every student like Create, or none of them if one id
+	// is taken or repeated, failing with ErrAlreadyExists. No reader sees
+	// some of the students without the others.
+	CreateAll(students []Student) error
+	// Get returns the student with the given id or ErrNotFound.
+	Get(id string) (Student, error)
+	// Update replaces an existing student or returns ErrNotFound. s.Version
+	// must be the version the caller read, otherwise ErrVersionMismatch is
+	// returned; the stored record gets s.Version+1.
+	Update(s Student) error
+	// Delete removes the student with the given id or returns ErrNotFound.
+	// A non-zero version makes the delete conditional like Update.
+	Delete(id string, version int64) error
+	// List returns one page of the students matching q. The zero ListQuery
+	// returns every student that is not soft-deleted, newest createTime
+	// first.
+	List(q ListQuery) (ListResult, error)
+}
@@ -0,0 +1,61 @@
+package storetest
+
+import (
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// AliasFactory returns a new, empty alias store. It is called once per
+// sub-test.
+type AliasFactory func(t *testing.T) store.AliasStore
+
+func RunAliases(t *testing.T, newAliases AliasFactory) {
+	t.Run("PutGet", func(t *testing.T) { testAliasPutGet(t, newAliases(t)) })
+	t.Run("Delete", func(t *testing.T) { testAliasDelete(t, newAliases(t)) })
+}
+
+func testAliasPutGet(t *testing.T, s store.AliasStore) {
+	if _, err := s.Get("a"); err != store.ErrNoAlias {
+		t.Fatalf("Get(missing) = %v, want ErrNoAlias", err)
+	}
+	a := store.Alias{RetiredId: "a", SurvivorId: "b", Time: 100}
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got, err := s.Get("a"); err != nil || got != a {
+		t.Fatalf("Get = %+v, %v, want %+v", got, err, a)
+	}
+	a.SurvivorId, a.Time = "c", 200
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	if got, _ := s.Get("a"); got != a {
+		t.Fatalf("Get after second Put = %+v, want %+v", got, a)
+	}
+	if _, err := s.Get("b"); err != store.ErrNoAlias {
+		t.Fatalf("Get(survivor) = %v, want ErrNoAlias", err)
+	}
+}
+
+func testAliasDelete(t *testing.T, s store.AliasStore) {
+	a := store.Alias{RetiredId: "a", SurvivorId: "b", Time: 100}
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete("a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get("a"); err != store.ErrNoAlias {
+		t.Fatalf("Get after Delete = %v, want ErrNoAlias", err)
+	}
+	if err := s.Delete("a"); err != nil {
+		t.Fatalf("Delete(missing) = %v, want nil", err)
+	}
+	if err := s.Put(a); err != nil {
+		t.Fatalf("Put after Delete: %v", err)
+	}
+	if got, _ := s.Get("a"); got != a {
+		t.Fatalf("Get after Put = %+v, want %+v", got, a)
+	}
+}
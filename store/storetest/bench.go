@@ -0,0 +1,146 @@
+package storetest
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// BenchStudents is the number of students a store is filled with before each
+// benchmark.
+const BenchStudents = 10000
+
+// Benchmark is one load pattern run against a store filled by Fill.
+type Benchmark struct {
+	Name string
+	F    func(b *testing.B, s store.StudentStore)
+}
+
+// Benchmarks are the load patterns every backend is measured with. All of
+// them run in parallel on GOMAXPROCS goroutines.
+var Benchmarks = []Benchmark{
+	{"Get", benchGet},
+	{"ListPage", benchListPage},
+	{"Update", benchUpdate},
+	// Mixed is 80% Get, 10% ListPage and 10% Update.
+	{"Mixed", benchMixed},
+}
+
+// RunBenchmarks runs Benchmarks against a fresh store each, for use from a
+// backend's test file:
+//
+//	func BenchmarkMemoryStore(b *testing.B) {
+//		storetest.RunBenchmarks(b, func() store.StudentStore {
+//			return store.NewMemoryStore()
+//		})
+//	}
+func RunBenchmarks(b *testing.B, newStore func() store.StudentStore) {
+	for _, bench := range Benchmarks {
+		bench := bench
+		b.Run(bench.Name, func(b *testing.B) {
+			s := newStore()
+			Fill(b, s)
+			b.ResetTimer()
+			bench.F(b, s)
+		})
+	}
+}
+
+// Fill creates BenchStudents students with ids benchId(0) and onwards.
+func Fill(b *testing.B, s store.StudentStore) {
+	for i := 0; i < BenchStudents; i++ {
+		stu := newStudent(benchId(i), int64(i))
+		stu.Age = int32(10 + i%90)
+		if i%2 == 0 {
+			stu.Profession = "计算机科学与技术"
+		}
+		if err := s.Create(stu); err != nil {
+			b.Fatalf("Create(%v): %v", stu.Id, err)
+		}
+	}
+}
+
+func benchId(i int) string {
+	return fmt.Sprintf("student-%05d", i)
+}
+
+var benchSeed int64
+
+// runParallel gives every goroutine its own random source.
+func runParallel(b *testing.B, op func(r *rand.Rand)) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(atomic.AddInt64(&benchSeed, 1)))
+		for pb.Next() {
+			op(r)
+		}
+	})
+}
+
+func benchGet(b *testing.B, s store.StudentStore) {
+	runParallel(b, func(r *rand.Rand) { get(b, s, r) })
+}
+
+func benchListPage(b *testing.B, s store.StudentStore) {
+	runParallel(b, func(r *rand.Rand) { listPage(b, s, r) })
+}
+
+func benchUpdate(b *testing.B, s store.StudentStore) {
+	runParallel(b, func(r *rand.Rand) { update(b, s, r) })
+}
+
+func benchMixed(b *testing.B, s store.StudentStore) {
+	runParallel(b, func(r *rand.Rand) {
+		switch n := r.Intn(10); {
+		case n < 8:
+			get(b, s, r)
+		case n < 9:
+			listPage(b, s, r)
+		default:
+			update(b, s, r)
+		}
+	})
+}
+
+func get(b *testing.B, s store.StudentStore, r *rand.Rand) {
+	if _, err := s.Get(benchId(r.Intn(BenchStudents))); err != nil {
+		b.Error(err)
+	}
+}
+
+func listPage(b *testing.B, s store.StudentStore, r *rand.Rand) {
+	q := store.ListQuery{
+		Filter: store.Filter{Profession: "软件工程", MinAge: int32(10 + r.Intn(45))},
+		Limit:  20,
+	}
+	if _, err := s.List(q); err != nil {
+		b.Error(err)
+	}
+}
+
+// update bumps the age of a random student, retrying when another goroutine
+// wrote it in between.
+func update(b *testing.B, s store.StudentStore, r *rand.Rand) {
+	id := benchId(r.Intn(BenchStudents))
+	for {
+		stu, err := s.Get(id)
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		stu.Age = 10 + (stu.Age-9)%90
+		stu.ModifiedTime++
+		switch err := s.Update(stu); err {
+		case nil:
+			return
+		case store.ErrVersionMismatch:
+			continue
+		default:
+			b.Error(err)
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// CoursesFactory returns a new store without courses. It is called once per
+// sub-test.
+type CoursesFactory func(t *testing.T) store.CourseStore
+
+func RunCourses(t *testing.T, newCourses CoursesFactory) {
+	t.Run("CRUD", func(t *testing.T) { testCoursesCRUD(t, newCourses(t)) })
+	t.Run("Isolation", func(t *testing.T) { testCoursesIsolation(t, newCourses(t)) })
+}
+
+func testCoursesCRUD(t *testing.T, c store.CourseStore) {
+	db := store.Course{Code: "DB101", Title: "Databases", Credits: 3, Professions: []string{"CST", "SE"}, Capacity: 40}
+	if err := c.Create(db); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := c.Create(db); err != store.ErrCourseAlreadyExists {
+		t.Fatalf("Create duplicate = %v, want ErrCourseAlreadyExists", err)
+	}
+	if err := c.Create(store.Course{Code: "ART1", Title: "Drawing", Credits: 1, Professions: []string{}}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := c.Get("DB101")
+	if err != nil || !reflect.DeepEqual(got, db) {
+		t.Fatalf("Get = %+v, %v, want %+v", got, err, db)
+	}
+	if _, err := c.Get("missing"); err != store.ErrCourseNotFound {
+		t.Fatalf("Get(missing) = %v, want ErrCourseNotFound", err)
+	}
+
+	db.Title = "Database Systems"
+	db.Professions = []string{"SE"}
+	db.Capacity = 0
+	if err := c.Update(db); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := c.Get("DB101"); !reflect.DeepEqual(got, db) {
+		t.Fatalf("Get after Update = %+v, want %+v", got, db)
+	}
+	if err := c.Update(store.Course{Code: "missing"}); err != store.ErrCourseNotFound {
+		t.Fatalf("Update(missing) = %v, want ErrCourseNotFound", err)
+	}
+
+	list, err := c.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var codes []string
+	for _, course := range list {
+		codes = append(codes, course.Code)
+	}
+	if !reflect.DeepEqual(codes, []string{"ART1", "DB101"}) {
+		t.Fatalf("List codes = %v, want [ART1 DB101]", codes)
+	}
+
+	if err := c.Delete("ART1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := c.Delete("ART1"); err != store.ErrCourseNotFound {
+		t.Fatalf("Delete twice = %v, want ErrCourseNotFound", err)
+	}
+	if list, _ := c.List(); len(list) != 1 {
+		t.Fatalf("List after Delete has %d courses, want 1", len(list))
+	}
+}
+
+// testCoursesIsolation checks that callers cannot change stored professions
+// through the slices they passed in or got back.
+func testCoursesIsolation(t *testing.T, c store.CourseStore) {
+	professions := []string{"SE"}
+	if err := c.Create(store.Course{Code: "OS", Title: "Operating Systems", Credits: 4, Professions: professions}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	professions[0] = "changed"
+	got, _ := c.Get("OS")
+	got.Professions[0] = "changed"
+	if got, _ := c.Get("OS"); got.Professions[0] != "SE" {
+		t.Fatalf("stored profession = %q, want SE", got.Professions[0])
+	}
+}
@@ -0,0 +1,79 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// EnrollmentsFactory returns a new store without enrollments. It is called
+// once per sub-test.
+type EnrollmentsFactory func(t *testing.T) store.EnrollmentStore
+
+func RunEnrollments(t *testing.T, newEnrollments EnrollmentsFactory) {
+	t.Run("EnrollDrop", func(t *testing.T) { testEnrollDrop(t, newEnrollments(t)) })
+	t.Run("Order", func(t *testing.T) { testEnrollmentOrder(t, newEnrollments(t)) })
+}
+
+func testEnrollDrop(t *testing.T, e store.EnrollmentStore) {
+	alice := store.Enrollment{CourseCode: "DB101", StudentId: "alice", Time: 100}
+	if err := e.Enroll(alice); err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	if err := e.Enroll(store.Enrollment{CourseCode: "DB101", StudentId: "alice", Time: 200}); err != store.ErrAlreadyEnrolled {
+		t.Fatalf("Enroll twice = %v, want ErrAlreadyEnrolled", err)
+	}
+	got, err := e.ByCourse("DB101")
+	if err != nil || !reflect.DeepEqual(got, []store.Enrollment{alice}) {
+		t.Fatalf("ByCourse = %+v, %v, want [%+v]", got, err, alice)
+	}
+	if err := e.Drop("DB101", "bob"); err != store.ErrNotEnrolled {
+		t.Fatalf("Drop(bob) = %v, want ErrNotEnrolled", err)
+	}
+	if err := e.Drop("DB101", "alice"); err != nil {
+		t.Fatalf("Drop: %v", err)
+	}
+	if err := e.Drop("DB101", "alice"); err != store.ErrNotEnrolled {
+		t.Fatalf("Drop twice = %v, want ErrNotEnrolled", err)
+	}
+	if got, err := e.ByStudent("alice"); err != nil || len(got) != 0 {
+		t.Fatalf("ByStudent after Drop = %+v, %v, want none", got, err)
+	}
+	if err := e.Enroll(alice); err != nil {
+		t.Fatalf("Enroll after Drop: %v", err)
+	}
+}
+
+func testEnrollmentOrder(t *testing.T, e store.EnrollmentStore) {
+	enrollments := []store.Enrollment{
+		{CourseCode: "OS", StudentId: "carol", Time: 300},
+		{CourseCode: "DB101", StudentId: "bob", Time: 200},
+		{CourseCode: "DB101", StudentId: "carol", Time: 100},
+		{CourseCode: "DB101", StudentId: "alice", Time: 200},
+	}
+	for _, enrollment := range enrollments {
+		if err := e.Enroll(enrollment); err != nil {
+			t.Fatalf("Enroll: %v", err)
+		}
+	}
+	byCourse, err := e.ByCourse("DB101")
+	if err != nil {
+		t.Fatalf("ByCourse: %v", err)
+	}
+	want := []store.Enrollment{enrollments[2], enrollments[3], enrollments[1]}
+	if !reflect.DeepEqual(byCourse, want) {
+		t.Fatalf("ByCourse = %+v, want %+v", byCourse, want)
+	}
+	byStudent, err := e.ByStudent("carol")
+	if err != nil {
+		t.Fatalf("ByStudent: %v", err)
+	}
+	want = []store.Enrollment{enrollments[2], enrollments[0]}
+	if !reflect.DeepEqual(byStudent, want) {
+		t.Fatalf("ByStudent = %+v, want %+v", byStudent, want)
+	}
+	if got, _ := e.ByCourse("missing"); len(got) != 0 {
+		t.Fatalf("ByCourse(missing) = %+v, want none", got)
+	}
+}
@@ -0,0 +1,132 @@
+package storetest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// HistoryFactory returns an empty history store. It is called once per sub-test.
+type HistoryFactory func(t *testing.T) store.HistoryStore
+
+func RunHistory(t *testing.T, newHistory HistoryFactory) {
+	t.Run("AppendList", func(t *testing.T) { testHistoryAppendList(t, newHistory(t)) })
+	t.Run("Snapshot", func(t *testing.T) { testHistorySnapshot(t, newHistory(t)) })
+	t.Run("Concurrent", func(t *testing.T) { testHistoryConcurrent(t, newHistory(t)) })
+}
+
+func testHistoryAppendList(t *testing.T, h store.HistoryStore) {
+	created := newStudent("a", 100)
+	altered := created
+	altered.Profession = "计算机科学与技术"
+	altered.Version = 2
+	entries := []store.HistoryEntry{
+		{StudentId: "a", Action: "register", Actor: "alice", RequestId: "r1", Time: 100, After: &created},
+		{StudentId: "b", Action: "register", Actor: "bob", RequestId: "r2", Time: 110, After: &created},
+		{StudentId: "a", Action: "alterProfession", Actor: "bob", RequestId: "r3", Time: 120, Before: &created, After: &altered},
+		{StudentId: "a", Action: "purge", Actor: "system", RequestId: "r4", Time: 130, Before: &altered},
+	}
+	for _, e := range entries {
+		if err := h.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	got, err := h.List("a")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []store.HistoryEntry{entries[0], entries[2], entries[3]}
+	if len(got) != len(want) {
+		t.Fatalf("List returned %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got, _ := h.List("missing"); len(got) != 0 {
+		t.Fatalf("List(missing) = %v, want none", got)
+	}
+}
+
+func testHistorySnapshot(t *testing.T, h store.HistoryStore) {
+	a1 := newStudent("a", 100)
+	a2 := a1
+	a2.Profession = "计算机科学与技术"
+	a2.Version = 2
+	b1 := newStudent("b", 150)
+	b2 := b1
+	b2.DeletedTime = 300
+	b2.Version = 2
+	c1 := newStudent("c", 250)
+	for _, e := range []store.HistoryEntry{
+		{StudentId: "a", Action: "register", Time: 100, After: &a1},
+		{StudentId: "b", Action: "register", Time: 150, After: &b1},
+		{StudentId: "a", Action: "alterProfession", Time: 200, Before: &a1, After: &a2},
+		{StudentId: "c", Action: "register", Time: 250, After: &c1},
+		{StudentId: "b", Action: "delete", Time: 300, Before: &b1, After: &b2},
+		{StudentId: "c", Action: "purge", Time: 400, Before: &c1},
+	} {
+		if err := h.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	for _, tc := range []struct {
+		asOf int64
+		want map[string]store.Student
+	}{
+		{50, map[string]store.Student{}},
+		{100, map[string]store.Student{"a": a1}},
+		{199, map[string]store.Student{"a": a1, "b": b1}},
+		{250, map[string]store.Student{"a": a2, "b": b1, "c": c1}},
+		{350, map[string]store.Student{"a": a2, "b": b2, "c": c1}},
+		{400, map[string]store.Student{"a": a2, "b": b2}},
+	} {
+		list, err := h.Snapshot(tc.asOf)
+		if err != nil {
+			t.Fatalf("Snapshot(%d): %v", tc.asOf, err)
+		}
+		got := map[string]store.Student{}
+		for _, s := range list {
+			got[s.Id] = s
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Snapshot(%d) = %+v, want %+v", tc.asOf, got, tc.want)
+		}
+	}
+}
+
+func testHistoryConcurrent(t *testing.T, h store.HistoryStore) {
+	const workers, perWorker = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if err := h.Append(store.HistoryEntry{StudentId: "a", Action: "update", Time: int64(i), RequestId: fmt.Sprint(w)}); err != nil {
+					t.Errorf("Append: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	got, err := h.List("a")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != workers*perWorker {
+		t.Fatalf("List returned %d entries, want %d", len(got), workers*perWorker)
+	}
+	// Each writer's entries keep their relative order.
+	last := map[string]int64{}
+	for _, e := range got {
+		if prev, ok := last[e.RequestId]; ok && e.Time < prev {
+			t.Fatalf("entries of writer %v out of order", e.RequestId)
+		}
+		last[e.RequestId] = e.Time
+	}
+}
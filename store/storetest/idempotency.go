@@ -0,0 +1,55 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// IdempotencyFactory returns a new, empty idempotency store. It is called
+// once per sub-test.
+type IdempotencyFactory func(t *testing.T) store.IdempotencyStore
+
+func RunIdempotency(t *testing.T, newIdempotency IdempotencyFactory) {
+	t.Run("PutGet", func(t *testing.T) { testIdempotencyPutGet(t, newIdempotency(t)) })
+	t.Run("Expire", func(t *testing.T) { testIdempotencyExpire(t, newIdempotency(t)) })
+}
+
+func testIdempotencyPutGet(t *testing.T, s store.IdempotencyStore) {
+	if _, err := s.Get("k"); err != store.ErrNoIdempotentResult {
+		t.Fatalf("Get(missing) = %v, want ErrNoIdempotentResult", err)
+	}
+	r := store.IdempotentResult{Key: "k", Method: "Register", Request: []byte{1, 2}, Response: []byte{3}, Time: 100}
+	if err := s.Put(r); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := s.Get("k")
+	if err != nil || !reflect.DeepEqual(got, r) {
+		t.Fatalf("Get = %+v, %v, want %+v", got, err, r)
+	}
+	r.Method, r.Response, r.Time = "Delete", []byte{4, 5}, 200
+	if err := s.Put(r); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	if got, _ := s.Get("k"); !reflect.DeepEqual(got, r) {
+		t.Fatalf("Get after second Put = %+v, want %+v", got, r)
+	}
+}
+
+func testIdempotencyExpire(t *testing.T, s store.IdempotencyStore) {
+	for i, key := range []string{"a", "b", "c"} {
+		if err := s.Put(store.IdempotentResult{Key: key, Method: "Register", Request: []byte{1}, Response: []byte{2}, Time: int64(100 * (i + 1))}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	n, err := s.Expire(300)
+	if err != nil || n != 2 {
+		t.Fatalf("Expire = %d, %v, want 2", n, err)
+	}
+	for key, want := range map[string]error{"a": store.ErrNoIdempotentResult, "b": store.ErrNoIdempotentResult, "c": nil} {
+		if _, err := s.Get(key); err != want {
+			t.Fatalf("Get(%v) = %v, want %v", key, err, want)
+		}
+	}
+}
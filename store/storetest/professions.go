@@ -0,0 +1,101 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// ProfessionsFactory returns a new profession catalogue, which may hold the
+// default professions. It is called once per sub-test.
+type ProfessionsFactory func(t *testing.T) store.ProfessionStore
+
+func RunProfessions(t *testing.T, newProfessions ProfessionsFactory) {
+	t.Run("CRUD", func(t *testing.T) { testProfessionsCRUD(t, emptied(t, newProfessions(t))) })
+	t.Run("Isolation", func(t *testing.T) { testProfessionsIsolation(t, emptied(t, newProfessions(t))) })
+}
+
+// emptied deletes the professions a new catalogue starts with.
+func emptied(t *testing.T, p store.ProfessionStore) store.ProfessionStore {
+	list, err := p.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, prof := range list {
+		if err := p.Delete(prof.Code); err != nil {
+			t.Fatalf("Delete(%v): %v", prof.Code, err)
+		}
+	}
+	return p
+}
+
+func testProfessionsCRUD(t *testing.T, p store.ProfessionStore) {
+	math := store.Profession{Code: "MATH", Names: map[string]string{"zh": "数学", "en": "Mathematics"}, Active: true, Capacity: 30}
+	if err := p.Create(math); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := p.Create(math); err != store.ErrProfessionAlreadyExists {
+		t.Fatalf("Create duplicate = %v, want ErrProfessionAlreadyExists", err)
+	}
+	if err := p.Create(store.Profession{Code: "ART", Names: map[string]string{"zh": "艺术"}}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := p.Get("MATH")
+	if err != nil || !reflect.DeepEqual(got, math) {
+		t.Fatalf("Get = %+v, %v, want %+v", got, err, math)
+	}
+	if _, err := p.Get("missing"); err != store.ErrProfessionNotFound {
+		t.Fatalf("Get(missing) = %v, want ErrProfessionNotFound", err)
+	}
+
+	math.Active = false
+	math.Capacity = 0
+	math.Names = map[string]string{"zh": "数学与应用数学"}
+	if err := p.Update(math); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := p.Get("MATH"); !reflect.DeepEqual(got, math) {
+		t.Fatalf("Get after Update = %+v, want %+v", got, math)
+	}
+	if err := p.Update(store.Profession{Code: "missing"}); err != store.ErrProfessionNotFound {
+		t.Fatalf("Update(missing) = %v, want ErrProfessionNotFound", err)
+	}
+
+	list, err := p.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var codes []string
+	for _, prof := range list {
+		codes = append(codes, prof.Code)
+	}
+	if !reflect.DeepEqual(codes, []string{"ART", "MATH"}) {
+		t.Fatalf("List codes = %v, want [ART MATH]", codes)
+	}
+
+	if err := p.Delete("ART"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := p.Delete("ART"); err != store.ErrProfessionNotFound {
+		t.Fatalf("Delete twice = %v, want ErrProfessionNotFound", err)
+	}
+	if list, _ := p.List(); len(list) != 1 {
+		t.Fatalf("List after Delete has %d professions, want 1", len(list))
+	}
+}
+
+// testProfessionsIsolation checks that callers cannot change stored names
+// through the maps they passed in or got back.
+func testProfessionsIsolation(t *testing.T, p store.ProfessionStore) {
+	names := map[string]string{"zh": "物理"}
+	if err := p.Create(store.Profession{Code: "PHY", Names: names, Active: true}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	names["zh"] = "changed"
+	got, _ := p.Get("PHY")
+	got.Names["zh"] = "changed"
+	if got, _ := p.Get("PHY"); got.Names["zh"] != "物理" {
+		t.Fatalf("stored name = %q, want 物理", got.Names["zh"])
+	}
+}
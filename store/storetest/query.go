@@ -0,0 +1,188 @@
+package storetest
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+var (
+	professions = []string{"软件工程", "计算机科学与技术"}
+	names       = []string{"al", "alan", "alice", "bob", "bobby", "carl", "dave", "eve"}
+)
+
+// randomStudent returns a student with values drawn from small domains so
+// that filters and sort keys collide often.
+func randomStudent(r *rand.Rand, id string) store.Student {
+	t := int64(r.Intn(20))
+	return store.Student{
+		Id:           id,
+		Name:         names[r.Intn(len(names))],
+		Age:          int32(10 + r.Intn(10)),
+		Profession:   professions[r.Intn(len(professions))],
+		CreateTime:   t,
+		ModifiedTime: t + int64(r.Intn(5)),
+		DeletedTime:  int64(r.Intn(4)/3) * (t + 100),
+		Number:       "N-" + id,
+	}
+}
+
+// queryMatrix is every combination of a few filters with every sort order.
+func queryMatrix() []store.ListQuery {
+	filters := []store.Filter{
+		{},
+		{Deleted: store.IncludeDeleted},
+		{Deleted: store.OnlyDeleted},
+		{Profession: "软件工程"},
+		{Profession: "计算机科学与技术", Deleted: store.IncludeDeleted},
+		{Profession: "none"},
+		{MinAge: 12, MaxAge: 15},
+		{MaxAge: 11},
+		{NamePrefix: "al"},
+		{NamePrefix: "bob", Profession: "软件工程"},
+		{CreatedFrom: 5, CreatedTo: 12},
+		{ModifiedFrom: 8},
+		{Profession: "软件工程", MinAge: 14, CreatedTo: 15},
+		{MinAge: 19, Deleted: store.IncludeDeleted},
+		{MinAge: 15, Deleted: store.OnlyDeleted},
+		{MinAge: 18, NamePrefix: "al"},
+		{MaxAge: 12, ModifiedFrom: 4, ModifiedTo: 9},
+		{Number: "N-s07"},
+		{Number: "N-s11", Deleted: store.IncludeDeleted},
+		{Number: "N-s11", MinAge: 15},
+		{Number: "N-none"},
+	}
+	var qs []store.ListQuery
+	for _, f := range filters {
+		for key := store.SortByCreateTime; key <= store.SortByAge; key++ {
+			for _, asc := range []bool{false, true} {
+				qs = append(qs, store.ListQuery{Filter: f, SortBy: key, Ascending: asc})
+			}
+		}
+	}
+	return qs
+}
+
+// checkQuery pages through q three students at a time and compares the pages
+// with store.ApplyQuery over want.
+func checkQuery(t *testing.T, s store.StudentStore, q store.ListQuery, want []store.Student) {
+	t.Helper()
+	expected := store.ApplyQuery(append([]store.Student(nil), want...), q)
+	var got []store.Student
+	q.Limit = 3
+	for page := 0; ; page++ {
+		res := mustList(t, s, q)
+		if res.Total != expected.Total {
+			t.Errorf("List(%+v) total = %d, want %d", q, res.Total, expected.Total)
+			return
+		}
+		got = append(got, res.Students...)
+		if !res.More || page > len(want) {
+			break
+		}
+		q.After = &res.Students[len(res.Students)-1]
+	}
+	if ids(got) != ids(expected.Students) {
+		t.Errorf("List(%+v) = %v, want %v", q.Filter, ids(got), ids(expected.Students))
+	}
+}
+
+func testListMatrix(t *testing.T, s store.StudentStore) {
+	r := rand.New(rand.NewSource(1))
+	var all []store.Student
+	for i := 0; i < 60; i++ {
+		stu := randomStudent(r, fmt.Sprintf("s%02d", i))
+		mustCreate(t, s, stu)
+		stu.Version = 1
+		all = append(all, stu)
+	}
+	for _, q := range queryMatrix() {
+		checkQuery(t, s, q, all)
+	}
+}
+
+// testConcurrentIndexes rewrites students from several goroutines while
+// others list them, then checks every query against the final state. Each
+// writer owns its own students, so the final state is known.
+func testConcurrentIndexes(t *testing.T, s store.StudentStore) {
+	const writers, perWriter, rounds = 4, 15, 20
+	final := make([][]store.Student, writers)
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			qs := queryMatrix()
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				q := qs[n%len(qs)]
+				res, err := s.List(q)
+				if err != nil {
+					t.Errorf("List: %v", err)
+					return
+				}
+				for _, stu := range res.Students {
+					if !q.Filter.Match(stu) {
+						t.Errorf("List(%+v) returned non-matching %+v", q.Filter, stu)
+						return
+					}
+				}
+			}
+		}()
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			current := map[string]store.Student{}
+			for round := 0; round < rounds; round++ {
+				id := fmt.Sprintf("w%d-%02d", w, r.Intn(perWriter))
+				old, exists := current[id]
+				next := randomStudent(r, id)
+				var err error
+				switch {
+				case !exists:
+					err = s.Create(next)
+					next.Version = 1
+					current[id] = next
+				case r.Intn(4) == 0:
+					err = s.Delete(id, 0)
+					delete(current, id)
+				default:
+					next.Version = old.Version
+					err = s.Update(next)
+					next.Version++
+					current[id] = next
+				}
+				if err != nil {
+					t.Errorf("write of %v: %v", id, err)
+					return
+				}
+			}
+			for _, stu := range current {
+				final[w] = append(final[w], stu)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	var all []store.Student
+	for _, list := range final {
+		all = append(all, list...)
+	}
+	for _, q := range queryMatrix() {
+		checkQuery(t, s, q, all)
+	}
+}
@@ -0,0 +1,55 @@
+package storetest
+
+import (
+	"sync"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// SequenceFactory returns a new sequence store without counters. It is
+// called once per sub-test.
+type SequenceFactory func(t *testing.T) store.SequenceStore
+
+func RunSequences(t *testing.T, newSequences SequenceFactory) {
+	t.Run("Next", func(t *testing.T) { testSequenceNext(t, newSequences(t)) })
+	t.Run("Concurrent", func(t *testing.T) { testSequenceConcurrent(t, newSequences(t)) })
+}
+
+func testSequenceNext(t *testing.T, s store.SequenceStore) {
+	for i, want := range []int64{1, 2, 3} {
+		if got, err := s.Next("a"); err != nil || got != want {
+			t.Fatalf("Next(a) #%d = %d, %v, want %d", i, got, err, want)
+		}
+	}
+	if got, err := s.Next("b"); err != nil || got != 1 {
+		t.Fatalf("Next(b) = %d, %v, want 1", got, err)
+	}
+}
+
+func testSequenceConcurrent(t *testing.T, s store.SequenceStore) {
+	const workers, perWorker = 4, 25
+	var mux sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				n, err := s.Next("c")
+				if err != nil {
+					t.Errorf("Next: %v", err)
+					return
+				}
+				mux.Lock()
+				if seen[n] {
+					t.Errorf("Next handed out %d twice", n)
+				}
+				seen[n] = true
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,426 @@
+// Package storetest is the conformance suite every store.StudentStore and
+// store.HistoryStore backend must pass. A backend wires it up from its own
+// test file:
+//
+//	func TestMemoryStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) store.StudentStore {
+//			return store.NewMemoryStore()
+//		})
+//	}
+package storetest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"mygolangproject/store"
+)
+
+// Factory returns an empty store. It is called once per sub-test.
+type Factory func(t *testing.T) store.StudentStore
+
+func Run(t *testing.T, newStore Factory) {
+	t.Run("CreateGet", func(t *testing.T) { testCreateGet(t, newStore(t)) })
+	t.Run("CreateDuplicate", func(t *testing.T) { testCreateDuplicate(t, newStore(t)) })
+	t.Run("CreateAll", func(t *testing.T) { testCreateAll(t, newStore(t)) })
+	t.Run("CreateAllConflict", func(t *testing.T) { testCreateAllConflict(t, newStore(t)) })
+	t.Run("GetMissing", func(t *testing.T) { testGetMissing(t, newStore(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newStore(t)) })
+	t.Run("UpdateMissing", func(t *testing.T) { testUpdateMissing(t, newStore(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newStore(t)) })
+	t.Run("ConditionalWrites", func(t *testing.T) { testConditionalWrites(t, newStore(t)) })
+	t.Run("ListOrder", func(t *testing.T) { testListOrder(t, newStore(t)) })
+	t.Run("ListFilter", func(t *testing.T) { testListFilter(t, newStore(t)) })
+	t.Run("ListSort", func(t *testing.T) { testListSort(t, newStore(t)) })
+	t.Run("ListDeleted", func(t *testing.T) { testListDeleted(t, newStore(t)) })
+	t.Run("ListPages", func(t *testing.T) { testListPages(t, newStore(t)) })
+	t.Run("ListTies", func(t *testing.T) { testListTies(t, newStore(t)) })
+	t.Run("ListMatrix", func(t *testing.T) { testListMatrix(t, newStore(t)) })
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, newStore(t)) })
+	t.Run("ConcurrentIndexes", func(t *testing.T) { testConcurrentIndexes(t, newStore(t)) })
+}
+
+func newStudent(id string, createTime int64) store.Student {
+	return store.Student{
+		Id:           id,
+		Name:         "tom",
+		Age:          18,
+		Profession:   "软件工程",
+		CreateTime:   createTime,
+		ModifiedTime: createTime,
+		Version:      1,
+	}
+}
+
+func mustCreate(t *testing.T, s store.StudentStore, stu store.Student) {
+	t.Helper()
+	if err := s.Create(stu); err != nil {
+		t.Fatalf("Create(%v): %v", stu.Id, err)
+	}
+}
+
+func testCreateGet(t *testing.T, s store.StudentStore) {
+	want := newStudent("a", 100)
+	mustCreate(t, s, want)
+	got, err := s.Get("a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Get = %+v, want %+v", got, want)
+	}
+}
+
+func testCreateDuplicate(t *testing.T, s store.StudentStore) {
+	mustCreate(t, s, newStudent("a", 100))
+	if err := s.Create(newStudent("a", 200)); err != store.ErrAlreadyExists {
+		t.Fatalf("duplicate Create = %v, want ErrAlreadyExists", err)
+	}
+	got, _ := s.Get("a")
+	if got.CreateTime != 100 {
+		t.Fatalf("duplicate Create overwrote the record: %+v", got)
+	}
+}
+
+func testCreateAll(t *testing.T, s store.StudentStore) {
+	batch := []store.Student{newStudent("a", 100), newStudent("b", 200), newStudent("c", 300)}
+	for i := range batch {
+		batch[i].Version = 0
+	}
+	if err := s.CreateAll(batch); err != nil {
+		t.Fatalf("CreateAll: %v", err)
+	}
+	for _, want := range batch {
+		want.Version = 1
+		got, err := s.Get(want.Id)
+		if err != nil {
+			t.Fatalf("Get(%v): %v", want.Id, err)
+		}
+		if got != want {
+			t.Fatalf("Get(%v) = %+v, want %+v", want.Id, got, want)
+		}
+	}
+	res := mustList(t, s, store.ListQuery{})
+	if got := ids(res.Students); got != "[c b a]" {
+		t.Fatalf("List after CreateAll = %v, want [c b a]", got)
+	}
+}
+
+// testCreateAllConflict checks that a batch with a taken or repeated id
+// stores none of its students.
+func testCreateAllConflict(t *testing.T, s store.StudentStore) {
+	mustCreate(t, s, newStudent("a", 100))
+	for _, batch := range [][]store.Student{
+		{newStudent("b", 200), newStudent("a", 300)},
+		{newStudent("b", 200), newStudent("c", 300), newStudent("b", 400)},
+	} {
+		if err := s.CreateAll(batch); err != store.ErrAlreadyExists {
+			t.Fatalf("CreateAll(%v) = %v, want ErrAlreadyExists", ids(batch), err)
+		}
+		res := mustList(t, s, store.ListQuery{})
+		if got := ids(res.Students); got != "[a]" {
+			t.Fatalf("after failed CreateAll(%v) students = %v, want [a]", ids(batch), got)
+		}
+		if got, _ := s.Get("a"); got.CreateTime != 100 {
+			t.Fatalf("failed CreateAll overwrote the record: %+v", got)
+		}
+	}
+}
+
+func testGetMissing(t *testing.T, s store.StudentStore) {
+	if _, err := s.Get("missing"); err != store.ErrNotFound {
+		t.Fatalf("Get(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdate(t *testing.T, s store.StudentStore) {
+	mustCreate(t, s, newStudent("a", 100))
+	want := newStudent("a", 100)
+	want.Profession = "计算机科学与技术"
+	want.ModifiedTime = 150
+	if err := s.Update(want); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want.Version = 2
+	got, err := s.Get("a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Get after Update = %+v, want %+v", got, want)
+	}
+}
+
+func testUpdateMissing(t *testing.T, s store.StudentStore) {
+	if err := s.Update(newStudent("missing", 100)); err != store.ErrNotFound {
+		t.Fatalf("Update(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get("missing"); err != store.ErrNotFound {
+		t.Fatalf("Update(missing) created a record")
+	}
+}
+
+func testDelete(t *testing.T, s store.StudentStore) {
+	mustCreate(t, s, newStudent("a", 100))
+	if err := s.Delete("a", 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get("a"); err != store.ErrNotFound {
+		t.Fatalf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("a", 0); err != store.ErrNotFound {
+		t.Fatalf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func testConditionalWrites(t *testing.T, s store.StudentStore) {
+	mustCreate(t, s, newStudent("a", 100))
+	first, _ := s.Get("a")
+	second := first
+
+	first.Name = "first"
+	if err := s.Update(first); err != nil {
+		t.Fatalf("Update at version %d: %v", first.Version, err)
+	}
+	second.Name = "second"
+	if err := s.Update(second); err != store.ErrVersionMismatch {
+		t.Fatalf("stale Update = %v, want ErrVersionMismatch", err)
+	}
+	got, _ := s.Get("a")
+	if got.Name != "first" || got.Version != 2 {
+		t.Fatalf("after stale Update got %+v, want name first at version 2", got)
+	}
+
+	if err := s.Delete("a", 1); err != store.ErrVersionMismatch {
+		t.Fatalf("stale Delete = %v, want ErrVersionMismatch", err)
+	}
+	if err := s.Delete("a", 2); err != nil {
+		t.Fatalf("Delete at current version: %v", err)
+	}
+	if err := s.Update(got); err != store.ErrNotFound {
+		t.Fatalf("Update after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func testListOrder(t *testing.T, s store.StudentStore) {
+	res, err := s.List(store.ListQuery{})
+	if err != nil {
+		t.Fatalf("List on empty store: %v", err)
+	}
+	if len(res.Students) != 0 || res.Total != 0 {
+		t.Fatalf("List on empty store returned %+v", res)
+	}
+	mustCreate(t, s, newStudent("b", 200))
+	mustCreate(t, s, newStudent("a", 100))
+	mustCreate(t, s, newStudent("c", 300))
+	res, err = s.List(store.ListQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := ids(res.Students); got != "[c b a]" {
+		t.Fatalf("List order = %v, want [c b a]", got)
+	}
+}
+
+func ids(list []store.Student) string {
+	var ids []string
+	for _, stu := range list {
+		ids = append(ids, stu.Id)
+	}
+	return fmt.Sprint(ids)
+}
+
+func mustList(t *testing.T, s store.StudentStore, q store.ListQuery) store.ListResult {
+	t.Helper()
+	res, err := s.List(q)
+	if err != nil {
+		t.Fatalf("List(%+v): %v", q, err)
+	}
+	return res
+}
+
+// createFixture stores five students with distinct values in every column.
+func createFixture(t *testing.T, s store.StudentStore) {
+	for _, stu := range []store.Student{
+		{Id: "a", Name: "alice", Age: 20, Profession: "软件工程", CreateTime: 100, ModifiedTime: 500},
+		{Id: "b", Name: "bob", Age: 35, Profession: "计算机科学与技术", CreateTime: 200, ModifiedTime: 400},
+		{Id: "c", Name: "alan", Age: 18, Profession: "软件工程", CreateTime: 300, ModifiedTime: 300},
+		{Id: "d", Name: "dave", Age: 60, Profession: "计算机科学与技术", CreateTime: 400, ModifiedTime: 200},
+		{Id: "e", Name: "eve", Age: 20, Profession: "软件工程", CreateTime: 500, ModifiedTime: 100},
+	} {
+		mustCreate(t, s, stu)
+	}
+}
+
+func testListFilter(t *testing.T, s store.StudentStore) {
+	createFixture(t, s)
+	for _, tc := range []struct {
+		filter store.Filter
+		want   string
+	}{
+		{store.Filter{Profession: "软件工程"}, "[e c a]"},
+		{store.Filter{MinAge: 20, MaxAge: 35}, "[e b a]"},
+		{store.Filter{NamePrefix: "al"}, "[c a]"},
+		{store.Filter{CreatedFrom: 200, CreatedTo: 400}, "[d c b]"},
+		{store.Filter{ModifiedFrom: 400}, "[b a]"},
+		{store.Filter{ModifiedTo: 200}, "[e d]"},
+		{store.Filter{Profession: "软件工程", MinAge: 20, NamePrefix: "e"}, "[e]"},
+		{store.Filter{Profession: "none"}, "[]"},
+	} {
+		res := mustList(t, s, store.ListQuery{Filter: tc.filter})
+		if got := ids(res.Students); got != tc.want {
+			t.Errorf("List(%+v) = %v, want %v", tc.filter, got, tc.want)
+		}
+		if res.Total != len(res.Students) {
+			t.Errorf("List(%+v) total = %d, want %d", tc.filter, res.Total, len(res.Students))
+		}
+	}
+}
+
+func testListSort(t *testing.T, s store.StudentStore) {
+	createFixture(t, s)
+	for _, tc := range []struct {
+		key       store.SortKey
+		ascending bool
+		want      string
+	}{
+		{store.SortByCreateTime, false, "[e d c b a]"},
+		{store.SortByCreateTime, true, "[a b c d e]"},
+		{store.SortByModifiedTime, false, "[a b c d e]"},
+		{store.SortByName, true, "[c a b d e]"},
+		// Equal ages fall back to the id in the same direction.
+		{store.SortByAge, true, "[c a e b d]"},
+		{store.SortByAge, false, "[d b e a c]"},
+	} {
+		res := mustList(t, s, store.ListQuery{SortBy: tc.key, Ascending: tc.ascending})
+		if got := ids(res.Students); got != tc.want {
+			t.Errorf("List(sort %v, ascending %v) = %v, want %v", tc.key, tc.ascending, got, tc.want)
+		}
+	}
+}
+
+func testListDeleted(t *testing.T, s store.StudentStore) {
+	createFixture(t, s)
+	for id, deletedTime := range map[string]int64{"b": 1000, "d": 2000} {
+		stu, _ := s.Get(id)
+		stu.DeletedTime = deletedTime
+		if err := s.Update(stu); err != nil {
+			t.Fatalf("soft delete of %v: %v", id, err)
+		}
+	}
+	if got, _ := s.Get("b"); got.DeletedTime != 1000 {
+		t.Fatalf("Get returned deletedTime %d, want 1000", got.DeletedTime)
+	}
+	for _, tc := range []struct {
+		filter store.Filter
+		want   string
+	}{
+		{store.Filter{}, "[e c a]"},
+		{store.Filter{Deleted: store.IncludeDeleted}, "[e d c b a]"},
+		{store.Filter{Deleted: store.OnlyDeleted}, "[d b]"},
+		{store.Filter{Deleted: store.OnlyDeleted, DeletedTo: 1500}, "[b]"},
+	} {
+		res := mustList(t, s, store.ListQuery{Filter: tc.filter})
+		if got := ids(res.Students); got != tc.want || res.Total != len(res.Students) {
+			t.Errorf("List(%+v) = %v (total %d), want %v", tc.filter, got, res.Total, tc.want)
+		}
+	}
+}
+
+func testListPages(t *testing.T, s store.StudentStore) {
+	createFixture(t, s)
+	q := store.ListQuery{SortBy: store.SortByAge, Ascending: true, Limit: 2}
+	first := mustList(t, s, q)
+	if got := ids(first.Students); got != "[c a]" || !first.More || first.Total != 5 {
+		t.Fatalf("first page = %v (more %v, total %d)", got, first.More, first.Total)
+	}
+
+	// Students inserted ahead of the cursor must not shift later pages.
+	mustCreate(t, s, store.Student{Id: "f", Name: "fay", Age: 11, Profession: "软件工程", CreateTime: 600, ModifiedTime: 600})
+	q.After = &first.Students[1]
+	second := mustList(t, s, q)
+	if got := ids(second.Students); got != "[e b]" || !second.More || second.Total != 6 {
+		t.Fatalf("second page = %v (more %v, total %d)", got, second.More, second.Total)
+	}
+	q.After = &second.Students[1]
+	last := mustList(t, s, q)
+	if got := ids(last.Students); got != "[d]" || last.More {
+		t.Fatalf("last page = %v (more %v)", got, last.More)
+	}
+}
+
+// testListTies pages through students created at the same instant, which
+// must come back ordered by id, and checks that writes move a student within
+// the modifiedTime order.
+func testListTies(t *testing.T, s store.StudentStore) {
+	for _, id := range []string{"d", "a", "f", "c", "e", "b"} {
+		mustCreate(t, s, newStudent(id, 100))
+	}
+	for _, tc := range []struct {
+		ascending bool
+		want      []string
+	}{
+		{false, []string{"[f e]", "[d c]", "[b a]"}},
+		{true, []string{"[a b]", "[c d]", "[e f]"}},
+	} {
+		q := store.ListQuery{Ascending: tc.ascending, Limit: 2}
+		for i, want := range tc.want {
+			res := mustList(t, s, q)
+			if got := ids(res.Students); got != want || res.More != (i < len(tc.want)-1) {
+				t.Fatalf("page %d (ascending %v) = %v (more %v), want %v", i, tc.ascending, got, res.More, want)
+			}
+			q.After = &res.Students[1]
+		}
+	}
+
+	stu, _ := s.Get("c")
+	stu.ModifiedTime = 900
+	if err := s.Update(stu); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Delete("f", 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	res := mustList(t, s, store.ListQuery{SortBy: store.SortByModifiedTime, Limit: 3})
+	if got := ids(res.Students); got != "[c e d]" || res.Total != 5 {
+		t.Fatalf("List by modifiedTime = %v (total %d), want [c e d] (total 5)", got, res.Total)
+	}
+}
+
+func testConcurrent(t *testing.T, s store.StudentStore) {
+	const workers, perWorker = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id := fmt.Sprintf("%d-%d", w, i)
+				if err := s.Create(newStudent(id, int64(i))); err != nil {
+					t.Errorf("Create(%v): %v", id, err)
+					return
+				}
+				if _, err := s.Get(id); err != nil {
+					t.Errorf("Get(%v): %v", id, err)
+				}
+				if _, err := s.List(store.ListQuery{}); err != nil {
+					t.Errorf("List: %v", err)
+				}
+				if i%2 == 0 {
+					if err := s.Delete(id, 0); err != nil {
+						t.Errorf("Delete(%v): %v", id, err)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	res, err := s.List(store.ListQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := workers * perWorker / 2; len(res.Students) != want || res.Total != want {
+		t.Fatalf("List returned %d of %d students, want %d", len(res.Students), res.Total, want)
+	}
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// 日志与快照文件格式:
+//
+//	record   = length(uint32) | crc32c(payload)(uint32) | payload
+//	payload  = json
+//
+// A record that is short or whose checksum does not match marks the end of
+// the valid log; everything from its offset on is a torn write and is dropped.
+
+const (
+	walFileName      = "students.wal"
+	snapshotFileName = "students.snap"
+	recordHeaderSize = 8
+	maxRecordSize    = 64 << 20
+)
+
+var (
+	crcTable         = crc32.MakeTable(crc32.Castagnoli)
+	errCorruptRecord = errors.New("corrupt record")
+)
+
+const (
+	opPut    = "put"
+	opPutAll = "putAll"
+	opDelete = "delete"
+)
+
+type walRecord struct {
+	Seq     uint64  `json:"seq"`
+	Op      string  `json:"op"`
+	Student Student `json:"student"`
+	// Students are the students of a putAll, written together.
+	Students []Student `json:"students,omitempty"`
+}
+
+type snapshot struct {
+	Seq      uint64    `json:"seq"`
+	Students []Student `json:"students"`
+}
+
+func writeRecord(w io.Writer, v interface{}) (int, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[recordHeaderSize:], payload)
+	return w.Write(buf)
+}
+
+// readRecord decodes the next record into v. It returns io.EOF at a clean end
+// of input and errCorruptRecord for a torn or damaged record.
+func readRecord(r io.Reader, v interface{}) (int, error) {
+	var header [recordHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	if err == io.EOF {
+		return 0, io.EOF
+	}
+	if err != nil {
+		return n, errCorruptRecord
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return n, errCorruptRecord
+	}
+	payload := make([]byte, length)
+	m, err := io.ReadFull(r, payload)
+	n += m
+	if err != nil {
+		return n, errCorruptRecord
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return n, errCorruptRecord
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return n, errCorruptRecord
+	}
+	return n, nil
+}
+
+// replayWAL calls apply for every intact record in the log and returns the
+// offset just past the last one.
+func replayWAL(f *os.File, apply func(walRecord)) (int64, error) {
+	return replayRecords(f, func() interface{} { return &walRecord{} }, func(v interface{}) {
+		apply(*v.(*walRecord))
+	})
+}
+
+// replayRecords decodes records from the start of f into values made by
+// alloc until the first torn or damaged one, and returns its offset.
+func replayRecords(f *os.File, alloc func() interface{}, apply func(interface{})) (int64, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		v := alloc()
+		n, err := readRecord(r, v)
+		if err == io.EOF || err == errCorruptRecord {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+		offset += int64(n)
+		apply(v)
+	}
+}
+
+func readSnapshot(dir string) (snapshot, error) {
+	var snap snapshot
+	_, err := readRecordFile(dir, snapshotFileName, &snap)
+	return snap, err
+}
+
+// readRecordFile reads a file holding the single record v and reports
+// whether it exists.
+func readRecordFile(dir, name string, v interface{}) (bool, error) {
+	f, err := os.Open(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := readRecord(bufio.NewReader(f), v); err != nil {
+		return true, fmt.Errorf("%v checksum mismatch", name)
+	}
+	return true, nil
+}
+
+func writeSnapshot(dir string, snap snapshot) error {
+	return writeRecordFile(dir, snapshotFileName, snap)
+}
+
+// writeRecordFile atomically replaces a file with the single record v: the
+// data is written to a temporary file, synced and then renamed over the old
+// file.
+func writeRecordFile(dir, name string, v interface{}) error {
+	tmp, err := ioutil.TempFile(dir, name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	if _, err := writeRecord(w, v); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCompacted is returned by Watch when the requested revision is no
+	// longer held in the event buffer; the caller must re-read the full list.
+	ErrCompacted = errors.New("revision has been compacted")
+	// ErrFutureRevision is returned by Watch for a revision not yet reached.
+	ErrFutureRevision = errors.New("revision is in the future")
+	// ErrSlowConsumer ends a watch whose reader fell too far behind.
+	ErrSlowConsumer = errors.New("watcher fell too far behind")
+	ErrWatchClosed  = errors.New("watcher closed")
+)
+
+type EventType int
+
+const (
+	Created EventType = iota + 1
+	Updated
+	// Deleted marks a soft delete; the student carries its DeletedTime.
+	Deleted
+	// Restored undoes a soft delete.
+	Restored
+	// Purged is a student removed from the store for good.
+	Purged
+)
+
+type Event struct {
+	Type     EventType
+	Student  Student
+	Revision int64
+	// Time is when the event was published, in unix nanoseconds.
+	Time int64
+}
+
+// WatchableStore wraps a StudentStore and publishes an Event, stamped with a
+// monotonically increasing revision, for every successful write.
+//
+// Writes to one student are serialized so that its revisions follow the order
+// in which its changes reached the underlying store; writes to students on
+// different lock stripes proceed in parallel. Reads go straight through.
+type WatchableStore struct {
+	StudentStore
+
+	// writeMux holds the stripe of a student across the store write and its
+	// publish.
+	writeMux [DefaultShards]sync.Mutex
+
+	mux       sync.Mutex
+	revision  int64
+	buffer    []Event // the most recent events, oldest first
+	maxBuffer int
+	maxQueue  int
+	watchers  map[*Watcher]struct{}
+}
+
+// NewWatchableStore keeps the last bufferSize events for resuming watches.
+func NewWatchableStore(s StudentStore, bufferSize int) *WatchableStore {
+	return &WatchableStore{
+		StudentStore: s,
+		maxBuffer:    bufferSize,
+		maxQueue:     2 * bufferSize,
+		watchers:     make(map[*Watcher]struct{}),
+	}
+}
+
+func (w *WatchableStore) Create(s Student) error {
+	mux := &w.writeMux[stripe(s.Id, len(w.writeMux))]
+	mux.Lock()
+	defer mux.Unlock()
+	if err := w.StudentStore.Create(s); err != nil {
+		return err
+	}
+	s.Version = 1
+	w.publish(Created, s)
+	return nil
+}
+
+// CreateAll publishes the events of the students only once all of them are
+// stored, with consecutive revisions.
+func (w *WatchableStore) CreateAll(students []Student) error {
+	var locked [DefaultShards]bool
+	for _, s := range students {
+		locked[stripe(s.Id, len(w.writeMux))] = true
+	}
+	for i := range w.writeMux {
+		if locked[i] {
+			w.writeMux[i].Lock()
+			defer w.writeMux[i].Unlock()
+		}
+	}
+	if err := w.StudentStore.CreateAll(students); err != nil {
+		return err
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	for _, s := range students {
+		s.Version = 1
+		w.publishLocked(Created, s)
+	}
+	return nil
+}
+
+func (w *WatchableStore) Update(s Student) error {
+	mux := &w.writeMux[stripe(s.Id, len(w.writeMux))]
+	mux.Lock()
+	defer mux.Unlock()
+	old, err := w.StudentStore.Get(s.Id)
+	if err != nil {
+		return err
+	}
+	if err := w.StudentStore.Update(s); err != nil {
+		return err
+	}
+	s.Version++
+	switch {
+	case old.DeletedTime == 0 && s.DeletedTime != 0:
+		w.publish(Deleted, s)
+	case old.DeletedTime != 0 && s.DeletedTime == 0:
+		w.publish(Restored, s)
+	default:
+		w.publish(Updated, s)
+	}
+	return nil
+}
+
+func (w *WatchableStore) Delete(id string, version int64) error {
+	mux := &w.writeMux[stripe(id, len(w.writeMux))]
+	mux.Lock()
+	defer mux.Unlock()
+	s, err := w.StudentStore.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := w.StudentStore.Delete(id, version); err != nil {
+		return err
+	}
+	w.publish(Purged, s)
+	return nil
+}
+
+// Revision returns the revision of the latest published event.
+func (w *WatchableStore) Revision() int64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.revision
+}
+
+func (w *WatchableStore) publish(t EventType, s Student) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.publishLocked(t, s)
+}
+
+func (w *WatchableStore) publishLocked(t EventType, s Student) {
+	w.revision++
+	ev := Event{Type: t, Student: s, Revision: w.revision, Time: time.Now().UnixNano()}
+	if w.maxBuffer > 0 {
+		if len(w.buffer) == w.maxBuffer {
+			copy(w.buffer, w.buffer[1:])
+			w.buffer = w.buffer[:len(w.buffer)-1]
+		}
+		w.buffer = append(w.buffer, ev)
+	}
+	for watcher := range w.watchers {
+		if !watcher.push(ev, w.maxQueue) {
+			delete(w.watchers, watcher)
+		}
+	}
+}
+
+// Watch returns a Watcher that yields every event after fromRevision.
+// A fromRevision of zero starts at the next write.
+func (w *WatchableStore) Watch(fromRevision int64) (*Watcher, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if fromRevision > w.revision {
+		return nil, ErrFutureRevision
+	}
+	watcher := &Watcher{parent: w, notify: make(chan struct{}, 1), start: w.revision}
+	if fromRevision > 0 {
+		watcher.start = fromRevision
+	}
+	if fromRevision > 0 && fromRevision < w.revision {
+		if len(w.buffer) == 0 || w.buffer[0].Revision > fromRevision+1 {
+			return nil, ErrCompacted
+		}
+		for _, ev := range w.buffer {
+			if ev.Revision > fromRevision {
+				watcher.queue = append(watcher.queue, ev)
+			}
+		}
+		watcher.signal()
+	}
+	w.watchers[watcher] = struct{}{}
+	return watcher, nil
+}
+
+func (w *WatchableStore) unwatch(watcher *Watcher) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	delete(w.watchers, watcher)
+}
+
+// Watcher is a single subscription. Events are queued per watcher so a slow
+// reader never blocks writers; once its queue overflows the watcher is ended
+// with ErrSlowConsumer and the reader is expected to resume from the last
+// revision it saw.
+type Watcher struct {
+	parent *WatchableStore
+	notify chan struct{}
+	start  int64
+
+	mux   sync.Mutex
+	queue []Event
+	err   error
+}
+
+// push queues ev and reports whether the watcher is still alive.
+func (w *Watcher) push(ev Event, maxQueue int) bool {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.err != nil {
+		return false
+	}
+	if maxQueue > 0 && len(w.queue) >= maxQueue {
+		w.err = ErrSlowConsumer
+		w.queue = nil
+	} else {
+		w.queue = append(w.queue, ev)
+	}
+	w.signal()
+	return w.err == nil
+}
+
+// Start returns the revision the watcher yields events after.
+func (w *Watcher) Start() int64 {
+	return w.start
+}
+
+func (w *Watcher) signal() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next blocks until an event is available, the watcher ends or ctx is done.
+func (w *Watcher) Next(ctx context.Context) (Event, error) {
+	for {
+		w.mux.Lock()
+		if len(w.queue) > 0 {
+			ev := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mux.Unlock()
+			return ev, nil
+		}
+		err := w.err
+		w.mux.Unlock()
+		if err != nil {
+			return Event{}, err
+		}
+		select {
+		case <-w.notify:
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		}
+	}
+}
+
+func (w *Watcher) Close() {
+	w.parent.unwatch(w)
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.err == nil {
+		w.err = ErrWatchClosed
+	}
+	w.queue = nil
+	w.signal()
+}
@@ -0,0 +1,184 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mygolangproject/store"
+)
+
+// nextEvents reads n events of w, failing the test if they do not come.
+func nextEvents(t *testing.T, w *store.Watcher, n int) []store.Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var events []store.Event
+	for i := 0; i < n; i++ {
+		ev, err := w.Next(ctx)
+		if err != nil {
+			t.Fatalf("event %d of %d: %v", i+1, n, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+// describe lists the revision, type and student of each event.
+func describe(events []store.Event) string {
+	var out []string
+	for _, ev := range events {
+		out = append(out, fmt.Sprintf("%d:%d:%v", ev.Revision, ev.Type, ev.Student.Id))
+	}
+	return fmt.Sprint(out)
+}
+
+func mustWatch(t *testing.T, w *store.WatchableStore, fromRevision int64) *store.Watcher {
+	t.Helper()
+	watcher, err := w.Watch(fromRevision)
+	if err != nil {
+		t.Fatalf("Watch(%d): %v", fromRevision, err)
+	}
+	t.Cleanup(watcher.Close)
+	return watcher
+}
+
+func TestWatchEvents(t *testing.T) {
+	w := store.NewWatchableStore(store.NewMemoryStore(), 16)
+	watcher := mustWatch(t, w, 0)
+	mustCreate(t, w, "a")
+	stu, err := w.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stu.Age = 21
+	for _, deleted := range []int64{0, 1, 0} {
+		stu.DeletedTime = deleted
+		if err := w.Update(stu); err != nil {
+			t.Fatal(err)
+		}
+		stu.Version++
+	}
+	if err := w.Delete("a", stu.Version); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CreateAll([]store.Student{{Id: "b"}, {Id: "c"}}); err != nil {
+		t.Fatal(err)
+	}
+	got := describe(nextEvents(t, watcher, 7))
+	want := fmt.Sprint([]string{
+		fmt.Sprintf("1:%d:a", store.Created),
+		fmt.Sprintf("2:%d:a", store.Updated),
+		fmt.Sprintf("3:%d:a", store.Deleted),
+		fmt.Sprintf("4:%d:a", store.Restored),
+		fmt.Sprintf("5:%d:a", store.Purged),
+		fmt.Sprintf("6:%d:b", store.Created),
+		fmt.Sprintf("7:%d:c", store.Created),
+	})
+	if got != want {
+		t.Errorf("events %v, want %v", got, want)
+	}
+	if rev := w.Revision(); rev != 7 {
+		t.Errorf("Revision() = %d, want 7", rev)
+	}
+	// A failed write publishes nothing.
+	if err := w.Create(store.Student{Id: "b"}); err != store.ErrAlreadyExists {
+		t.Fatalf("Create of a taken id: %v", err)
+	}
+	if rev := w.Revision(); rev != 7 {
+		t.Errorf("Revision() = %d after a failed write, want 7", rev)
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	w := store.NewWatchableStore(store.NewMemoryStore(), 16)
+	mustCreate(t, w, "a", "b", "c")
+	tests := []struct {
+		from  int64
+		start int64
+		want  string
+	}{
+		{0, 3, "[4:1:d]"},
+		{1, 1, "[2:1:b 3:1:c 4:1:d]"},
+		{2, 2, "[3:1:c 4:1:d]"},
+		{3, 3, "[4:1:d]"},
+	}
+	var watchers []*store.Watcher
+	for _, tt := range tests {
+		watcher := mustWatch(t, w, tt.from)
+		if watcher.Start() != tt.start {
+			t.Errorf("Watch(%d) starts after %d, want %d", tt.from, watcher.Start(), tt.start)
+		}
+		watchers = append(watchers, watcher)
+	}
+	mustCreate(t, w, "d")
+	for i, tt := range tests {
+		n := int(4 - tt.start)
+		if got := describe(nextEvents(t, watchers[i], n)); got != tt.want {
+			t.Errorf("Watch(%d) got %v, want %v", tt.from, got, tt.want)
+		}
+	}
+	if _, err := w.Watch(5); err != store.ErrFutureRevision {
+		t.Errorf("Watch(5) at revision 4: %v, want %v", err, store.ErrFutureRevision)
+	}
+}
+
+func TestWatchCompacted(t *testing.T) {
+	w := store.NewWatchableStore(store.NewMemoryStore(), 2)
+	mustCreate(t, w, "a", "b", "c", "d")
+	// The buffer holds revisions 3 and 4.
+	if _, err := w.Watch(1); err != store.ErrCompacted {
+		t.Errorf("Watch(1): %v, want %v", err, store.ErrCompacted)
+	}
+	if got := describe(nextEvents(t, mustWatch(t, w, 2), 2)); got != "[3:1:c 4:1:d]" {
+		t.Errorf("Watch(2) got %v", got)
+	}
+}
+
+// TestWatchSlowConsumer checks that a watcher that stops reading neither
+// blocks writers nor other watchers, and is ended once its queue overflows.
+func TestWatchSlowConsumer(t *testing.T) {
+	const bufferSize = 2 // queues hold 2*bufferSize events
+	w := store.NewWatchableStore(store.NewMemoryStore(), bufferSize)
+	slow := mustWatch(t, w, 0)
+	fast := mustWatch(t, w, 0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+			if err := w.Create(store.Student{Id: id}); err != nil {
+				t.Error(err)
+			}
+			if _, err := fast.Next(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writers blocked by a watcher that does not read")
+	}
+	if _, err := slow.Next(context.Background()); err != store.ErrSlowConsumer {
+		t.Errorf("slow watcher got %v, want %v", err, store.ErrSlowConsumer)
+	}
+	// A reader resuming from a revision still buffered gets the rest.
+	if got := describe(nextEvents(t, mustWatch(t, w, 4), 2)); got != "[5:1:e 6:1:f]" {
+		t.Errorf("resumed watcher got %v", got)
+	}
+}
+
+func TestWatchClose(t *testing.T) {
+	w := store.NewWatchableStore(store.NewMemoryStore(), 16)
+	watcher, err := w.Watch(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.Close()
+	mustCreate(t, w, "a")
+	if _, err := watcher.Next(context.Background()); err != store.ErrWatchClosed {
+		t.Errorf("closed watcher got %v, want %v", err, store.ErrWatchClosed)
+	}
+}